@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"html/template"
 	"log"
 	"net/http"
@@ -12,10 +13,14 @@ import (
 
 	"groupie-tracker/internal/auth"
 	"groupie-tracker/internal/database"
-	"groupie-tracker/internal/games/blindtest"
-	"groupie-tracker/internal/games/petitbac"
+	"groupie-tracker/internal/games"
+	_ "groupie-tracker/internal/games/all"
+	"groupie-tracker/internal/games/engines"
+	"groupie-tracker/internal/media"
 	"groupie-tracker/internal/rooms"
 	"groupie-tracker/internal/spotify"
+	"groupie-tracker/internal/stats"
+	"groupie-tracker/internal/tracks"
 	"groupie-tracker/internal/websocket"
 )
 
@@ -26,6 +31,15 @@ type Config struct {
 	StaticDir       string
 	SpotifyClientID string
 	SpotifySecret   string
+	SpotifyAPIURL   string
+	SpotifyAuthURL  string
+	DisconnectGrace time.Duration
+	TrackProvider   string
+	TracksDir       string
+	TracksCatalog   string
+	DeezerCacheTTL  time.Duration
+	DeezerCacheDB   bool
+	TracksFallback  string
 }
 
 func main() {
@@ -38,49 +52,138 @@ func main() {
 		StaticDir:       getEnv("STATIC_DIR", "./web/static"),
 		SpotifyClientID: getEnv("SPOTIFY_CLIENT_ID", ""),
 		SpotifySecret:   getEnv("SPOTIFY_CLIENT_SECRET", ""),
+		SpotifyAPIURL:   getEnv("SPOTIFY_API_URL", spotify.DefaultSpotifyAPIURL),
+		SpotifyAuthURL:  getEnv("SPOTIFY_ACCOUNTS_URL", spotify.DefaultSpotifyAccountsURL),
+		DisconnectGrace: getDurationEnv("DISCONNECT_GRACE", websocket.DefaultGracePeriod),
+		TrackProvider:   getEnv("TRACK_PROVIDER", tracks.DefaultProvider),
+		TracksDir:       getEnv("TRACKS_DIR", ""),
+		TracksCatalog:   getEnv("TRACKS_CATALOG", ""),
+		DeezerCacheTTL:  getDurationEnv("DEEZER_CACHE_TTL", spotify.DefaultCacheTTL),
+		DeezerCacheDB:   getEnv("DEEZER_CACHE_SQLITE", "false") == "true",
+		TracksFallback:  getEnv("TRACKS_FALLBACK", ""),
 	}
 
 	if err := os.MkdirAll("./data", 0755); err != nil {
 		log.Printf("[WARN] Impossible de créer le dossier data: %v", err)
 	}
 
-	if err := database.Init(config.DatabasePath); err != nil {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrateCommand(os.Args[2:], config.DatabasePath)
+		return
+	}
+
+	db, err := database.Init(config.DatabasePath)
+	if err != nil {
 		log.Fatalf("[FATAL] Erreur initialisation DB: %v", err)
 	}
-	defer database.Close()
+	defer db.Close()
 	log.Println("[OK] Base de données initialisée")
 
-	spotifyClient := spotify.NewClient(spotify.Config{})
+	apiStore := spotify.NewSQLiteCache(db)
+	spotifyConfig := spotify.Config{
+		CacheTTL:  config.DeezerCacheTTL,
+		PoolStore: apiStore,
+	}
+	if config.DeezerCacheDB {
+		spotifyConfig.CacheStore = apiStore
+	}
+	spotifyClient := spotify.NewClient(spotifyConfig)
 	if err := spotifyClient.Authenticate(); err != nil {
 		log.Printf("[WARN] Erreur init Deezer: %v", err)
 	} else {
 		log.Println("[OK] Client Deezer initialisé")
 	}
 
-	roomManager := rooms.GetManager()
-	blindtestMgr := blindtest.GetGameManager()
-	petitbacMgr := petitbac.GetGameManager()
-	log.Println("[OK] Managers de jeu initialisés")
+	trackRegistry := tracks.GetRegistry()
+	deezerTracks := tracks.NewDeezerProvider(spotifyClient)
+	trackRegistry.Register(deezerTracks)
+
+	if config.SpotifyClientID != "" || config.SpotifySecret != "" {
+		webAPI, err := spotify.NewWebAPIClient(spotify.WebAPIConfig{
+			ClientID:     config.SpotifyClientID,
+			ClientSecret: config.SpotifySecret,
+			APIURL:       config.SpotifyAPIURL,
+			AccountsURL:  config.SpotifyAuthURL,
+			TokenStore:   apiStore,
+		})
+		if err == nil {
+			err = webAPI.Authenticate()
+		}
+		switch {
+		case errors.Is(err, spotify.ErrMissingCredentials), errors.Is(err, spotify.ErrInvalidCredentials):
+			log.Printf("[WARN] Source Spotify désactivée: %v", err)
+		case err != nil:
+			// Spotify injoignable au démarrage : la source reste proposée,
+			// le token sera redemandé à la première partie.
+			log.Printf("[WARN] Erreur init Spotify: %v", err)
+			trackRegistry.Register(tracks.NewSpotifyProvider(webAPI))
+		default:
+			log.Println("[OK] Client Spotify authentifié")
+			trackRegistry.Register(tracks.NewSpotifyProvider(webAPI))
+		}
+	}
+
+	var localTracks *tracks.LocalProvider
+	if config.TracksDir != "" {
+		provider, err := tracks.NewLocalProvider(config.TracksDir)
+		if err != nil {
+			log.Printf("[WARN] Dossier de musique %s inutilisable: %v", config.TracksDir, err)
+		} else {
+			localTracks = provider
+			trackRegistry.Register(provider)
+		}
+	}
+	if config.TracksCatalog != "" {
+		catalog, err := tracks.NewCatalogProvider(config.TracksCatalog)
+		if err != nil {
+			log.Printf("[WARN] Catalogue %s inutilisable: %v", config.TracksCatalog, err)
+		} else {
+			trackRegistry.Register(catalog)
+		}
+	}
+	// Les extraits Deezer expirent : hors ligne, seule une source locale
+	// permet encore de lancer une partie une fois la réserve périmée.
+	fallback, err := trackRegistry.Fallback(config.TracksFallback, deezerTracks)
+	switch {
+	case err == nil:
+		deezerTracks.SetFallback(fallback)
+		log.Printf("[OK] Source de secours Deezer: %s", fallback.Name())
+	case config.TracksFallback != "":
+		log.Printf("[WARN] TRACKS_FALLBACK %q indisponible", config.TracksFallback)
+	default:
+		log.Println("[WARN] Aucune source hors ligne (TRACKS_DIR, TRACKS_CATALOG) : sans Deezer, seuls les extraits encore valides de la réserve sont joués")
+	}
+	if err := trackRegistry.SetDefault(config.TrackProvider); err != nil {
+		log.Printf("[WARN] TRACK_PROVIDER %q indisponible, source par défaut: %s", config.TrackProvider, trackRegistry.DefaultName())
+	}
+	log.Printf("[OK] %d sources de musique, par défaut: %s", len(trackRegistry.All()), trackRegistry.DefaultName())
+
+	authStore := auth.NewSQLiteStore(db)
+	roomStore := rooms.NewPersistenceService(db)
 
-	_ = roomManager
-	_ = blindtestMgr
-	_ = petitbacMgr
+	authService := auth.NewService(authStore)
+	sessionManager := auth.NewSessionManager(authStore, authService)
 
-	wsHandler := websocket.NewHandler()
+	roomManager := rooms.InitManager(roomStore, roomStore)
+	if err := roomManager.RestoreRooms(); err != nil {
+		log.Printf("[WARN] Erreur restauration des salles: %v", err)
+	}
+	wsHandler := websocket.NewHandler(roomManager, config.DisconnectGrace)
 	log.Println("[OK] Handler WebSocket initialisé")
 
-	blindtestHandler := blindtest.GetHandler()
-	wsHandler.SetBlindTestHandler(blindtestHandler)
-	log.Println("[OK] Handler Blind Test connecté")
-
-	petitbacHandler := petitbac.GetHandler()
-	wsHandler.SetPetitBacHandler(petitbacHandler)
-	log.Println("[OK] Handler Petit Bac connecté")
+	count := engines.RegisterAll(games.GetRegistry(), engines.Deps{
+		Rooms:    roomManager,
+		Hub:      websocket.GetHub(),
+		Previews: media.GetPreviewProxy(),
+	})
+	log.Printf("[OK] %d moteurs de jeu enregistrés", count)
 
-	authHandler := auth.NewHandler(config.TemplateDir)
-	roomHandler := rooms.NewHandler(config.TemplateDir)
+	authHandler := auth.NewHandler(config.TemplateDir, authService, sessionManager)
+	roomHandler := rooms.NewHandler(config.TemplateDir, roomManager, sessionManager, wsHandler.LeaveRoom)
+	statsHandler := stats.NewHandler(config.TemplateDir, roomStore, authService, sessionManager)
+	tracksHandler := tracks.NewHandler(trackRegistry)
 
-	authMiddleware := auth.NewMiddleware()
+	authMiddleware := auth.NewMiddleware(sessionManager)
 
 	mux := http.NewServeMux()
 
@@ -93,7 +196,6 @@ func main() {
 			return
 		}
 
-		sessionManager := auth.NewSessionManager()
 		user, _ := sessionManager.GetUserFromRequest(r)
 
 		data := map[string]interface{}{
@@ -125,7 +227,6 @@ func main() {
 	mux.HandleFunc("/room/", roomHandler.HandleRoom)
 
 	mux.HandleFunc("/room/create", func(w http.ResponseWriter, r *http.Request) {
-		sessionManager := auth.NewSessionManager()
 		user, err := sessionManager.GetUserFromRequest(r)
 		if err != nil {
 			http.Redirect(w, r, "/login?redirect=/room/create", http.StatusSeeOther)
@@ -138,8 +239,10 @@ func main() {
 		}
 
 		data := map[string]interface{}{
-			"Title": "Créer une salle",
-			"User":  user,
+			"Title":         "Créer une salle",
+			"User":          user,
+			"TrackSources":  tracks.GetRegistry().All(),
+			"DefaultSource": tracks.GetRegistry().DefaultName(),
 		}
 
 		tmpl, err := template.ParseFiles(filepath.Join(config.TemplateDir, "create_room.html"))
@@ -157,7 +260,6 @@ func main() {
 	})
 
 	mux.HandleFunc("/room/join", func(w http.ResponseWriter, r *http.Request) {
-		sessionManager := auth.NewSessionManager()
 		user, err := sessionManager.GetUserFromRequest(r)
 		if err != nil {
 			http.Redirect(w, r, "/login?redirect=/room/join", http.StatusSeeOther)
@@ -189,6 +291,13 @@ func main() {
 		}
 	})
 
+	mux.HandleFunc("/leaderboard", statsHandler.HandleLeaderboard)
+	mux.HandleFunc("/api/leaderboard", statsHandler.HandleLeaderboardAPI)
+	mux.HandleFunc("/profile/", statsHandler.HandleProfile)
+	mux.HandleFunc("/api/profile/", statsHandler.HandleProfileAPI)
+	mux.HandleFunc("/api/games/", statsHandler.HandleGameDetailsAPI)
+
+	mux.HandleFunc("/api/genres", tracksHandler.HandleGenres)
 	mux.HandleFunc("/api/rooms", roomHandler.HandleGetRooms)
 	mux.HandleFunc("/api/rooms/create", roomHandler.HandleCreateRoom)
 	mux.HandleFunc("/api/rooms/join", roomHandler.HandleJoinRoom)
@@ -204,6 +313,17 @@ func main() {
 		http.NotFound(w, r)
 	}))
 
+	// Les extraits et la pochette floutée des indices ne sont servis qu'à
+	// travers le jeton de la manche en cours, et seulement aux joueurs et
+	// spectateurs de la salle. Les fichiers locaux n'ont pas d'autre route.
+	previewProxy := media.GetPreviewProxy()
+	previewProxy.SetMembership(roomManager.IsMember)
+	if localTracks != nil {
+		previewProxy.AddLocalSource(tracks.LocalSourcePrefix, localTracks)
+	}
+	mux.Handle(media.PreviewPrefix, authMiddleware.RequireAuth(previewProxy))
+	mux.Handle(media.CoverPrefix, authMiddleware.RequireAuth(http.HandlerFunc(previewProxy.ServeCover)))
+
 	mux.Handle("/ws/room/", authMiddleware.RequireAuth(http.HandlerFunc(wsHandler.HandleWebSocket)))
 
 	handler := loggingMiddleware(securityHeadersMiddleware(mux))
@@ -228,7 +348,7 @@ func main() {
 	<-quit
 
 	log.Println("[SERVER] Arrêt en cours...")
-	database.Close()
+	db.Close()
 	log.Println("[SERVER] Arrêté proprement")
 }
 
@@ -255,4 +375,17 @@ func getEnv(key, defaultValue string) string {
 		return value
 	}
 	return defaultValue
+}
+
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("[WARN] %s invalide (%q), valeur par défaut %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return d
 }
\ No newline at end of file
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"groupie-tracker/internal/database"
+)
+
+const migrateUsage = `Usage: server migrate <commande>
+
+Commandes:
+  up [n]     Applique les migrations en attente (toutes par défaut)
+  down [n]   Annule les n dernières migrations (1 par défaut)
+  status     Affiche l'état de chaque migration`
+
+// runMigrateCommand gère "server migrate up|down|status" sans démarrer le serveur.
+func runMigrateCommand(args []string, dbPath string) {
+	if len(args) == 0 || (args[0] != "up" && args[0] != "down" && args[0] != "status") {
+		fmt.Println(migrateUsage)
+		os.Exit(2)
+	}
+
+	steps := 0
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			fmt.Println(migrateUsage)
+			os.Exit(2)
+		}
+		steps = n
+	}
+
+	db, err := database.Open(dbPath)
+	if err != nil {
+		log.Fatalf("[FATAL] Erreur ouverture DB: %v", err)
+	}
+	defer db.Close()
+
+	switch args[0] {
+	case "up":
+		count, err := database.MigrateUp(db, steps)
+		if err != nil {
+			log.Fatalf("[FATAL] %v", err)
+		}
+		fmt.Printf("%d migration(s) appliquée(s)\n", count)
+
+	case "down":
+		count, err := database.MigrateDown(db, steps)
+		if err != nil {
+			log.Fatalf("[FATAL] %v", err)
+		}
+		fmt.Printf("%d migration(s) annulée(s)\n", count)
+
+	case "status":
+		status, err := database.GetMigrationStatus(db)
+		if err != nil {
+			log.Fatalf("[FATAL] %v", err)
+		}
+		for _, m := range status {
+			state := "en attente"
+			if m.Applied {
+				state = "appliquée le " + m.AppliedAt
+			}
+			fmt.Printf("%03d  %-36s %s\n", m.Version, m.Name, state)
+		}
+	}
+}
@@ -5,6 +5,7 @@ go 1.24.0
 require (
 	github.com/gorilla/websocket v1.5.3
 	golang.org/x/crypto v0.45.0
+	golang.org/x/text v0.31.0
 	modernc.org/sqlite v1.40.1
 )
 
@@ -14,7 +14,7 @@ type Handler struct {
 	templates      *template.Template
 }
 
-func NewHandler(templatesDir string) *Handler {
+func NewHandler(templatesDir string, service *Service, sessionManager *SessionManager) *Handler {
 	funcMap := template.FuncMap{
 		"slice": func(s string, start, end int) string {
 			if start >= len(s) {
@@ -36,8 +36,8 @@ func NewHandler(templatesDir string) *Handler {
 	}
 
 	return &Handler{
-		service:        NewService(),
-		sessionManager: NewSessionManager(),
+		service:        service,
+		sessionManager: sessionManager,
 		templates:      tmpl,
 	}
 }
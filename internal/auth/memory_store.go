@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"groupie-tracker/internal/models"
+)
+
+// MemoryStore implémente UserStore et SessionStore en mémoire, pour les tests
+// ou un serveur sans base de données.
+type MemoryStore struct {
+	users    map[int64]*models.User
+	sessions map[string]*models.Session
+	nextID   int64
+	mutex    sync.RWMutex
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		users:    make(map[int64]*models.User),
+		sessions: make(map[string]*models.Session),
+	}
+}
+
+func (s *MemoryStore) CreateUser(pseudo, email, passwordHash string) (*models.User, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, user := range s.users {
+		if user.Pseudo == pseudo {
+			return nil, ErrPseudoTaken
+		}
+		if user.Email == email {
+			return nil, ErrEmailTaken
+		}
+	}
+
+	s.nextID++
+	user := &models.User{
+		ID:           s.nextID,
+		Pseudo:       pseudo,
+		Email:        email,
+		PasswordHash: passwordHash,
+		CreatedAt:    time.Now(),
+	}
+	s.users[user.ID] = user
+
+	copied := *user
+	return &copied, nil
+}
+
+func (s *MemoryStore) GetUserByID(id int64) (*models.User, error) {
+	return s.findUser(func(user *models.User) bool { return user.ID == id })
+}
+
+func (s *MemoryStore) GetUserByPseudo(pseudo string) (*models.User, error) {
+	return s.findUser(func(user *models.User) bool { return user.Pseudo == pseudo })
+}
+
+func (s *MemoryStore) GetUserByLogin(pseudo, email string) (*models.User, error) {
+	return s.findUser(func(user *models.User) bool { return user.Pseudo == pseudo || user.Email == email })
+}
+
+func (s *MemoryStore) findUser(match func(*models.User) bool) (*models.User, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, user := range s.users {
+		if match(user) {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (s *MemoryStore) PseudoExists(pseudo string) (bool, error) {
+	_, err := s.GetUserByPseudo(pseudo)
+	return err == nil, nil
+}
+
+func (s *MemoryStore) EmailExists(email string) (bool, error) {
+	_, err := s.findUser(func(user *models.User) bool { return user.Email == email })
+	return err == nil, nil
+}
+
+func (s *MemoryStore) CreateSession(session *models.Session) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for id, existing := range s.sessions {
+		if existing.UserID == session.UserID {
+			delete(s.sessions, id)
+		}
+	}
+
+	copied := *session
+	s.sessions[session.ID] = &copied
+	return nil
+}
+
+func (s *MemoryStore) GetSession(sessionID string) (*models.Session, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	session, exists := s.sessions[sessionID]
+	if !exists {
+		return nil, ErrSessionNotFound
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (s *MemoryStore) DeleteSession(sessionID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.sessions, sessionID)
+	return nil
+}
+
+func (s *MemoryStore) ExtendSession(sessionID string, expiresAt time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if session, exists := s.sessions[sessionID]; exists {
+		session.ExpiresAt = expiresAt
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteExpiredSessions(now time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for id, session := range s.sessions {
+		if session.ExpiresAt.Before(now) {
+			delete(s.sessions, id)
+		}
+	}
+	return nil
+}
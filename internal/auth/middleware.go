@@ -18,9 +18,9 @@ type Middleware struct {
 	sessionManager *SessionManager
 }
 
-func NewMiddleware() *Middleware {
+func NewMiddleware(sessionManager *SessionManager) *Middleware {
 	return &Middleware{
-		sessionManager: NewSessionManager(),
+		sessionManager: sessionManager,
 	}
 }
 
@@ -1,14 +1,12 @@
 package auth
 
 import (
-	"database/sql"
 	"errors"
 	"regexp"
 	"strings"
 	"unicode"
 
 	"golang.org/x/crypto/bcrypt"
-	"groupie-tracker/internal/database"
 	"groupie-tracker/internal/models"
 )
 
@@ -23,12 +21,12 @@ var (
 )
 
 type Service struct {
-	db *sql.DB
+	users UserStore
 }
 
-func NewService() *Service {
+func NewService(users UserStore) *Service {
 	return &Service{
-		db: database.GetDB(),
+		users: users,
 	}
 }
 
@@ -45,7 +43,7 @@ func (s *Service) Register(pseudo, email, password string) (*models.User, error)
 		return nil, ErrWeakPassword
 	}
 
-	exists, err := s.pseudoExists(pseudo)
+	exists, err := s.users.PseudoExists(pseudo)
 	if err != nil {
 		return nil, err
 	}
@@ -53,7 +51,7 @@ func (s *Service) Register(pseudo, email, password string) (*models.User, error)
 		return nil, ErrPseudoTaken
 	}
 
-	exists, err = s.emailExists(email)
+	exists, err = s.users.EmailExists(strings.ToLower(email))
 	if err != nil {
 		return nil, err
 	}
@@ -66,35 +64,12 @@ func (s *Service) Register(pseudo, email, password string) (*models.User, error)
 		return nil, err
 	}
 
-	result, err := s.db.Exec(
-		"INSERT INTO users (pseudo, email, password_hash) VALUES (?, ?, ?)",
-		pseudo, strings.ToLower(email), string(hashedPassword),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	userID, err := result.LastInsertId()
-	if err != nil {
-		return nil, err
-	}
-
-	return s.GetUserByID(userID)
+	return s.users.CreateUser(pseudo, strings.ToLower(email), string(hashedPassword))
 }
 
 func (s *Service) Login(identifier, password string) (*models.User, error) {
-	var user models.User
-
-	query := `
-		SELECT id, pseudo, email, password_hash, created_at 
-		FROM users 
-		WHERE pseudo = ? OR email = ?
-	`
-	err := s.db.QueryRow(query, identifier, strings.ToLower(identifier)).Scan(
-		&user.ID, &user.Pseudo, &user.Email, &user.PasswordHash, &user.CreatedAt,
-	)
-
-	if err == sql.ErrNoRows {
+	user, err := s.users.GetUserByLogin(identifier, strings.ToLower(identifier))
+	if err == ErrUserNotFound {
 		return nil, ErrInvalidCredentials
 	}
 	if err != nil {
@@ -105,22 +80,15 @@ func (s *Service) Login(identifier, password string) (*models.User, error) {
 		return nil, ErrInvalidCredentials
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 func (s *Service) GetUserByID(id int64) (*models.User, error) {
-	var user models.User
-	query := "SELECT id, pseudo, email, password_hash, created_at FROM users WHERE id = ?"
-	err := s.db.QueryRow(query, id).Scan(
-		&user.ID, &user.Pseudo, &user.Email, &user.PasswordHash, &user.CreatedAt,
-	)
-	if err == sql.ErrNoRows {
-		return nil, ErrUserNotFound
-	}
-	if err != nil {
-		return nil, err
-	}
-	return &user, nil
+	return s.users.GetUserByID(id)
+}
+
+func (s *Service) GetUserByPseudo(pseudo string) (*models.User, error) {
+	return s.users.GetUserByPseudo(pseudo)
 }
 
 func isValidPseudo(pseudo string) bool {
@@ -165,15 +133,3 @@ func isValidPasswordCNIL(password string) bool {
 
 	return hasUpper && hasLower && hasDigit && hasSpecial
 }
-
-func (s *Service) pseudoExists(pseudo string) (bool, error) {
-	var count int
-	err := s.db.QueryRow("SELECT COUNT(*) FROM users WHERE pseudo = ?", pseudo).Scan(&count)
-	return count > 0, err
-}
-
-func (s *Service) emailExists(email string) (bool, error) {
-	var count int
-	err := s.db.QueryRow("SELECT COUNT(*) FROM users WHERE email = ?", strings.ToLower(email)).Scan(&count)
-	return count > 0, err
-}
\ No newline at end of file
@@ -2,13 +2,11 @@ package auth
 
 import (
 	"crypto/rand"
-	"database/sql"
 	"encoding/hex"
 	"errors"
 	"net/http"
 	"time"
 
-	"groupie-tracker/internal/database"
 	"groupie-tracker/internal/models"
 )
 
@@ -23,12 +21,14 @@ var (
 )
 
 type SessionManager struct {
-	db *sql.DB
+	sessions SessionStore
+	users    *Service
 }
 
-func NewSessionManager() *SessionManager {
+func NewSessionManager(sessions SessionStore, users *Service) *SessionManager {
 	return &SessionManager{
-		db: database.GetDB(),
+		sessions: sessions,
+		users:    users,
 	}
 }
 
@@ -39,39 +39,22 @@ func (sm *SessionManager) CreateSession(userID int64) (*models.Session, error) {
 	}
 
 	now := time.Now()
-	expiresAt := now.Add(SessionDuration)
-
-	_, err = sm.db.Exec("DELETE FROM sessions WHERE user_id = ?", userID)
-	if err != nil {
-		return nil, err
+	session := &models.Session{
+		ID:        sessionID,
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(SessionDuration),
 	}
 
-	_, err = sm.db.Exec(
-		"INSERT INTO sessions (id, user_id, created_at, expires_at) VALUES (?, ?, ?, ?)",
-		sessionID, userID, now, expiresAt,
-	)
-	if err != nil {
+	if err := sm.sessions.CreateSession(session); err != nil {
 		return nil, err
 	}
 
-	return &models.Session{
-		ID:        sessionID,
-		UserID:    userID,
-		CreatedAt: now,
-		ExpiresAt: expiresAt,
-	}, nil
+	return session, nil
 }
 
 func (sm *SessionManager) GetSession(sessionID string) (*models.Session, error) {
-	var session models.Session
-	query := "SELECT id, user_id, created_at, expires_at FROM sessions WHERE id = ?"
-	err := sm.db.QueryRow(query, sessionID).Scan(
-		&session.ID, &session.UserID, &session.CreatedAt, &session.ExpiresAt,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, ErrSessionNotFound
-	}
+	session, err := sm.sessions.GetSession(sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -81,26 +64,19 @@ func (sm *SessionManager) GetSession(sessionID string) (*models.Session, error)
 		return nil, ErrSessionExpired
 	}
 
-	return &session, nil
+	return session, nil
 }
 
 func (sm *SessionManager) DeleteSession(sessionID string) error {
-	_, err := sm.db.Exec("DELETE FROM sessions WHERE id = ?", sessionID)
-	return err
+	return sm.sessions.DeleteSession(sessionID)
 }
 
 func (sm *SessionManager) ExtendSession(sessionID string) error {
-	newExpiry := time.Now().Add(SessionDuration)
-	_, err := sm.db.Exec(
-		"UPDATE sessions SET expires_at = ? WHERE id = ?",
-		newExpiry, sessionID,
-	)
-	return err
+	return sm.sessions.ExtendSession(sessionID, time.Now().Add(SessionDuration))
 }
 
 func (sm *SessionManager) CleanExpiredSessions() error {
-	_, err := sm.db.Exec("DELETE FROM sessions WHERE expires_at < ?", time.Now())
-	return err
+	return sm.sessions.DeleteExpiredSessions(time.Now())
 }
 
 func (sm *SessionManager) SetSessionCookie(w http.ResponseWriter, session *models.Session) {
@@ -140,8 +116,7 @@ func (sm *SessionManager) GetUserFromRequest(r *http.Request) (*models.User, err
 		return nil, err
 	}
 
-	authService := NewService()
-	return authService.GetUserByID(session.UserID)
+	return sm.users.GetUserByID(session.UserID)
 }
 
 func generateSessionID() (string, error) {
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"database/sql"
+	"time"
+
+	"groupie-tracker/internal/models"
+)
+
+// SQLiteStore implémente UserStore et SessionStore sur les tables users et sessions.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{db: db}
+}
+
+func (s *SQLiteStore) CreateUser(pseudo, email, passwordHash string) (*models.User, error) {
+	result, err := s.db.Exec(
+		"INSERT INTO users (pseudo, email, password_hash) VALUES (?, ?, ?)",
+		pseudo, email, passwordHash,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetUserByID(userID)
+}
+
+func (s *SQLiteStore) GetUserByID(id int64) (*models.User, error) {
+	return s.getUser("SELECT id, pseudo, email, password_hash, created_at FROM users WHERE id = ?", id)
+}
+
+func (s *SQLiteStore) GetUserByPseudo(pseudo string) (*models.User, error) {
+	return s.getUser("SELECT id, pseudo, email, password_hash, created_at FROM users WHERE pseudo = ?", pseudo)
+}
+
+func (s *SQLiteStore) GetUserByLogin(pseudo, email string) (*models.User, error) {
+	query := `
+		SELECT id, pseudo, email, password_hash, created_at 
+		FROM users 
+		WHERE pseudo = ? OR email = ?
+	`
+	return s.getUser(query, pseudo, email)
+}
+
+func (s *SQLiteStore) getUser(query string, args ...interface{}) (*models.User, error) {
+	var user models.User
+	err := s.db.QueryRow(query, args...).Scan(
+		&user.ID, &user.Pseudo, &user.Email, &user.PasswordHash, &user.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *SQLiteStore) PseudoExists(pseudo string) (bool, error) {
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM users WHERE pseudo = ?", pseudo).Scan(&count)
+	return count > 0, err
+}
+
+func (s *SQLiteStore) EmailExists(email string) (bool, error) {
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM users WHERE email = ?", email).Scan(&count)
+	return count > 0, err
+}
+
+func (s *SQLiteStore) CreateSession(session *models.Session) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM sessions WHERE user_id = ?", session.UserID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO sessions (id, user_id, created_at, expires_at) VALUES (?, ?, ?, ?)",
+		session.ID, session.UserID, session.CreatedAt, session.ExpiresAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) GetSession(sessionID string) (*models.Session, error) {
+	var session models.Session
+	query := "SELECT id, user_id, created_at, expires_at FROM sessions WHERE id = ?"
+	err := s.db.QueryRow(query, sessionID).Scan(
+		&session.ID, &session.UserID, &session.CreatedAt, &session.ExpiresAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (s *SQLiteStore) DeleteSession(sessionID string) error {
+	_, err := s.db.Exec("DELETE FROM sessions WHERE id = ?", sessionID)
+	return err
+}
+
+func (s *SQLiteStore) ExtendSession(sessionID string, expiresAt time.Time) error {
+	_, err := s.db.Exec("UPDATE sessions SET expires_at = ? WHERE id = ?", expiresAt, sessionID)
+	return err
+}
+
+func (s *SQLiteStore) DeleteExpiredSessions(now time.Time) error {
+	_, err := s.db.Exec("DELETE FROM sessions WHERE expires_at < ?", now)
+	return err
+}
@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"time"
+
+	"groupie-tracker/internal/models"
+)
+
+// UserStore stocke les comptes. Les méthodes de lecture renvoient
+// ErrUserNotFound quand aucun utilisateur ne correspond.
+type UserStore interface {
+	CreateUser(pseudo, email, passwordHash string) (*models.User, error)
+	GetUserByID(id int64) (*models.User, error)
+	GetUserByPseudo(pseudo string) (*models.User, error)
+	GetUserByLogin(pseudo, email string) (*models.User, error)
+	PseudoExists(pseudo string) (bool, error)
+	EmailExists(email string) (bool, error)
+}
+
+// SessionStore stocke les sessions. CreateSession remplace les sessions
+// existantes de l'utilisateur ; GetSession renvoie ErrSessionNotFound.
+type SessionStore interface {
+	CreateSession(session *models.Session) error
+	GetSession(sessionID string) (*models.Session, error)
+	DeleteSession(sessionID string) error
+	ExtendSession(sessionID string, expiresAt time.Time) error
+	DeleteExpiredSessions(now time.Time) error
+}
@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"groupie-tracker/internal/database"
+	"groupie-tracker/internal/models"
+)
+
+// authStore réunit les deux interfaces, implémentées ensemble par
+// MemoryStore et SQLiteStore.
+type authStore interface {
+	UserStore
+	SessionStore
+}
+
+// testDB est la base SQLite partagée par les tests de contrat.
+var testDB *sql.DB
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "auth-test")
+	if err != nil {
+		log.Fatal(err)
+	}
+	testDB, err = database.Init(filepath.Join(dir, "test.db"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	code := m.Run()
+	testDB.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// forEachStore joue le même test sur chaque implémentation, la base SQLite
+// étant vidée avant chaque passage.
+func forEachStore(t *testing.T, test func(t *testing.T, store authStore)) {
+	stores := []struct {
+		name string
+		new  func(t *testing.T) authStore
+	}{
+		{"mémoire", func(t *testing.T) authStore { return NewMemoryStore() }},
+		{"sqlite", func(t *testing.T) authStore {
+			if err := database.ResetDatabase(testDB); err != nil {
+				t.Fatalf("ResetDatabase: %v", err)
+			}
+			return NewSQLiteStore(testDB)
+		}},
+	}
+
+	for _, s := range stores {
+		t.Run(s.name, func(t *testing.T) {
+			test(t, s.new(t))
+		})
+	}
+}
+
+func TestUserStoreContract(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store authStore) {
+		alice, err := store.CreateUser("Alice", "alice@example.com", "hash-a")
+		if err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+		if alice.ID == 0 || alice.Pseudo != "Alice" || alice.Email != "alice@example.com" || alice.PasswordHash != "hash-a" {
+			t.Errorf("CreateUser = %+v", alice)
+		}
+		if _, err := store.CreateUser("Bob", "bob@example.com", "hash-b"); err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+
+		if _, err := store.CreateUser("Alice", "autre@example.com", "hash"); err == nil {
+			t.Error("pseudo en double accepté")
+		}
+		if _, err := store.CreateUser("Autre", "alice@example.com", "hash"); err == nil {
+			t.Error("email en double accepté")
+		}
+
+		lookups := []struct {
+			name   string
+			lookup func() (*models.User, error)
+			want   string
+		}{
+			{"par id", func() (*models.User, error) { return store.GetUserByID(alice.ID) }, "Alice"},
+			{"par pseudo", func() (*models.User, error) { return store.GetUserByPseudo("Bob") }, "Bob"},
+			{"connexion par pseudo", func() (*models.User, error) { return store.GetUserByLogin("Alice", "") }, "Alice"},
+			{"connexion par email", func() (*models.User, error) { return store.GetUserByLogin("", "bob@example.com") }, "Bob"},
+			{"id inconnu", func() (*models.User, error) { return store.GetUserByID(alice.ID + 100) }, ""},
+			{"pseudo inconnu", func() (*models.User, error) { return store.GetUserByPseudo("Carol") }, ""},
+			{"connexion inconnue", func() (*models.User, error) { return store.GetUserByLogin("Carol", "carol@example.com") }, ""},
+		}
+		for _, tt := range lookups {
+			user, err := tt.lookup()
+			if tt.want == "" {
+				if !errors.Is(err, ErrUserNotFound) {
+					t.Errorf("%s: erreur %v, attendu %v", tt.name, err, ErrUserNotFound)
+				}
+				continue
+			}
+			if err != nil || user.Pseudo != tt.want {
+				t.Errorf("%s: %+v, %v, attendu %s", tt.name, user, err, tt.want)
+			}
+		}
+
+		exists := []struct {
+			name   string
+			exists func() (bool, error)
+			want   bool
+		}{
+			{"pseudo existant", func() (bool, error) { return store.PseudoExists("Alice") }, true},
+			{"pseudo libre", func() (bool, error) { return store.PseudoExists("Carol") }, false},
+			{"email existant", func() (bool, error) { return store.EmailExists("bob@example.com") }, true},
+			{"email libre", func() (bool, error) { return store.EmailExists("carol@example.com") }, false},
+		}
+		for _, tt := range exists {
+			if got, err := tt.exists(); err != nil || got != tt.want {
+				t.Errorf("%s: %v, %v, attendu %v", tt.name, got, err, tt.want)
+			}
+		}
+	})
+}
+
+func TestSessionStoreContract(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store authStore) {
+		alice, err := store.CreateUser("Alice", "alice@example.com", "hash-a")
+		if err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+		bob, err := store.CreateUser("Bob", "bob@example.com", "hash-b")
+		if err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+
+		now := time.Now().UTC().Truncate(time.Second)
+		newSession := func(id string, userID int64, expiresAt time.Time) {
+			t.Helper()
+			session := &models.Session{ID: id, UserID: userID, CreatedAt: now, ExpiresAt: expiresAt}
+			if err := store.CreateSession(session); err != nil {
+				t.Fatalf("CreateSession(%s): %v", id, err)
+			}
+		}
+
+		newSession("alice-1", alice.ID, now.Add(time.Hour))
+		session, err := store.GetSession("alice-1")
+		if err != nil || session.UserID != alice.ID || !session.ExpiresAt.Equal(now.Add(time.Hour)) {
+			t.Fatalf("GetSession = %+v, %v", session, err)
+		}
+
+		// Une nouvelle connexion remplace la session précédente.
+		newSession("alice-2", alice.ID, now.Add(time.Hour))
+		if _, err := store.GetSession("alice-1"); !errors.Is(err, ErrSessionNotFound) {
+			t.Errorf("ancienne session: %v, attendu %v", err, ErrSessionNotFound)
+		}
+
+		if err := store.ExtendSession("alice-2", now.Add(2*time.Hour)); err != nil {
+			t.Fatalf("ExtendSession: %v", err)
+		}
+		if session, err := store.GetSession("alice-2"); err != nil || !session.ExpiresAt.Equal(now.Add(2*time.Hour)) {
+			t.Errorf("session prolongée = %+v, %v", session, err)
+		}
+
+		newSession("bob-1", bob.ID, now.Add(-time.Minute))
+		if err := store.DeleteExpiredSessions(now); err != nil {
+			t.Fatalf("DeleteExpiredSessions: %v", err)
+		}
+		if _, err := store.GetSession("bob-1"); !errors.Is(err, ErrSessionNotFound) {
+			t.Errorf("session expirée: %v, attendu %v", err, ErrSessionNotFound)
+		}
+		if _, err := store.GetSession("alice-2"); err != nil {
+			t.Errorf("session valide supprimée: %v", err)
+		}
+
+		if err := store.DeleteSession("alice-2"); err != nil {
+			t.Fatalf("DeleteSession: %v", err)
+		}
+		if _, err := store.GetSession("alice-2"); !errors.Is(err, ErrSessionNotFound) {
+			t.Errorf("session supprimée: %v, attendu %v", err, ErrSessionNotFound)
+		}
+	})
+}
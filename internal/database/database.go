@@ -3,48 +3,42 @@ package database
 import (
 	"database/sql"
 	"log"
-	"sync"
 
 	_ "modernc.org/sqlite"
 )
 
-var (
-	db   *sql.DB
-	once sync.Once
-)
+// Init ouvre la base et applique les migrations en attente. La connexion est
+// créée une fois par main puis passée aux stockages qui en ont besoin.
+func Init(dbPath string) (*sql.DB, error) {
+	db, err := Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
 
-func Init(dbPath string) error {
-	var err error
-	once.Do(func() {
-		db, err = sql.Open("sqlite", dbPath+"?_foreign_keys=on")
-		if err != nil {
-			return
-		}
-
-		db.SetMaxOpenConns(25)
-		db.SetMaxIdleConns(5)
-
-		if err = db.Ping(); err != nil {
-			return
-		}
-
-		log.Println("[DB] Base de données SQLite connectée:", dbPath)
-
-		if err = RunMigrations(); err != nil {
-			log.Printf("[DB] Erreur migrations: %v", err)
-			return
-		}
-	})
-	return err
+	if err := RunMigrations(db); err != nil {
+		log.Printf("[DB] Erreur migrations: %v", err)
+		db.Close()
+		return nil, err
+	}
+	return db, nil
 }
 
-func GetDB() *sql.DB {
-	return db
-}
+// Open ouvre la connexion sans appliquer les migrations (utilisé par la
+// sous-commande migrate).
+func Open(dbPath string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", dbPath+"?_foreign_keys=on")
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(25)
+	db.SetMaxIdleConns(5)
 
-func Close() error {
-	if db != nil {
-		return db.Close()
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
 	}
-	return nil
-}
\ No newline at end of file
+
+	log.Println("[DB] Base de données SQLite connectée:", dbPath)
+	return db, nil
+}
@@ -1,148 +1,387 @@
-
 package database
 
 import (
+	"database/sql"
+	"fmt"
 	"log"
 )
 
-func RunMigrations() error {
-	migrations := []struct {
-		name string
-		sql  string
-	}{
-		{
-			name: "create_users_table",
-			sql: `
-				CREATE TABLE IF NOT EXISTS users (
-					id INTEGER PRIMARY KEY AUTOINCREMENT,
-					pseudo TEXT NOT NULL UNIQUE,
-					email TEXT NOT NULL UNIQUE,
-					password_hash TEXT NOT NULL,
-					created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-				);
-				CREATE INDEX IF NOT EXISTS idx_users_pseudo ON users(pseudo);
-				CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
-			`,
-		},
-		{
-			name: "create_sessions_table",
-			sql: `
-				CREATE TABLE IF NOT EXISTS sessions (
-					id TEXT PRIMARY KEY,
-					user_id INTEGER NOT NULL,
-					created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-					expires_at DATETIME NOT NULL,
-					FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
-				);
-				CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
-				CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);
-			`,
-		},
-		{
-			name: "create_rooms_table",
-			sql: `
-				CREATE TABLE IF NOT EXISTS rooms (
-					id TEXT PRIMARY KEY,
-					code TEXT NOT NULL UNIQUE,
-					name TEXT NOT NULL,
-					host_id INTEGER NOT NULL,
-					game_type TEXT NOT NULL,
-					status TEXT DEFAULT 'waiting',
-					config TEXT,
-					created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-					FOREIGN KEY (host_id) REFERENCES users(id) ON DELETE CASCADE
-				);
-				CREATE INDEX IF NOT EXISTS idx_rooms_code ON rooms(code);
-				CREATE INDEX IF NOT EXISTS idx_rooms_status ON rooms(status);
-			`,
-		},
-		{
-			name: "create_room_players_table",
-			sql: `
-				CREATE TABLE IF NOT EXISTS room_players (
-					id INTEGER PRIMARY KEY AUTOINCREMENT,
-					room_id TEXT NOT NULL,
-					user_id INTEGER NOT NULL,
-					score INTEGER DEFAULT 0,
-					is_host BOOLEAN DEFAULT 0,
-					joined_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-					FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE,
-					FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
-					UNIQUE(room_id, user_id)
-				);
-			`,
-		},
-		{
-			name: "create_game_scores_table",
-			sql: `
-				CREATE TABLE IF NOT EXISTS game_scores (
-					id INTEGER PRIMARY KEY AUTOINCREMENT,
-					room_id TEXT NOT NULL,
-					user_id INTEGER NOT NULL,
-					game_type TEXT NOT NULL,
-					score INTEGER DEFAULT 0,
-					round_scores TEXT,
-					created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-					FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE,
-					FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
-				);
-				CREATE INDEX IF NOT EXISTS idx_game_scores_room ON game_scores(room_id);
-				CREATE INDEX IF NOT EXISTS idx_game_scores_user ON game_scores(user_id);
-			`,
-		},
-		{
-			name: "create_petitbac_categories_table",
-			sql: `
-				CREATE TABLE IF NOT EXISTS petitbac_categories (
-					id INTEGER PRIMARY KEY AUTOINCREMENT,
-					name TEXT NOT NULL UNIQUE,
-					is_default BOOLEAN DEFAULT 0,
-					created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-				);
-				INSERT OR IGNORE INTO petitbac_categories (name, is_default) VALUES 
-					('artiste', 1),
-					('album', 1),
-					('groupe', 1),
-					('instrument', 1),
-					('featuring', 1);
-			`,
-		},
-		{
-			name: "create_spotify_tokens_table",
-			sql: `
-				CREATE TABLE IF NOT EXISTS spotify_tokens (
-					id INTEGER PRIMARY KEY AUTOINCREMENT,
-					access_token TEXT NOT NULL,
-					refresh_token TEXT,
-					expires_at DATETIME NOT NULL,
-					created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-				);
-			`,
-		},
+// Migration est une évolution numérotée du schéma. Up et Down sont exécutées
+// dans une transaction avec la mise à jour de schema_migrations.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Les migrations sont appliquées dans l'ordre des versions. Une migration
+// publiée ne doit plus être modifiée : on en ajoute une nouvelle.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_users_table",
+		Up: `
+			CREATE TABLE IF NOT EXISTS users (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				pseudo TEXT NOT NULL UNIQUE,
+				email TEXT NOT NULL UNIQUE,
+				password_hash TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			);
+			CREATE INDEX IF NOT EXISTS idx_users_pseudo ON users(pseudo);
+			CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+		`,
+		Down: `DROP TABLE IF EXISTS users;`,
+	},
+	{
+		Version: 2,
+		Name:    "create_sessions_table",
+		Up: `
+			CREATE TABLE IF NOT EXISTS sessions (
+				id TEXT PRIMARY KEY,
+				user_id INTEGER NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				expires_at DATETIME NOT NULL,
+				FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+			);
+			CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+			CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);
+		`,
+		Down: `DROP TABLE IF EXISTS sessions;`,
+	},
+	{
+		Version: 3,
+		Name:    "create_rooms_table",
+		Up: `
+			CREATE TABLE IF NOT EXISTS rooms (
+				id TEXT PRIMARY KEY,
+				code TEXT NOT NULL UNIQUE,
+				name TEXT NOT NULL,
+				host_id INTEGER NOT NULL,
+				game_type TEXT NOT NULL,
+				status TEXT DEFAULT 'waiting',
+				config TEXT,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (host_id) REFERENCES users(id) ON DELETE CASCADE
+			);
+			CREATE INDEX IF NOT EXISTS idx_rooms_code ON rooms(code);
+			CREATE INDEX IF NOT EXISTS idx_rooms_status ON rooms(status);
+		`,
+		Down: `DROP TABLE IF EXISTS rooms;`,
+	},
+	{
+		Version: 4,
+		Name:    "create_room_players_table",
+		Up: `
+			CREATE TABLE IF NOT EXISTS room_players (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				room_id TEXT NOT NULL,
+				user_id INTEGER NOT NULL,
+				score INTEGER DEFAULT 0,
+				is_host BOOLEAN DEFAULT 0,
+				joined_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE,
+				FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+				UNIQUE(room_id, user_id)
+			);
+		`,
+		Down: `DROP TABLE IF EXISTS room_players;`,
+	},
+	{
+		Version: 5,
+		Name:    "create_game_scores_table",
+		Up: `
+			CREATE TABLE IF NOT EXISTS game_scores (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				room_id TEXT NOT NULL,
+				user_id INTEGER NOT NULL,
+				game_type TEXT NOT NULL,
+				score INTEGER DEFAULT 0,
+				round_scores TEXT,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE,
+				FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+			);
+			CREATE INDEX IF NOT EXISTS idx_game_scores_room ON game_scores(room_id);
+			CREATE INDEX IF NOT EXISTS idx_game_scores_user ON game_scores(user_id);
+		`,
+		Down: `DROP TABLE IF EXISTS game_scores;`,
+	},
+	{
+		Version: 6,
+		Name:    "create_petitbac_categories_table",
+		Up: `
+			CREATE TABLE IF NOT EXISTS petitbac_categories (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL UNIQUE,
+				is_default BOOLEAN DEFAULT 0,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			);
+			INSERT OR IGNORE INTO petitbac_categories (name, is_default) VALUES 
+				('artiste', 1),
+				('album', 1),
+				('groupe', 1),
+				('instrument', 1),
+				('featuring', 1);
+		`,
+		Down: `DROP TABLE IF EXISTS petitbac_categories;`,
+	},
+	{
+		Version: 7,
+		Name:    "create_spotify_tokens_table",
+		Up: `
+			CREATE TABLE IF NOT EXISTS spotify_tokens (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				access_token TEXT NOT NULL,
+				refresh_token TEXT,
+				expires_at DATETIME NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			);
+		`,
+		Down: `DROP TABLE IF EXISTS spotify_tokens;`,
+	},
+	{
+		Version: 8,
+		Name:    "add_game_scores_game_index",
+		Up: `
+			CREATE INDEX IF NOT EXISTS idx_game_scores_game ON game_scores(room_id, created_at);
+		`,
+		Down: `DROP INDEX IF EXISTS idx_game_scores_game;`,
+	},
+	{
+		Version: 9,
+		Name:    "create_deezer_cache_table",
+		Up: `
+			CREATE TABLE IF NOT EXISTS deezer_cache (
+				cache_key TEXT PRIMARY KEY,
+				body BLOB NOT NULL,
+				stored_at INTEGER NOT NULL
+			);
+			CREATE INDEX IF NOT EXISTS idx_deezer_cache_stored_at ON deezer_cache(stored_at);
+		`,
+		Down: `DROP TABLE IF EXISTS deezer_cache;`,
+	},
+	{
+		Version: 10,
+		Name:    "create_deezer_track_pool_table",
+		Up: `
+			CREATE TABLE IF NOT EXISTS deezer_track_pool (
+				genre TEXT NOT NULL,
+				track_id TEXT NOT NULL,
+				title TEXT NOT NULL,
+				artist TEXT NOT NULL,
+				album TEXT NOT NULL DEFAULT '',
+				preview_url TEXT NOT NULL,
+				image_url TEXT NOT NULL DEFAULT '',
+				seen_at INTEGER NOT NULL,
+				PRIMARY KEY (genre, track_id)
+			);
+		`,
+		Down: `DROP TABLE IF EXISTS deezer_track_pool;`,
+	},
+	{
+		// Une partie était identifiée par (room_id, created_at) : deux parties
+		// enregistrées dans la même seconde se confondaient. Chaque partie a
+		// désormais sa ligne dans games ; les scores existants y sont rattachés.
+		Version: 11,
+		Name:    "add_game_scores_game_id",
+		Up: `
+			CREATE TABLE IF NOT EXISTS games (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				room_id TEXT NOT NULL,
+				game_type TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			);
+			ALTER TABLE game_scores ADD COLUMN game_id INTEGER;
+			INSERT INTO games (room_id, game_type, created_at)
+				SELECT room_id, MIN(game_type), created_at
+				FROM game_scores
+				GROUP BY room_id, created_at
+				ORDER BY MIN(id);
+			UPDATE game_scores SET game_id = (
+				SELECT g.id FROM games g
+				WHERE g.room_id = game_scores.room_id AND g.created_at = game_scores.created_at
+			);
+			CREATE INDEX IF NOT EXISTS idx_game_scores_game_id ON game_scores(game_id);
+		`,
+		Down: `
+			DROP INDEX IF EXISTS idx_game_scores_game_id;
+			ALTER TABLE game_scores DROP COLUMN game_id;
+			DROP TABLE IF EXISTS games;
+		`,
+	},
+}
+
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt string
+}
+
+func ensureMigrationsTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	return err
+}
+
+func appliedMigrations(db *sql.DB) (map[int]string, error) {
+	if err := ensureMigrationsTable(db); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
+	applied := make(map[int]string)
+	for rows.Next() {
+		var version int
+		var appliedAt string
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+func RunMigrations(db *sql.DB) error {
+	_, err := MigrateUp(db, 0)
+	return err
+}
+
+// MigrateUp applique au plus steps migrations en attente (toutes si steps <= 0)
+// et renvoie le nombre de migrations appliquées.
+func MigrateUp(db *sql.DB, steps int) (int, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
 	for _, m := range migrations {
-		log.Printf("[DB] Exécution migration: %s", m.name)
-		_, err := db.Exec(m.sql)
+		if steps > 0 && count >= steps {
+			break
+		}
+		if _, done := applied[m.Version]; done {
+			continue
+		}
+
+		log.Printf("[DB] Exécution migration %03d: %s", m.Version, m.Name)
+		err := inTransaction(db, func(tx *sql.Tx) error {
+			if _, err := tx.Exec(m.Up); err != nil {
+				return err
+			}
+			_, err := tx.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)", m.Version, m.Name)
+			return err
+		})
 		if err != nil {
-			log.Printf("[DB] Erreur migration %s: %v", m.name, err)
+			log.Printf("[DB] Erreur migration %03d %s: %v", m.Version, m.Name, err)
+			return count, fmt.Errorf("migration %03d %s: %w", m.Version, m.Name, err)
+		}
+		count++
+	}
+
+	if count > 0 {
+		log.Printf("[DB] %d migration(s) appliquée(s)", count)
+	} else {
+		log.Println("[DB] Schéma à jour")
+	}
+	return count, nil
+}
+
+// MigrateDown annule les steps dernières migrations appliquées (au moins une).
+func MigrateDown(db *sql.DB, steps int) (int, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(migrations) - 1; i >= 0 && count < steps; i-- {
+		m := migrations[i]
+		if _, done := applied[m.Version]; !done {
+			continue
+		}
+
+		log.Printf("[DB] Annulation migration %03d: %s", m.Version, m.Name)
+		err := inTransaction(db, func(tx *sql.Tx) error {
+			if _, err := tx.Exec(m.Down); err != nil {
+				return err
+			}
+			_, err := tx.Exec("DELETE FROM schema_migrations WHERE version = ?", m.Version)
 			return err
+		})
+		if err != nil {
+			log.Printf("[DB] Erreur annulation %03d %s: %v", m.Version, m.Name, err)
+			return count, fmt.Errorf("migration %03d %s: %w", m.Version, m.Name, err)
 		}
+		count++
 	}
 
-	log.Println("[DB] Toutes les migrations exécutées avec succès")
-	return nil
+	log.Printf("[DB] %d migration(s) annulée(s)", count)
+	return count, nil
 }
 
-func ResetDatabase() error {
+func GetMigrationStatus(db *sql.DB) ([]MigrationStatus, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		appliedAt, done := applied[m.Version]
+		status = append(status, MigrationStatus{
+			Version:   m.Version,
+			Name:      m.Name,
+			Applied:   done,
+			AppliedAt: appliedAt,
+		})
+	}
+	return status, nil
+}
+
+func inTransaction(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func ResetDatabase(db *sql.DB) error {
 	tables := []string{
 		"game_scores",
+		"games",
 		"room_players",
 		"rooms",
 		"sessions",
 		"petitbac_categories",
 		"spotify_tokens",
+		"deezer_cache",
+		"deezer_track_pool",
 		"users",
+		"schema_migrations",
 	}
 
 	for _, table := range tables {
@@ -152,5 +391,5 @@ func ResetDatabase() error {
 		}
 	}
 
-	return RunMigrations()
-}
\ No newline at end of file
+	return RunMigrations(db)
+}
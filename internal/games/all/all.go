@@ -0,0 +1,8 @@
+// Package all importe tous les jeux pour que leur init() inscrive leur
+// moteur auprès du paquet engines. Un nouveau jeu s'ajoute ici, pas dans main.
+package all
+
+import (
+	_ "groupie-tracker/internal/games/blindtest"
+	_ "groupie-tracker/internal/games/petitbac"
+)
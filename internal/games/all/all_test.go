@@ -0,0 +1,32 @@
+package all
+
+import (
+	"testing"
+
+	"groupie-tracker/internal/games"
+	"groupie-tracker/internal/games/engines"
+	"groupie-tracker/internal/media"
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/rooms"
+	"groupie-tracker/internal/websocket"
+)
+
+func TestRegisterAll(t *testing.T) {
+	store := rooms.NewMemoryStore()
+	registry := games.NewRegistry()
+	count := engines.RegisterAll(registry, engines.Deps{
+		Rooms:    rooms.NewManager(store, store),
+		Hub:      websocket.GetHub(),
+		Previews: media.GetPreviewProxy(),
+	})
+
+	want := []models.GameType{models.GameTypeBlindTest, models.GameTypePetitBac}
+	if count != len(want) {
+		t.Fatalf("%d moteurs enregistrés, attendu %d", count, len(want))
+	}
+	for i, engine := range registry.All() {
+		if engine.GameType() != want[i] {
+			t.Errorf("moteur %d = %s, attendu %s", i, engine.GameType(), want[i])
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package blindtest
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"groupie-tracker/internal/rooms"
+)
+
+// AnswerModeBuzzer fait répondre les joueurs un par un : le premier buzz
+// reçu par le serveur fige la manche et donne la main à son auteur.
+const AnswerModeBuzzer = "buzzer"
+
+// Raisons d'une élimination au buzzer (bt_buzz_lock).
+const (
+	LockoutWrong        = "wrong"
+	LockoutTimeout      = "timeout"
+	LockoutDisconnected = "disconnected"
+)
+
+var (
+	ErrNotBuzzerMode = errors.New("cette salle n'utilise pas le buzzer")
+	ErrBuzzRequired  = errors.New("buzzez avant de répondre")
+	ErrBuzzTaken     = errors.New("un autre joueur a déjà buzzé")
+	ErrLockedOut     = errors.New("vous êtes éliminé pour cette manche")
+	ErrAlreadyFound  = errors.New("vous avez déjà trouvé")
+)
+
+// Buzz est le joueur qui a la main. AnswerTime est le temps qui lui reste
+// pour répondre ; seq distingue les buzz successifs d'une manche pour que
+// l'expiration d'un ancien buzz n'élimine pas le suivant.
+type Buzz struct {
+	UserID     int64  `json:"user_id"`
+	Pseudo     string `json:"pseudo"`
+	AnswerTime int    `json:"answer_time"`
+	seq        int
+	deadline   time.Time
+}
+
+// BuzzLockout est le contenu d'un message bt_buzz_lock.
+type BuzzLockout struct {
+	UserID int64  `json:"user_id"`
+	Pseudo string `json:"pseudo"`
+	Reason string `json:"reason"`
+}
+
+// Buzz donne la main au joueur si personne ne l'a : l'ordre d'arrivée est
+// celui dans lequel les messages prennent state.Mutex. Le compte à rebours
+// de la manche est suspendu jusqu'à la réponse ou l'expiration.
+func (gm *GameManager) Buzz(roomID string, userID int64, pseudo string) (*Buzz, error) {
+	state := gm.GetGameState(roomID)
+	if state == nil {
+		return nil, rooms.ErrRoomNotFound
+	}
+
+	state.Mutex.Lock()
+	defer state.Mutex.Unlock()
+
+	if state.Settings.AnswerMode != AnswerModeBuzzer {
+		return nil, ErrNotBuzzerMode
+	}
+	if state.CurrentTrack == nil || state.IsRevealed {
+		return nil, ErrRoundOver
+	}
+	if state.LockedOut[userID] {
+		return nil, ErrLockedOut
+	}
+	if state.hasFoundBoth(userID) {
+		return nil, ErrAlreadyFound
+	}
+	if state.Settings.ClipMode == ClipModeProgressive && (state.Stage == 0 || state.guessesLeft(userID) == 0) {
+		return nil, ErrNoGuessesLeft
+	}
+	if state.Buzz != nil {
+		return nil, ErrBuzzTaken
+	}
+
+	state.BuzzSeq++
+	state.Buzz = &Buzz{
+		UserID:     userID,
+		Pseudo:     pseudo,
+		AnswerTime: state.Settings.BuzzTime,
+		seq:        state.BuzzSeq,
+		deadline:   time.Now().Add(time.Duration(state.Settings.BuzzTime) * time.Second),
+	}
+	state.HasAnswered[userID] = true
+
+	buzz := *state.Buzz
+	return &buzz, nil
+}
+
+// ExpireBuzz élimine le joueur qui n'a pas répondu à temps. Il renvoie nil
+// si ce buzz a déjà reçu une réponse ou si la manche est finie.
+func (gm *GameManager) ExpireBuzz(roomID string, seq int) *BuzzLockout {
+	state := gm.GetGameState(roomID)
+	if state == nil {
+		return nil
+	}
+
+	state.Mutex.Lock()
+	defer state.Mutex.Unlock()
+
+	if state.Buzz == nil || state.Buzz.seq != seq || state.IsRevealed {
+		return nil
+	}
+	return state.lockOut(LockoutTimeout)
+}
+
+// ReleaseBuzz élimine le joueur qui a la main s'il s'est déconnecté, pour
+// que la manche reprenne sans attendre la fin de son temps de réponse. Il
+// renvoie nil si ce joueur n'avait pas la main.
+func (gm *GameManager) ReleaseBuzz(roomID string, userID int64) *BuzzLockout {
+	state := gm.GetGameState(roomID)
+	if state == nil {
+		return nil
+	}
+
+	state.Mutex.Lock()
+	defer state.Mutex.Unlock()
+
+	if state.Buzz == nil || state.Buzz.UserID != userID || state.IsRevealed {
+		return nil
+	}
+	return state.lockOut(LockoutDisconnected)
+}
+
+// IsBuzzed indique qu'un joueur a la main : le compte à rebours est figé.
+func (gm *GameManager) IsBuzzed(roomID string) bool {
+	state := gm.GetGameState(roomID)
+	if state == nil {
+		return false
+	}
+	state.Mutex.RLock()
+	defer state.Mutex.RUnlock()
+	return state.Buzz != nil
+}
+
+// lockOut élimine le joueur qui a la main et rend la main aux autres.
+// L'appelant tient state.Mutex.
+func (s *GameState) lockOut(reason string) *BuzzLockout {
+	lockout := &BuzzLockout{UserID: s.Buzz.UserID, Pseudo: s.Buzz.Pseudo, Reason: reason}
+	s.LockedOut[s.Buzz.UserID] = true
+	s.Buzz = nil
+	return lockout
+}
+
+// currentBuzz renvoie le buzz en cours avec le temps de réponse restant,
+// pour la reprise après un rechargement de page.
+func (s *GameState) currentBuzz() *Buzz {
+	if s.Buzz == nil {
+		return nil
+	}
+	buzz := *s.Buzz
+	buzz.AnswerTime = max(int(math.Ceil(time.Until(s.Buzz.deadline).Seconds())), 0)
+	return &buzz
+}
@@ -0,0 +1,209 @@
+package blindtest
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBuzz(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(*GameState)
+		wantErr error
+	}{
+		{"premier buzz", func(*GameState) {}, nil},
+		{"hors mode buzzer", func(s *GameState) { s.Settings.AnswerMode = AnswerModeText }, ErrNotBuzzerMode},
+		{"manche révélée", func(s *GameState) { s.IsRevealed = true }, ErrRoundOver},
+		{"sans manche", func(s *GameState) { s.CurrentTrack = nil }, ErrRoundOver},
+		{"joueur éliminé", func(s *GameState) { s.LockedOut[1] = true }, ErrLockedOut},
+		{"déjà trouvé", func(s *GameState) { s.FoundTitle[1], s.FoundArtist[1] = true, true }, ErrAlreadyFound},
+		{"main déjà prise", func(s *GameState) { s.Buzz = &Buzz{UserID: 2, Pseudo: "Bob"} }, ErrBuzzTaken},
+		{"progressif avant la première étape", func(s *GameState) { s.Settings.ClipMode = ClipModeProgressive }, ErrNoGuessesLeft},
+		{"progressif sans essai", func(s *GameState) {
+			s.Settings.ClipMode = ClipModeProgressive
+			s.Stage = 2
+			s.StageGuesses[1] = s.Settings.StageGuesses
+		}, ErrNoGuessesLeft},
+		{"progressif avec essais", func(s *GameState) {
+			s.Settings.ClipMode = ClipModeProgressive
+			s.Stage = 2
+		}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm, state := newTestRound(t, testSettings(AnswerModeBuzzer))
+			tt.setup(state)
+
+			buzz, err := gm.Buzz(testRoomID, 1, "Alice")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Buzz = %v, attendu %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if state.Buzz != nil && state.Buzz.UserID == 1 {
+					t.Errorf("main donnée malgré l'erreur")
+				}
+				return
+			}
+			if buzz.UserID != 1 || buzz.Pseudo != "Alice" || buzz.AnswerTime != DefaultBuzzTime {
+				t.Errorf("buzz = %+v", *buzz)
+			}
+			if state.Buzz == nil || state.Buzz.UserID != 1 || state.Buzz.seq != state.BuzzSeq || !state.HasAnswered[1] {
+				t.Errorf("main non enregistrée: %+v", state.Buzz)
+			}
+			if !gm.IsBuzzed(testRoomID) {
+				t.Error("IsBuzzed = false après un buzz")
+			}
+		})
+	}
+}
+
+func TestExpireBuzz(t *testing.T) {
+	tests := []struct {
+		name  string
+		seq   func(first int) int
+		setup func(*GameState)
+		want  bool
+	}{
+		{"buzz en cours", func(first int) int { return first }, func(*GameState) {}, true},
+		{"ancien buzz", func(first int) int { return first - 1 }, func(*GameState) {}, false},
+		{"manche révélée", func(first int) int { return first }, func(s *GameState) { s.IsRevealed = true }, false},
+		{"buzz déjà répondu", func(first int) int { return first }, func(s *GameState) { s.Buzz = nil }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm, state := newTestRound(t, testSettings(AnswerModeBuzzer))
+			state.BuzzSeq = 4
+			if _, err := gm.Buzz(testRoomID, 1, "Alice"); err != nil {
+				t.Fatalf("Buzz: %v", err)
+			}
+			tt.setup(state)
+
+			lockout := gm.ExpireBuzz(testRoomID, tt.seq(state.BuzzSeq))
+			if (lockout != nil) != tt.want {
+				t.Fatalf("ExpireBuzz = %+v, attendu une élimination: %v", lockout, tt.want)
+			}
+			if !tt.want {
+				return
+			}
+			if *lockout != (BuzzLockout{UserID: 1, Pseudo: "Alice", Reason: LockoutTimeout}) {
+				t.Errorf("élimination = %+v", *lockout)
+			}
+			if state.Buzz != nil || !state.LockedOut[1] {
+				t.Errorf("main non rendue: buzz %+v, éliminé %v", state.Buzz, state.LockedOut[1])
+			}
+			if again := gm.ExpireBuzz(testRoomID, tt.seq(state.BuzzSeq)); again != nil {
+				t.Errorf("seconde expiration du même buzz: %+v", again)
+			}
+		})
+	}
+}
+
+func TestLockOut(t *testing.T) {
+	_, state := newTestRound(t, testSettings(AnswerModeBuzzer))
+	state.Buzz = &Buzz{UserID: 2, Pseudo: "Bob"}
+
+	lockout := state.lockOut(LockoutWrong)
+	if *lockout != (BuzzLockout{UserID: 2, Pseudo: "Bob", Reason: LockoutWrong}) {
+		t.Errorf("élimination = %+v", *lockout)
+	}
+	if state.Buzz != nil || !state.LockedOut[2] || state.LockedOut[1] {
+		t.Errorf("après élimination: buzz %+v, éliminés %v", state.Buzz, state.LockedOut)
+	}
+}
+
+func TestBuzzerAnswers(t *testing.T) {
+	gm, state := newTestRound(t, testSettings(AnswerModeBuzzer))
+
+	steps := []struct {
+		name        string
+		userID      int64
+		buzz        bool
+		answer      string
+		wantErr     error
+		wantCorrect bool
+		wantLocked  bool
+	}{
+		{"réponse sans buzz", 1, false, "Queen", ErrBuzzRequired, false, false},
+		{"Bob buzze", 2, true, "", nil, false, false},
+		{"Alice répond à la place de Bob", 1, false, "Queen", ErrBuzzRequired, false, false},
+		{"Bob se trompe", 2, false, "Abba", nil, false, true},
+		{"Bob rebuzze", 2, true, "", ErrLockedOut, false, false},
+		{"Alice buzze", 1, true, "", nil, false, false},
+		{"Alice trouve l'artiste", 1, false, "Queen", nil, true, false},
+		{"Alice rebuzze pour le titre", 1, true, "", nil, false, false},
+	}
+
+	for _, step := range steps {
+		var err error
+		var result *AnswerResult
+		if step.buzz {
+			_, err = gm.Buzz(testRoomID, step.userID, "Joueur")
+		} else {
+			result, err = gm.SubmitAnswer(testRoomID, step.userID, step.answer)
+		}
+		if !errors.Is(err, step.wantErr) {
+			t.Fatalf("%s: erreur %v, attendu %v", step.name, err, step.wantErr)
+		}
+		if result == nil {
+			continue
+		}
+		if result.IsCorrect != step.wantCorrect || result.LockedOut != step.wantLocked {
+			t.Errorf("%s: correct %v, éliminé %v", step.name, result.IsCorrect, result.LockedOut)
+		}
+		if state.Buzz != nil {
+			t.Errorf("%s: la réponse n'a pas rendu la main", step.name)
+		}
+	}
+
+	if !state.LockedOut[2] || state.LockedOut[1] {
+		t.Errorf("éliminés = %v, attendu seulement Bob", state.LockedOut)
+	}
+}
+
+func TestReleaseBuzz(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID int64
+		setup  func(*GameState)
+		want   bool
+	}{
+		{"joueur qui a la main", 1, func(*GameState) {}, true},
+		{"autre joueur", 2, func(*GameState) {}, false},
+		{"manche révélée", 1, func(s *GameState) { s.IsRevealed = true }, false},
+		{"buzz déjà répondu", 1, func(s *GameState) { s.Buzz = nil }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm, state := newTestRound(t, testSettings(AnswerModeBuzzer))
+			if _, err := gm.Buzz(testRoomID, 1, "Alice"); err != nil {
+				t.Fatalf("Buzz: %v", err)
+			}
+			seq := state.BuzzSeq
+			tt.setup(state)
+
+			lockout := gm.ReleaseBuzz(testRoomID, tt.userID)
+			if (lockout != nil) != tt.want {
+				t.Fatalf("ReleaseBuzz = %+v, attendu une élimination: %v", lockout, tt.want)
+			}
+			if !tt.want {
+				return
+			}
+			if *lockout != (BuzzLockout{UserID: 1, Pseudo: "Alice", Reason: LockoutDisconnected}) {
+				t.Errorf("élimination = %+v", *lockout)
+			}
+			if gm.IsBuzzed(testRoomID) || !state.LockedOut[1] {
+				t.Errorf("main non rendue: buzz %+v, éliminé %v", state.Buzz, state.LockedOut[1])
+			}
+			// Le délai de réponse du buzz abandonné ne doit plus rien faire.
+			if expired := gm.ExpireBuzz(testRoomID, seq); expired != nil {
+				t.Errorf("expiration après la déconnexion: %+v", expired)
+			}
+			if _, err := gm.Buzz(testRoomID, 2, "Bob"); err != nil {
+				t.Errorf("les autres ne peuvent plus buzzer: %v", err)
+			}
+		})
+	}
+}
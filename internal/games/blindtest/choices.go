@@ -0,0 +1,99 @@
+package blindtest
+
+import (
+	"errors"
+	"log"
+	"math/rand/v2"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/tracks"
+)
+
+// Modes de réponse : texte libre (bt_answer.answer) ou choix parmi quatre
+// propositions (bt_answer.choice_id).
+const (
+	AnswerModeText    = "text"
+	AnswerModeChoices = "choices"
+)
+
+// choiceCount est le nombre de propositions d'une manche, bonne réponse
+// comprise. decoyPoolFactor fixe la taille du réservoir de leurres tiré chez
+// la source en plus des pistes de la partie.
+const (
+	choiceCount     = 4
+	decoyPoolFactor = 3
+)
+
+var (
+	ErrChoiceRequired = errors.New("choisissez une des propositions")
+	ErrTextRequired   = errors.New("cette salle attend une réponse libre")
+	ErrInvalidChoice  = errors.New("proposition inconnue")
+)
+
+// Choice est une proposition d'une manche. L'identifiant ne dit rien de la
+// piste : seul le serveur sait laquelle est la bonne.
+type Choice struct {
+	ID     int    `json:"id"`
+	Title  string `json:"title"`
+	Artist string `json:"artist"`
+}
+
+// loadDecoyPool tire chez la source des pistes qui servent uniquement de
+// leurres. Si la source n'en fournit pas assez (petite playlist, genre
+// rare), les autres pistes de la partie font l'affaire.
+func loadDecoyPool(provider tracks.TrackProvider, genre string, playlist []*models.SpotifyTrack) []*models.SpotifyTrack {
+	pool := append([]*models.SpotifyTrack(nil), playlist...)
+
+	extra, err := provider.GetTracks(genre, len(playlist)*decoyPoolFactor)
+	if err != nil {
+		log.Printf("[BlindTest] Leurres limités aux pistes de la partie (%s): %v", provider.Name(), err)
+		return pool
+	}
+
+	seen := make(map[string]bool, len(pool)+len(extra))
+	for _, track := range pool {
+		seen[track.ID] = true
+	}
+	for _, track := range extra {
+		if !seen[track.ID] {
+			seen[track.ID] = true
+			pool = append(pool, track)
+		}
+	}
+	return pool
+}
+
+// buildChoices mélange la bonne piste et jusqu'à trois leurres du
+// réservoir. Un leurre qui porte le même titre que la bonne réponse (autre
+// version, reprise) ou qu'un autre leurre est écarté.
+func buildChoices(track *models.SpotifyTrack, pool []*models.SpotifyTrack) ([]Choice, int) {
+	titles := map[string]bool{cleanTitle(track.Name): true}
+	picked := []*models.SpotifyTrack{track}
+
+	for _, i := range rand.Perm(len(pool)) {
+		if len(picked) == choiceCount {
+			break
+		}
+		decoy := pool[i]
+		title := cleanTitle(decoy.Name)
+		if decoy.ID == track.ID || titles[title] {
+			continue
+		}
+		titles[title] = true
+		picked = append(picked, decoy)
+	}
+
+	rand.Shuffle(len(picked), func(i, j int) {
+		picked[i], picked[j] = picked[j], picked[i]
+	})
+
+	choices := make([]Choice, len(picked))
+	correct := 0
+	for i, candidate := range picked {
+		choices[i] = Choice{ID: i + 1, Title: candidate.Name, Artist: candidate.Artist}
+		if candidate == track {
+			correct = i + 1
+		}
+	}
+	return choices, correct
+}
@@ -0,0 +1,196 @@
+package blindtest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/tracks"
+)
+
+func decoy(id, title, artist string) *models.SpotifyTrack {
+	return &models.SpotifyTrack{ID: id, Name: title, Artist: artist}
+}
+
+func TestBuildChoices(t *testing.T) {
+	tests := []struct {
+		name      string
+		pool      []*models.SpotifyTrack
+		wantCount int
+		forbidden []string // identifiants qui ne doivent jamais être proposés
+	}{
+		{"réservoir complet", []*models.SpotifyTrack{
+			decoy("2", "Formidable", "Stromae"),
+			decoy("3", "Hotel California", "Eagles"),
+			decoy("4", "Get Lucky", "Daft Punk"),
+			decoy("5", "Alors on danse", "Stromae"),
+			decoy("6", "Smells Like Teen Spirit", "Nirvana"),
+		}, 4, nil},
+		{"bonne piste dans le réservoir", []*models.SpotifyTrack{
+			testTrack(),
+			decoy("2", "Formidable", "Stromae"),
+			decoy("3", "Hotel California", "Eagles"),
+			decoy("4", "Get Lucky", "Daft Punk"),
+		}, 4, nil},
+		{"autre version de la bonne piste", []*models.SpotifyTrack{
+			decoy("2", "Bohemian Rhapsody - Remastered 2011", "Queen"),
+			decoy("3", "Bohemian Rhapsody (Live Aid)", "Queen"),
+			decoy("4", "Get Lucky", "Daft Punk"),
+		}, 2, []string{"2", "3"}},
+		{"leurres du même titre", []*models.SpotifyTrack{
+			decoy("2", "Yesterday", "The Beatles"),
+			decoy("3", "Yesterday", "Ray Charles"),
+			decoy("4", "Yesterday - 2009 Remaster", "The Beatles"),
+			decoy("5", "Get Lucky", "Daft Punk"),
+		}, 3, nil},
+		{"réservoir trop petit", []*models.SpotifyTrack{
+			decoy("2", "Formidable", "Stromae"),
+		}, 2, nil},
+		{"réservoir vide", nil, 1, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			track := testTrack()
+			// Le tirage est aléatoire : plusieurs tirages couvrent les ordres.
+			for draw := 0; draw < 50; draw++ {
+				choices, correct := buildChoices(track, tt.pool)
+				if len(choices) != tt.wantCount {
+					t.Fatalf("%d propositions, attendu %d: %v", len(choices), tt.wantCount, choices)
+				}
+				if correct < 1 || correct > len(choices) || choices[correct-1].Title != track.Name {
+					t.Fatalf("bonne réponse %d introuvable dans %v", correct, choices)
+				}
+
+				titles := make(map[string]bool)
+				for i, choice := range choices {
+					if choice.ID != i+1 {
+						t.Errorf("identifiant %d en position %d", choice.ID, i+1)
+					}
+					title := cleanTitle(choice.Title)
+					if titles[title] {
+						t.Fatalf("titre %q proposé deux fois: %v", choice.Title, choices)
+					}
+					titles[title] = true
+				}
+				for _, candidate := range tt.pool {
+					if !slices.Contains(tt.forbidden, candidate.ID) {
+						continue
+					}
+					for _, choice := range choices {
+						if choice.Title == candidate.Name {
+							t.Fatalf("leurre %s proposé: %v", candidate.ID, choices)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+// decoyProvider renvoie toujours les mêmes pistes, ou err.
+type decoyProvider struct {
+	tracks []*models.SpotifyTrack
+	err    error
+}
+
+func (p decoyProvider) Name() string           { return "test" }
+func (p decoyProvider) DisplayName() string    { return "Test" }
+func (p decoyProvider) Genres() []tracks.Genre { return nil }
+func (p decoyProvider) GetTracks(string, int) ([]*models.SpotifyTrack, error) {
+	return p.tracks, p.err
+}
+
+func TestLoadDecoyPool(t *testing.T) {
+	playlist := []*models.SpotifyTrack{testTrack(), decoy("2", "Formidable", "Stromae")}
+
+	tests := []struct {
+		name     string
+		provider decoyProvider
+		want     []string
+	}{
+		{"leurres ajoutés", decoyProvider{tracks: []*models.SpotifyTrack{
+			decoy("3", "Get Lucky", "Daft Punk"),
+			decoy("4", "Hotel California", "Eagles"),
+		}}, []string{"1", "2", "3", "4"}},
+		{"doublons écartés", decoyProvider{tracks: []*models.SpotifyTrack{
+			decoy("2", "Formidable", "Stromae"),
+			decoy("3", "Get Lucky", "Daft Punk"),
+			decoy("3", "Get Lucky", "Daft Punk"),
+		}}, []string{"1", "2", "3"}},
+		{"source en panne", decoyProvider{err: errors.New("indisponible")}, []string{"1", "2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := loadDecoyPool(tt.provider, "pop", playlist)
+			var ids []string
+			for _, track := range pool {
+				ids = append(ids, track.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+				t.Errorf("réservoir = %v, attendu %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubmitChoice(t *testing.T) {
+	pool := []*models.SpotifyTrack{
+		decoy("2", "Formidable", "Stromae"),
+		decoy("3", "Hotel California", "Eagles"),
+		decoy("4", "Get Lucky", "Daft Punk"),
+	}
+	gm, state := newTestRound(t, testSettings(AnswerModeChoices))
+	state.Choices, state.CorrectChoice = buildChoices(state.CurrentTrack, pool)
+	wrong := state.CorrectChoice%len(state.Choices) + 1
+
+	tests := []struct {
+		name      string
+		userID    int64
+		choiceID  int
+		wantErr   error
+		correct   bool
+		already   bool
+		wantTotal int // points de la manche après le choix ; -1 : inchangés
+	}{
+		{"bonne proposition", 1, state.CorrectChoice, nil, true, false, -1},
+		{"second choix refusé", 1, wrong, nil, false, true, -1},
+		{"mauvaise proposition", 2, wrong, nil, false, false, 0},
+		{"proposition inconnue", 3, 99, ErrInvalidChoice, false, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := state.RoundPoints[tt.userID]
+			result, err := gm.SubmitChoice(testRoomID, tt.userID, tt.choiceID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("erreur = %v, attendu %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if _, chosen := state.Chosen[tt.userID]; chosen {
+					t.Error("choix invalide enregistré")
+				}
+				return
+			}
+			if result.IsCorrect != tt.correct || result.AlreadyAnswered != tt.already || !result.Locked {
+				t.Errorf("résultat = %+v", *result)
+			}
+			if tt.correct && (!result.Complete() || result.Points <= 0) {
+				t.Errorf("bonne proposition: titre %v, artiste %v, %d points", result.HasTitle, result.HasArtist, result.Points)
+			}
+			if tt.already && state.RoundPoints[tt.userID] != before {
+				t.Errorf("second choix noté: %d points, avant %d", state.RoundPoints[tt.userID], before)
+			}
+			if tt.wantTotal >= 0 && state.RoundPoints[tt.userID] != tt.wantTotal {
+				t.Errorf("points de la manche = %d, attendu %d", state.RoundPoints[tt.userID], tt.wantTotal)
+			}
+		})
+	}
+
+	if _, err := gm.SubmitAnswer(testRoomID, 4, "Queen"); !errors.Is(err, ErrChoiceRequired) {
+		t.Errorf("réponse libre en mode choix: %v, attendu %v", err, ErrChoiceRequired)
+	}
+}
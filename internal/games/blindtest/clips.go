@@ -0,0 +1,117 @@
+package blindtest
+
+import (
+	"errors"
+
+	"groupie-tracker/internal/rooms"
+)
+
+// Modes d'écoute : extrait complet dès le début de la manche, ou extrait
+// débloqué par étapes de plus en plus longues (façon Heardle).
+const (
+	ClipModeFull        = "full"
+	ClipModeProgressive = "progressive"
+)
+
+// ClipStages sont les durées d'extrait débloquées à chaque étape, en
+// secondes. stageDiscount retire un pourcentage des points d'une bonne
+// réponse selon l'étape à laquelle elle arrive.
+var (
+	ClipStages    = []int{1, 2, 4, 8, 16}
+	stageDiscount = []int{0, 20, 40, 60, 75}
+)
+
+var ErrNoGuessesLeft = errors.New("plus d'essai pour cet extrait, attendez le suivant")
+
+// ClipStage est le contenu d'un message bt_clip_stage : l'URL ne sert que
+// Seconds secondes de l'extrait, et l'étape dure Duration secondes.
+type ClipStage struct {
+	Round      int    `json:"round"`
+	Stage      int    `json:"stage"`
+	Stages     int    `json:"stages"`
+	Seconds    int    `json:"seconds"`
+	PreviewURL string `json:"preview_url"`
+	Duration   int    `json:"duration"`
+	Guesses    int    `json:"guesses"`
+	MaxPoints  int    `json:"max_points"`
+}
+
+// stageDuration laisse le temps d'écouter l'extrait puis de répondre.
+func stageDuration(stage int, settings Settings) int {
+	return ClipStages[stage-1] + settings.StageTime
+}
+
+func progressiveRoundTime(settings Settings) int {
+	total := 0
+	for stage := 1; stage <= len(ClipStages); stage++ {
+		total += stageDuration(stage, settings)
+	}
+	return total
+}
+
+// stageMaxPoints est le maximum d'une manche au barème strategy pour une
+// réponse donnée à l'étape stage.
+func stageMaxPoints(strategy ScoringStrategy, stage int) int {
+	return maxAnswerPoints(strategy) * (100 - stageDiscount[stage-1]) / 100
+}
+
+// roundTime est la durée qui sert au bonus de rapidité : toute la manche,
+// ou l'étape en cours en mode progressif.
+func (s *GameState) roundTime() int {
+	if s.Settings.ClipMode == ClipModeProgressive && s.Stage > 0 {
+		return stageDuration(s.Stage, s.Settings)
+	}
+	return s.Settings.RoundTime
+}
+
+// NextStage débloque l'étape suivante de la manche : un nouveau jeton limité
+// à la durée de l'étape remplace le précédent, et chaque joueur retrouve ses
+// essais. Il renvoie nil après la dernière étape.
+func (gm *GameManager) NextStage(roomID string) (*ClipStage, error) {
+	state := gm.GetGameState(roomID)
+	if state == nil {
+		return nil, rooms.ErrRoomNotFound
+	}
+
+	state.Mutex.Lock()
+	defer state.Mutex.Unlock()
+
+	if state.CurrentTrack == nil || state.IsRevealed || state.Stage >= len(ClipStages) {
+		return nil, nil
+	}
+
+	previewURL, err := gm.previews.LimitRound(roomID, ClipStages[state.Stage])
+	if err != nil {
+		return nil, err
+	}
+
+	state.Stage++
+	state.StageGuesses = make(map[int64]int)
+	state.PreviewURL = previewURL
+	state.TimeLeft = stageDuration(state.Stage, state.Settings)
+
+	return &ClipStage{
+		Round:      state.CurrentRound,
+		Stage:      state.Stage,
+		Stages:     len(ClipStages),
+		Seconds:    ClipStages[state.Stage-1],
+		PreviewURL: previewURL,
+		Duration:   state.TimeLeft,
+		Guesses:    state.Settings.StageGuesses,
+		MaxPoints:  stageMaxPoints(state.Scoring, state.Stage),
+	}, nil
+}
+
+// useGuess compte un essai du joueur pour l'étape en cours. L'appelant tient
+// state.Mutex.
+func (s *GameState) useGuess(userID int64) error {
+	if s.Stage == 0 || s.StageGuesses[userID] >= s.Settings.StageGuesses {
+		return ErrNoGuessesLeft
+	}
+	s.StageGuesses[userID]++
+	return nil
+}
+
+func (s *GameState) guessesLeft(userID int64) int {
+	return max(s.Settings.StageGuesses-s.StageGuesses[userID], 0)
+}
@@ -0,0 +1,151 @@
+package blindtest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"groupie-tracker/internal/media"
+	"groupie-tracker/internal/models"
+)
+
+// newProgressiveRound prépare une manche en écoute progressive dont
+// l'extrait est publié, comme après NextRound.
+func newProgressiveRound(t *testing.T) (*GameManager, *GameState) {
+	t.Helper()
+	previews := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(previews.Close)
+
+	gm, state := newTestRound(t, SettingsFromConfig(models.GameConfig{ClipMode: ClipModeProgressive}))
+	if _, err := gm.previews.StartRound(testRoomID, previews.URL+"/preview.mp3"); err != nil {
+		t.Fatalf("StartRound: %v", err)
+	}
+	t.Cleanup(func() { gm.previews.EndGame(testRoomID) })
+	return gm, state
+}
+
+func TestNextStage(t *testing.T) {
+	gm, state := newProgressiveRound(t)
+
+	tests := []struct {
+		stage     int
+		seconds   int
+		duration  int
+		maxPoints int
+	}{
+		{1, 1, 7, 180},
+		{2, 2, 8, 144},
+		{3, 4, 10, 108},
+		{4, 8, 14, 72},
+		{5, 16, 22, 45},
+	}
+
+	previous := ""
+	for _, tt := range tests {
+		state.StageGuesses[1] = DefaultStageGuesses
+
+		stage, err := gm.NextStage(testRoomID)
+		if err != nil || stage == nil {
+			t.Fatalf("étape %d: %v, %v", tt.stage, stage, err)
+		}
+		if stage.Stage != tt.stage || stage.Seconds != tt.seconds || stage.Duration != tt.duration || stage.MaxPoints != tt.maxPoints {
+			t.Errorf("étape %d = %+v", tt.stage, *stage)
+		}
+		if stage.Stages != len(ClipStages) || stage.Guesses != DefaultStageGuesses {
+			t.Errorf("étape %d: %d étapes, %d essais", tt.stage, stage.Stages, stage.Guesses)
+		}
+		if !strings.HasPrefix(stage.PreviewURL, media.PreviewPrefix) || stage.PreviewURL == previous {
+			t.Errorf("étape %d: jeton %q non renouvelé", tt.stage, stage.PreviewURL)
+		}
+		if state.TimeLeft != tt.duration || state.guessesLeft(1) != DefaultStageGuesses {
+			t.Errorf("étape %d: %ds, %d essais restants", tt.stage, state.TimeLeft, state.guessesLeft(1))
+		}
+		previous = stage.PreviewURL
+	}
+
+	if stage, err := gm.NextStage(testRoomID); stage != nil || err != nil {
+		t.Errorf("étape après la dernière: %+v, %v", stage, err)
+	}
+}
+
+func TestNextStageAfterReveal(t *testing.T) {
+	gm, state := newProgressiveRound(t)
+	state.IsRevealed = true
+
+	if stage, err := gm.NextStage(testRoomID); stage != nil || err != nil {
+		t.Errorf("étape après révélation: %+v, %v", stage, err)
+	}
+}
+
+func TestUseGuess(t *testing.T) {
+	tests := []struct {
+		name     string
+		stage    int
+		used     int
+		wantErr  error
+		wantLeft int
+	}{
+		{"avant la première étape", 0, 0, ErrNoGuessesLeft, 2},
+		{"premier essai", 1, 0, nil, 1},
+		{"dernier essai", 3, 1, nil, 0},
+		{"essais épuisés", 3, 2, ErrNoGuessesLeft, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, state := newTestRound(t, SettingsFromConfig(models.GameConfig{ClipMode: ClipModeProgressive}))
+			state.Stage = tt.stage
+			state.StageGuesses[1] = tt.used
+
+			if err := state.useGuess(1); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("useGuess = %v, attendu %v", err, tt.wantErr)
+			}
+			if left := state.guessesLeft(1); left != tt.wantLeft {
+				t.Errorf("%d essais restants, attendu %d", left, tt.wantLeft)
+			}
+		})
+	}
+}
+
+func TestProgressiveAnswers(t *testing.T) {
+	gm, state := newProgressiveRound(t)
+
+	if _, err := gm.SubmitAnswer(testRoomID, 1, "Queen"); !errors.Is(err, ErrNoGuessesLeft) {
+		t.Fatalf("réponse avant la première étape = %v, attendu %v", err, ErrNoGuessesLeft)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := gm.NextStage(testRoomID); err != nil {
+			t.Fatalf("NextStage: %v", err)
+		}
+	}
+	state.TimeLeft = 0
+
+	tests := []struct {
+		answer      string
+		wantErr     error
+		wantPoints  int
+		guessesLeft int
+	}{
+		{"", nil, 0, 2},       // une réponse vide ne coûte pas d'essai
+		{"Abba", nil, 0, 1},   // mauvaise réponse
+		{"Queen", nil, 24, 0}, // 40 points d'artiste, -40 % à l'étape 3
+		{"Bohemian Rhapsody", ErrNoGuessesLeft, 0, 0},
+	}
+
+	for _, tt := range tests {
+		result, err := gm.SubmitAnswer(testRoomID, 1, tt.answer)
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("SubmitAnswer(%q) = %v, attendu %v", tt.answer, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		if result.Points != tt.wantPoints || result.Stage != 3 || result.GuessesLeft != tt.guessesLeft {
+			t.Errorf("SubmitAnswer(%q): %d points, étape %d, %d essais, attendu %d points, %d essais",
+				tt.answer, result.Points, result.Stage, result.GuessesLeft, tt.wantPoints, tt.guessesLeft)
+		}
+	}
+}
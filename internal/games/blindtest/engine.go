@@ -0,0 +1,241 @@
+package blindtest
+
+import (
+	"log"
+	"net/url"
+	"strings"
+
+	"groupie-tracker/internal/games"
+	"groupie-tracker/internal/games/engines"
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/tracks"
+)
+
+func init() {
+	engines.Add(func(deps engines.Deps) games.Engine {
+		return NewHandler(NewGameManager(deps.Rooms, deps.Previews), deps.Rooms, deps.Hub)
+	})
+}
+
+func (h *Handler) GameType() models.GameType {
+	return models.GameTypeBlindTest
+}
+
+func (h *Handler) DisplayName() string {
+	return "Blind Test"
+}
+
+func (h *Handler) MessageTypes() []models.WSMessageType {
+	return []models.WSMessageType{
+		models.WSTypeBTAnswer,
+		models.WSTypeBTBuzz,
+	}
+}
+
+func (h *Handler) Template() string {
+	return "room_blindtest.html"
+}
+
+func (h *Handler) DefaultConfig() models.GameConfig {
+	return models.GameConfig{
+		Playlist:     "",
+		TimePerRound: models.BlindTestDefaultTime,
+		NbRounds:     DefaultRounds,
+		PauseTime:    DefaultPauseTime,
+		RevealTime:   DefaultRevealTime,
+	}
+}
+
+func (h *Handler) ParseConfig(form url.Values, config *models.GameConfig) {
+	if _, sent := form["genre"]; sent {
+		config.Playlist = form.Get("genre")
+		log.Printf("[BlindTest] Genre: %q", config.Playlist)
+	}
+	// Une autre playlist ou une autre source oublie le résumé de la playlist
+	// lue : ValidateConfig la relira.
+	if playlist := strings.TrimSpace(form.Get("playlist")); playlist != "" && playlist != config.PlaylistID {
+		config.PlaylistID = playlist
+		config.PlaylistTitle = ""
+		config.PlaylistTracks = 0
+	}
+	if source := form.Get("track_source"); source != "" && source != config.TrackSource {
+		config.TrackSource = source
+		config.PlaylistTitle = ""
+		config.PlaylistTracks = 0
+		log.Printf("[BlindTest] Source des pistes: %s", source)
+	}
+	parseSettings(form, config)
+}
+
+func (h *Handler) ValidateConfig(config *models.GameConfig) error {
+	if err := SettingsFromConfig(*config).Validate(); err != nil {
+		return err
+	}
+	provider, err := tracks.GetRegistry().Resolve(config.TrackSource)
+	if err != nil {
+		return games.ErrInvalidConfig
+	}
+	if config.PlaylistID != "" {
+		// Le format est vérifié localement ; l'API n'est appelée que pour une
+		// playlist pas encore lue. Start télécharge ses pistes au lancement.
+		playlistID, err := tracks.ParsePlaylist(provider, config.PlaylistID)
+		if err != nil {
+			return err
+		}
+		if playlistID == config.PlaylistID && config.PlaylistTitle != "" {
+			return nil
+		}
+		playlist, err := tracks.ValidatePlaylist(provider, playlistID)
+		if err != nil {
+			return err
+		}
+		config.PlaylistID = playlist.ID
+		config.PlaylistTitle = playlist.Title
+		config.PlaylistTracks = playlist.Playable
+		return nil
+	}
+	if !tracks.HasGenre(provider, config.Playlist) {
+		return tracks.ErrUnknownGenre
+	}
+	return nil
+}
+
+func (h *Handler) Start(room *models.Room, options map[string]interface{}) error {
+	room.Mutex.RLock()
+	genre := room.Config.Playlist
+	source := room.Config.TrackSource
+	playlistID := room.Config.PlaylistID
+	playable := room.Config.PlaylistTracks
+	settings := SettingsFromConfig(room.Config)
+	room.Mutex.RUnlock()
+
+	provider, err := tracks.GetRegistry().Resolve(source)
+	if err != nil {
+		return err
+	}
+	if playlistID != "" {
+		if provider, err = tracks.FromPlaylist(provider, playlistID); err != nil {
+			return err
+		}
+		genre = ""
+	}
+
+	if g, ok := options["genre"].(string); ok && g != "" && playlistID == "" {
+		genre = g
+	}
+
+	if err := h.StartGame(room.Code, provider, genre, settings); err != nil {
+		return err
+	}
+
+	rounds := settings.Rounds
+
+	// Une playlist plus courte que prévu raccourcit la partie.
+	if state := h.gameManager.GetGameState(room.ID); state != nil {
+		state.Mutex.RLock()
+		rounds = state.TotalRounds
+		state.Mutex.RUnlock()
+	}
+
+	log.Printf("[BlindTest] ✅ BlindTest démarré: source=%s, genre=%s, rounds=%d", provider.Name(), genre, rounds)
+
+	h.hub.Broadcast(room.Code, &models.WSMessage{
+		Type: models.WSTypeStartGame,
+		Payload: map[string]interface{}{
+			"game_type": models.GameTypeBlindTest,
+			"genre":     genre,
+			"rounds":    rounds,
+			"source":    provider.Name(),
+			"playlist":  playlistID,
+			"playable":  playable,
+			"settings":  settings,
+		},
+	})
+
+	return nil
+}
+
+func (h *Handler) Stop(roomID string) {
+	h.mutex.Lock()
+	if stopChan, exists := h.stopTimers[roomID]; exists {
+		select {
+		case <-stopChan:
+		default:
+			close(stopChan)
+		}
+		delete(h.stopTimers, roomID)
+	}
+	delete(h.roundLocks, roomID)
+	h.mutex.Unlock()
+
+	h.gameManager.StopGame(roomID)
+}
+
+// PlayerDisconnected rend la main aux autres joueurs quand celui qui a buzzé
+// perd sa connexion.
+func (h *Handler) PlayerDisconnected(roomID string, userID int64) {
+	lockout := h.gameManager.ReleaseBuzz(roomID, userID)
+	if lockout == nil {
+		return
+	}
+
+	room, err := h.roomManager.GetRoom(roomID)
+	if err != nil {
+		return
+	}
+	log.Printf("[BlindTest] 🔌 %s s'est déconnecté pendant son buzz", lockout.Pseudo)
+	h.resumeAfterBuzz(roomID, room.Code, lockout)
+}
+
+func (h *Handler) Snapshot(roomID string, userID int64) map[string]interface{} {
+	state := h.gameManager.GetGameState(roomID)
+	if state == nil {
+		return nil
+	}
+
+	state.Mutex.RLock()
+	snapshot := map[string]interface{}{
+		"game_type": models.GameTypeBlindTest,
+		"round":     state.CurrentRound,
+		"total":     state.TotalRounds,
+		"time_left": state.TimeLeft,
+		"duration":  state.roundTime(),
+	}
+	if state.CurrentTrack != nil {
+		snapshot["preview_url"] = state.PreviewURL
+		snapshot["has_found"] = state.hasFoundBoth(userID)
+		snapshot["has_title"] = state.FoundTitle[userID]
+		snapshot["has_artist"] = state.FoundArtist[userID]
+		snapshot["points"] = state.RoundPoints[userID]
+		snapshot["is_revealed"] = state.IsRevealed
+		if state.Settings.ClipMode == ClipModeProgressive {
+			snapshot["clip_mode"] = ClipModeProgressive
+			snapshot["stages"] = ClipStages
+			snapshot["stage"] = state.Stage
+			snapshot["guesses_left"] = state.guessesLeft(userID)
+			if state.Stage > 0 {
+				snapshot["stage_seconds"] = ClipStages[state.Stage-1]
+				snapshot["max_points"] = stageMaxPoints(state.Scoring, state.Stage)
+			}
+		} else if state.Settings.Hints {
+			snapshot["hints"] = state.Hints
+			snapshot["max_points"] = maxRoundPoints(state.Scoring, len(state.Hints))
+		}
+		if state.Choices != nil {
+			snapshot["choices"] = state.Choices
+			snapshot["choice_id"] = state.Chosen[userID]
+		}
+		if state.Settings.AnswerMode == AnswerModeBuzzer {
+			snapshot["buzz_time"] = state.Settings.BuzzTime
+			snapshot["buzz"] = state.currentBuzz()
+			snapshot["locked_out"] = state.LockedOut[userID]
+		}
+		if state.IsRevealed {
+			snapshot["reveal"] = state.revealInfo()
+		}
+	}
+	state.Mutex.RUnlock()
+
+	snapshot["scores"] = h.gameManager.GetScores(roomID)
+	return snapshot
+}
@@ -0,0 +1,78 @@
+package blindtest
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/spotify"
+	"groupie-tracker/internal/tracks"
+)
+
+// countingPlaylists est une source Deezer factice qui compte les lectures de
+// playlist.
+type countingPlaylists struct {
+	decoyProvider
+	lookups int
+}
+
+func (p *countingPlaylists) Name() string { return "test-playlists" }
+
+func (p *countingPlaylists) ParsePlaylistID(input string) (string, error) {
+	return spotify.ParsePlaylistID(input)
+}
+
+func (p *countingPlaylists) LookupPlaylist(playlistID string) (*tracks.Playlist, error) {
+	p.lookups++
+	return &tracks.Playlist{ID: playlistID, Title: "Playlist " + playlistID, Total: 20, Playable: 20}, nil
+}
+
+func (p *countingPlaylists) GetPlaylistTracks(string) ([]*models.SpotifyTrack, error) {
+	return p.tracks, nil
+}
+
+func TestValidateConfigReadsPlaylistOnce(t *testing.T) {
+	provider := &countingPlaylists{}
+	tracks.GetRegistry().Register(provider)
+
+	h := &Handler{}
+	config := h.DefaultConfig()
+	config.TrackSource = provider.Name()
+
+	// Chaque étape simule un update_config ou un start_game.
+	tests := []struct {
+		name     string
+		form     url.Values
+		wantErr  bool
+		lookups  int
+		playlist string
+	}{
+		{"playlist choisie", url.Values{"playlist": {"https://www.deezer.com/fr/playlist/1234"}}, false, 1, "1234"},
+		{"lancement", url.Values{}, false, 1, "1234"},
+		{"réglages renvoyés", url.Values{"playlist": {"1234"}, "bt_round_count": {strconv.Itoa(DefaultRounds)}}, false, 1, "1234"},
+		{"autre playlist", url.Values{"playlist": {"5678"}}, false, 2, "5678"},
+		{"lien invalide, sans appel", url.Values{"playlist": {"pas une playlist"}}, true, 2, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := config
+			h.ParseConfig(tt.form, &next)
+			err := h.ValidateConfig(&next)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateConfig = %v, erreur attendue: %v", err, tt.wantErr)
+			}
+			if provider.lookups != tt.lookups {
+				t.Errorf("%d lecture(s) de la playlist, attendu %d", provider.lookups, tt.lookups)
+			}
+			if err != nil {
+				return
+			}
+			if next.PlaylistID != tt.playlist || next.PlaylistTitle != "Playlist "+tt.playlist {
+				t.Errorf("playlist = %q (%q), attendu %q", next.PlaylistID, next.PlaylistTitle, tt.playlist)
+			}
+			config = next
+		})
+	}
+}
@@ -1,78 +1,109 @@
 package blindtest
 
 import (
+	"errors"
 	"log"
+	"maps"
 	"math/rand/v2"
 	"strings"
 	"sync"
 	"time"
 
+	"groupie-tracker/internal/media"
 	"groupie-tracker/internal/models"
 	"groupie-tracker/internal/rooms"
-	"groupie-tracker/internal/spotify"
+	"groupie-tracker/internal/tracks"
 )
 
+// ErrRoundOver refuse une réponse arrivée après la révélation ou hors manche.
+var ErrRoundOver = errors.New("aucune manche en cours")
+
 type GameState struct {
-	RoomID       string                 `json:"room_id"`
-	CurrentRound int                    `json:"current_round"`
-	TotalRounds  int                    `json:"total_rounds"`
-	CurrentTrack *models.SpotifyTrack   `json:"current_track,omitempty"`
-	Tracks       []*models.SpotifyTrack `json:"-"`
-	TimeLeft     int                    `json:"time_left"`
-	Answers      map[int64]string       `json:"answers"`
-	HasAnswered  map[int64]bool         `json:"has_answered"`
-	IsRevealed   bool                   `json:"is_revealed"`
-	Timer        *time.Timer            `json:"-"`
-	Mutex        sync.RWMutex           `json:"-"`
+	RoomID        string                   `json:"room_id"`
+	CurrentRound  int                      `json:"current_round"`
+	TotalRounds   int                      `json:"total_rounds"`
+	Settings      Settings                 `json:"settings"`
+	Scoring       ScoringStrategy          `json:"-"`
+	CurrentTrack  *models.SpotifyTrack     `json:"-"`
+	PreviewURL    string                   `json:"preview_url"`
+	Tracks        []*models.SpotifyTrack   `json:"-"`
+	TimeLeft      int                      `json:"time_left"`
+	Answers       map[int64]string         `json:"answers"`
+	HasAnswered   map[int64]bool           `json:"has_answered"`
+	FoundTitle    map[int64]bool           `json:"found_title"`
+	FoundArtist   map[int64]bool           `json:"found_artist"`
+	RoundPoints   map[int64]int            `json:"round_points"`
+	RoundScores   map[int64][]int          `json:"round_scores"`
+	Finishers     int                      `json:"finishers"`
+	WrongGuesses  map[int64]int            `json:"wrong_guesses"`
+	Streaks       map[int64]int            `json:"streaks"`
+	Breakdown     map[int64]map[string]int `json:"breakdown"`
+	DecoyPool     []*models.SpotifyTrack   `json:"-"`
+	Choices       []Choice                 `json:"-"`
+	CorrectChoice int                      `json:"-"`
+	Chosen        map[int64]int            `json:"-"`
+	Hints         []*Hint                  `json:"-"`
+	Stage         int                      `json:"stage"`
+	StageGuesses  map[int64]int            `json:"-"`
+	Buzz          *Buzz                    `json:"-"`
+	LockedOut     map[int64]bool           `json:"-"`
+	BuzzSeq       int                      `json:"-"`
+	IsRevealed    bool                     `json:"is_revealed"`
+	Timer         *time.Timer              `json:"-"`
+	Mutex         sync.RWMutex             `json:"-"`
 }
 
 type GameManager struct {
 	games       map[string]*GameState
 	mutex       sync.RWMutex
 	roomManager *rooms.Manager
+	previews    *media.PreviewProxy
 }
 
-var (
-	gameManagerInstance *GameManager
-	gameManagerOnce     sync.Once
-)
-
-func GetGameManager() *GameManager {
-	gameManagerOnce.Do(func() {
-		gameManagerInstance = &GameManager{
-			games:       make(map[string]*GameState),
-			roomManager: rooms.GetManager(),
-		}
-	})
-	return gameManagerInstance
-}
-
-func (gm *GameManager) StartGame(roomID string, genre string, rounds int) (*GameState, error) {
-	client := spotify.GetClient()
-	if client == nil {
-		log.Println("[BlindTest] Client Spotify non initialisé")
-		return nil, spotify.ErrNoToken
+func NewGameManager(roomManager *rooms.Manager, previews *media.PreviewProxy) *GameManager {
+	return &GameManager{
+		games:       make(map[string]*GameState),
+		roomManager: roomManager,
+		previews:    previews,
 	}
+}
 
-	tracks, err := client.GetRandomTracksForBlindTest(genre, rounds)
+func (gm *GameManager) StartGame(roomID string, provider tracks.TrackProvider, genre string, settings Settings) (*GameState, error) {
+	rounds := settings.Rounds
+	playlist, err := provider.GetTracks(genre, rounds)
 	if err != nil {
-		log.Printf("[BlindTest] Erreur récupération pistes: %v", err)
+		log.Printf("[BlindTest] Erreur récupération pistes (%s): %v", provider.Name(), err)
 		return nil, err
 	}
 
-	if len(tracks) < rounds {
-		rounds = len(tracks)
+	if len(playlist) < rounds {
+		rounds = len(playlist)
 	}
 
 	state := &GameState{
 		RoomID:       roomID,
 		CurrentRound: 0,
 		TotalRounds:  rounds,
-		Tracks:       tracks,
+		Settings:     settings,
+		Scoring:      GetScoringStrategy(settings.Scoring),
+		Tracks:       playlist,
 		Answers:      make(map[int64]string),
 		HasAnswered:  make(map[int64]bool),
+		FoundTitle:   make(map[int64]bool),
+		FoundArtist:  make(map[int64]bool),
+		RoundPoints:  make(map[int64]int),
+		RoundScores:  make(map[int64][]int),
+		WrongGuesses: make(map[int64]int),
+		Streaks:      make(map[int64]int),
+		Breakdown:    make(map[int64]map[string]int),
 		IsRevealed:   false,
 	}
+	if state.Scoring == nil {
+		state.Scoring = GetScoringStrategy(DefaultScoring)
+	}
+	if settings.AnswerMode == AnswerModeChoices {
+		state.DecoyPool = loadDecoyPool(provider, genre, playlist)
+	}
 
 	gm.mutex.Lock()
 	gm.games[roomID] = state
@@ -91,6 +122,18 @@ func (gm *GameManager) GetGameState(roomID string) *GameState {
 	return gm.games[roomID]
 }
 
+// GetSettings renvoie les réglages de la partie en cours, ou ceux par défaut
+// si la partie vient de se terminer.
+func (gm *GameManager) GetSettings(roomID string) Settings {
+	state := gm.GetGameState(roomID)
+	if state == nil {
+		return SettingsFromConfig(models.GameConfig{})
+	}
+	state.Mutex.RLock()
+	defer state.Mutex.RUnlock()
+	return state.Settings
+}
+
 func (gm *GameManager) NextRound(roomID string) (*RoundInfo, error) {
 	state := gm.GetGameState(roomID)
 	if state == nil {
@@ -104,30 +147,82 @@ func (gm *GameManager) NextRound(roomID string) (*RoundInfo, error) {
 		return nil, nil
 	}
 
+	// Les clients ne voient qu'un jeton de manche : l'URL de l'extrait
+	// contient l'identifiant de la piste chez le fournisseur.
+	track := state.Tracks[state.CurrentRound]
+	previewURL, err := gm.previews.StartRound(roomID, track.PreviewURL)
+	if err != nil {
+		return nil, err
+	}
+
 	state.CurrentRound++
-	state.CurrentTrack = state.Tracks[state.CurrentRound-1]
-	state.TimeLeft = models.BlindTestDefaultTime
+	state.CurrentTrack = track
+	state.PreviewURL = previewURL
+	state.TimeLeft = state.Settings.RoundTime
 	state.Answers = make(map[int64]string)
 	state.HasAnswered = make(map[int64]bool)
+	state.FoundTitle = make(map[int64]bool)
+	state.FoundArtist = make(map[int64]bool)
+	state.RoundPoints = make(map[int64]int)
+	state.Finishers = 0
+	state.WrongGuesses = make(map[int64]int)
+	state.Chosen = make(map[int64]int)
+	state.Hints = nil
+	state.Stage = 0
+	state.StageGuesses = make(map[int64]int)
+	state.Buzz = nil
+	state.LockedOut = make(map[int64]bool)
+	state.Choices = nil
+	state.CorrectChoice = 0
+	if state.Settings.AnswerMode == AnswerModeChoices {
+		state.Choices, state.CorrectChoice = buildChoices(track, state.DecoyPool)
+	}
 	state.IsRevealed = false
+	if state.Settings.ClipMode == ClipModeProgressive {
+		// L'extrait complet n'est jamais annoncé : NextStage publie un jeton
+		// limité à chaque étape.
+		state.PreviewURL = ""
+		state.TimeLeft = progressiveRoundTime(state.Settings)
+	}
 
 	log.Printf("[BlindTest] Manche %d/%d - Piste: %s", state.CurrentRound, state.TotalRounds, state.CurrentTrack.Name)
 
-	return &RoundInfo{
+	info := &RoundInfo{
 		Round:      state.CurrentRound,
 		Total:      state.TotalRounds,
-		PreviewURL: state.CurrentTrack.PreviewURL,
+		PreviewURL: state.PreviewURL,
 		Duration:   state.TimeLeft,
-	}, nil
+		Choices:    state.Choices,
+	}
+	if state.Settings.ClipMode == ClipModeProgressive {
+		info.Stages = ClipStages
+	} else if state.Settings.Hints {
+		info.MaxPoints = maxRoundPoints(state.Scoring, 0)
+	}
+	if state.Settings.AnswerMode == AnswerModeBuzzer {
+		info.BuzzTime = state.Settings.BuzzTime
+	}
+	return info, nil
 }
 
+// RoundInfo.Choices n'est rempli qu'en mode choix multiple, MaxPoints que si
+// les indices sont activés, Stages qu'en mode progressif (PreviewURL est
+// alors vide jusqu'à la première étape), BuzzTime qu'en mode buzzer.
 type RoundInfo struct {
-	Round      int    `json:"round"`
-	Total      int    `json:"total"`
-	PreviewURL string `json:"preview_url"`
-	Duration   int    `json:"duration"`
+	Round      int      `json:"round"`
+	Total      int      `json:"total"`
+	PreviewURL string   `json:"preview_url"`
+	Duration   int      `json:"duration"`
+	Choices    []Choice `json:"choices,omitempty"`
+	MaxPoints  int      `json:"max_points,omitempty"`
+	Stages     []int    `json:"stages,omitempty"`
+	BuzzTime   int      `json:"buzz_time,omitempty"`
 }
 
+// SubmitAnswer compare la réponse au titre et à l'artiste séparément. Chaque
+// partie trouvée rapporte ses points une seule fois ; le joueur peut
+// continuer à proposer tant qu'il n'a pas trouvé les deux. Les points sont
+// calculés par le barème de la salle.
 func (gm *GameManager) SubmitAnswer(roomID string, userID int64, answer string) (*AnswerResult, error) {
 	state := gm.GetGameState(roomID)
 	if state == nil {
@@ -137,36 +232,250 @@ func (gm *GameManager) SubmitAnswer(roomID string, userID int64, answer string)
 	state.Mutex.Lock()
 	defer state.Mutex.Unlock()
 
-	if state.HasAnswered[userID] {
-		prevAnswer := state.Answers[userID]
-		if checkAnswer(prevAnswer, state.CurrentTrack.Name, state.CurrentTrack.Artist) {
-			return &AnswerResult{AlreadyAnswered: true}, nil
+	if state.Settings.AnswerMode == AnswerModeChoices {
+		return nil, ErrChoiceRequired
+	}
+	if state.CurrentTrack == nil || state.IsRevealed {
+		return nil, ErrRoundOver
+	}
+
+	if state.hasFoundBoth(userID) {
+		return &AnswerResult{AlreadyAnswered: true, HasTitle: true, HasArtist: true, Locked: true}, nil
+	}
+
+	// En mode buzzer, seul le joueur qui a la main peut répondre.
+	buzzer := state.Settings.AnswerMode == AnswerModeBuzzer
+	if buzzer && (state.Buzz == nil || state.Buzz.UserID != userID) {
+		return nil, ErrBuzzRequired
+	}
+
+	titleMatch := matchesTitle(answer, state.CurrentTrack.Name)
+	artistMatch := matchesArtist(answer, state.CurrentTrack.Artist)
+	// Une réponse vide n'est pas une erreur, ni une réponse qui répète une
+	// partie déjà trouvée.
+	wrong := !titleMatch && !artistMatch && strings.TrimSpace(answer) != ""
+
+	// En mode progressif, chaque proposition non vide consomme un essai de
+	// l'étape en cours.
+	progressive := state.Settings.ClipMode == ClipModeProgressive
+	if progressive && strings.TrimSpace(answer) != "" {
+		if err := state.useGuess(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	result := state.applyAnswer(userID, answer, titleMatch, artistMatch, wrong)
+	result.Locked = result.Complete()
+	if progressive {
+		result.Stage = state.Stage
+		result.GuessesLeft = state.guessesLeft(userID)
+	}
+	// Une réponse rend la main ; si elle ne trouve rien, le joueur est
+	// éliminé pour la manche.
+	if buzzer {
+		if result.IsCorrect {
+			state.Buzz = nil
+		} else {
+			state.lockOut(LockoutWrong)
+			result.LockedOut = true
+			result.Locked = true
+		}
+	}
+	gm.addPoints(roomID, userID, result)
+
+	log.Printf("[BlindTest] Réponse de %d: %s (titre: %v, artiste: %v, points: %d)", userID, answer, result.HasTitle, result.HasArtist, result.Points)
+
+	return result, nil
+}
+
+// SubmitChoice enregistre le choix d'un joueur en mode choix multiple. Un
+// seul choix par manche : la bonne proposition vaut titre et artiste, une
+// mauvaise est notée comme une réponse fausse.
+func (gm *GameManager) SubmitChoice(roomID string, userID int64, choiceID int) (*AnswerResult, error) {
+	state := gm.GetGameState(roomID)
+	if state == nil {
+		return nil, rooms.ErrRoomNotFound
+	}
+
+	state.Mutex.Lock()
+	defer state.Mutex.Unlock()
+
+	if state.Settings.AnswerMode != AnswerModeChoices {
+		return nil, ErrTextRequired
+	}
+	if state.CurrentTrack == nil || state.IsRevealed {
+		return nil, ErrRoundOver
+	}
+
+	if previous, chosen := state.Chosen[userID]; chosen {
+		return &AnswerResult{
+			AlreadyAnswered: true,
+			HasTitle:        state.FoundTitle[userID],
+			HasArtist:       state.FoundArtist[userID],
+			ChoiceID:        previous,
+			Locked:          true,
+		}, nil
+	}
+
+	var picked *Choice
+	for i := range state.Choices {
+		if state.Choices[i].ID == choiceID {
+			picked = &state.Choices[i]
+			break
 		}
 	}
+	if picked == nil {
+		return nil, ErrInvalidChoice
+	}
 
-	state.Answers[userID] = answer
-	state.HasAnswered[userID] = true
+	state.Chosen[userID] = choiceID
+	correct := choiceID == state.CorrectChoice
 
-	isCorrect := checkAnswer(answer, state.CurrentTrack.Name, state.CurrentTrack.Artist)
+	result := state.applyAnswer(userID, picked.Title+" - "+picked.Artist, correct, correct, !correct)
+	result.ChoiceID = choiceID
+	result.Locked = true
+	gm.addPoints(roomID, userID, result)
 
-	points := 0
-	if isCorrect {
-		points = calculatePoints(state.TimeLeft, models.BlindTestDefaultTime)
-		gm.roomManager.AddPlayerScore(roomID, userID, points)
+	log.Printf("[BlindTest] Choix de %d: %d (correct: %v, points: %d)", userID, choiceID, correct, result.Points)
+
+	return result, nil
+}
+
+// applyAnswer met à jour la manche et note la réponse avec le barème de la
+// salle. L'appelant tient state.Mutex.
+func (s *GameState) applyAnswer(userID int64, answer string, titleMatch, artistMatch, wrong bool) *AnswerResult {
+	s.Answers[userID] = answer
+	s.HasAnswered[userID] = true
+
+	result := &AnswerResult{}
+	if titleMatch && !s.FoundTitle[userID] {
+		s.FoundTitle[userID] = true
+		result.FoundTitle = true
+	}
+	if artistMatch && !s.FoundArtist[userID] {
+		s.FoundArtist[userID] = true
+		result.FoundArtist = true
+	}
+	result.IsCorrect = result.FoundTitle || result.FoundArtist
+	result.HasTitle = s.FoundTitle[userID]
+	result.HasArtist = s.FoundArtist[userID]
+
+	ctx := ScoringContext{
+		FoundTitle:  result.FoundTitle,
+		FoundArtist: result.FoundArtist,
+		Complete:    result.Complete(),
+		TimeLeft:    s.TimeLeft,
+		RoundTime:   s.roundTime(),
+		Streak:      s.Streaks[userID],
+		Score:       s.gameScore(userID),
+	}
+	switch {
+	case result.IsCorrect:
+		if ctx.Complete {
+			s.Finishers++
+			ctx.FinishRank = s.Finishers
+		}
+		result.Breakdown = s.Scoring.ScoreAnswer(ctx)
+		if s.Settings.ClipMode == ClipModeProgressive && s.Stage > 0 {
+			result.Breakdown = discount(result.Breakdown, ScoreStage, stageDiscount[s.Stage-1])
+		} else {
+			result.Breakdown = discount(result.Breakdown, ScoreHints, hintPercent(len(s.Hints)))
+		}
+	case wrong:
+		s.WrongGuesses[userID]++
+		ctx.WrongGuesses = s.WrongGuesses[userID]
+		result.Breakdown = s.Scoring.ScoreMiss(ctx)
 	}
 
-	log.Printf("[BlindTest] Réponse de %d: %s (correct: %v, points: %d)", userID, answer, isCorrect, points)
+	result.Points = sumPoints(result.Breakdown)
+	for _, line := range result.Breakdown {
+		if isBonus(line.Kind) {
+			result.Bonus += line.Points
+		}
+	}
 
-	return &AnswerResult{
-		IsCorrect: isCorrect,
-		Points:    points,
-	}, nil
+	if result.Points != 0 {
+		s.RoundPoints[userID] += result.Points
+		s.addBreakdown(userID, result.Breakdown)
+	}
+	return result
 }
 
+func (gm *GameManager) addPoints(roomID string, userID int64, result *AnswerResult) {
+	if result.Points != 0 {
+		gm.roomManager.AddPlayerScore(roomID, userID, result.Points)
+	}
+}
+
+// AnswerResult décrit l'effet d'une réponse. FoundTitle / FoundArtist
+// indiquent ce que cette réponse vient de trouver, HasTitle / HasArtist ce
+// que le joueur a trouvé depuis le début de la manche.
+// Breakdown détaille les points de cette réponse, Bonus en est la part
+// hors titre, artiste et rapidité. Locked indique que le joueur ne peut plus
+// répondre dans cette manche ; ChoiceID rappelle son choix en mode choix
+// multiple. Stage et GuessesLeft ne sont remplis qu'en mode progressif ;
+// LockedOut signale une élimination au buzzer.
 type AnswerResult struct {
-	IsCorrect       bool `json:"is_correct"`
-	Points          int  `json:"points"`
-	AlreadyAnswered bool `json:"already_answered"`
+	IsCorrect       bool        `json:"is_correct"`
+	Points          int         `json:"points"`
+	Bonus           int         `json:"bonus"`
+	Breakdown       []ScoreLine `json:"breakdown"`
+	FoundTitle      bool        `json:"found_title"`
+	FoundArtist     bool        `json:"found_artist"`
+	HasTitle        bool        `json:"has_title"`
+	HasArtist       bool        `json:"has_artist"`
+	AlreadyAnswered bool        `json:"already_answered"`
+	ChoiceID        int         `json:"choice_id,omitempty"`
+	Locked          bool        `json:"locked"`
+	Stage           int         `json:"stage,omitempty"`
+	GuessesLeft     int         `json:"guesses_left"`
+	LockedOut       bool        `json:"locked_out,omitempty"`
+}
+
+// Complete indique que le joueur a trouvé le titre et l'artiste.
+func (r *AnswerResult) Complete() bool {
+	return r.HasTitle && r.HasArtist
+}
+
+func (s *GameState) hasFoundBoth(userID int64) bool {
+	return s.FoundTitle[userID] && s.FoundArtist[userID]
+}
+
+// isDone indique que le joueur n'a plus rien à proposer dans la manche :
+// tout trouvé, ou choix fait en mode choix multiple.
+func (s *GameState) isDone(userID int64) bool {
+	if s.LockedOut[userID] {
+		return true
+	}
+	if s.Settings.AnswerMode == AnswerModeChoices {
+		_, chosen := s.Chosen[userID]
+		return chosen
+	}
+	return s.hasFoundBoth(userID)
+}
+
+// gameScore additionne les manches terminées et la manche en cours.
+func (s *GameState) gameScore(userID int64) int {
+	score := s.RoundPoints[userID]
+	for _, points := range s.RoundScores[userID] {
+		score += points
+	}
+	return score
+}
+
+func (s *GameState) addBreakdown(userID int64, lines []ScoreLine) {
+	totals := s.Breakdown[userID]
+	if totals == nil {
+		totals = make(map[string]int)
+		s.Breakdown[userID] = totals
+	}
+	for _, line := range lines {
+		totals[line.Kind] += line.Points
+	}
+}
+
+func isBonus(kind string) bool {
+	return kind == ScoreBoth || kind == ScoreFirstFinder || kind == ScoreStreak
 }
 
 func (gm *GameManager) RevealAnswer(roomID string) *RevealInfo {
@@ -178,21 +487,50 @@ func (gm *GameManager) RevealAnswer(roomID string) *RevealInfo {
 	state.Mutex.Lock()
 	defer state.Mutex.Unlock()
 
+	if state.IsRevealed || state.CurrentTrack == nil {
+		return nil
+	}
 	state.IsRevealed = true
+	gm.recordRoundScores(state)
+
+	return state.revealInfo()
+}
 
+func (s *GameState) revealInfo() *RevealInfo {
 	return &RevealInfo{
-		TrackName:  state.CurrentTrack.Name,
-		ArtistName: state.CurrentTrack.Artist,
-		AlbumName:  state.CurrentTrack.Album,
-		ImageURL:   state.CurrentTrack.ImageURL,
+		TrackName:     s.CurrentTrack.Name,
+		ArtistName:    s.CurrentTrack.Artist,
+		AlbumName:     s.CurrentTrack.Album,
+		ImageURL:      s.CurrentTrack.ImageURL,
+		CorrectChoice: s.CorrectChoice,
+	}
+}
+
+func (gm *GameManager) recordRoundScores(state *GameState) {
+	room, err := gm.roomManager.GetRoom(state.RoomID)
+	if err != nil {
+		return
+	}
+
+	room.Mutex.RLock()
+	defer room.Mutex.RUnlock()
+
+	for userID := range room.Players {
+		state.RoundScores[userID] = append(state.RoundScores[userID], state.RoundPoints[userID])
+		if state.hasFoundBoth(userID) {
+			state.Streaks[userID]++
+		} else {
+			state.Streaks[userID] = 0
+		}
 	}
 }
 
 type RevealInfo struct {
-	TrackName  string `json:"track_name"`
-	ArtistName string `json:"artist_name"`
-	AlbumName  string `json:"album_name"`
-	ImageURL   string `json:"image_url"`
+	TrackName     string `json:"track_name"`
+	ArtistName    string `json:"artist_name"`
+	AlbumName     string `json:"album_name"`
+	ImageURL      string `json:"image_url"`
+	CorrectChoice int    `json:"correct_choice,omitempty"`
 }
 
 func (gm *GameManager) GetScores(roomID string) []PlayerScore {
@@ -201,15 +539,27 @@ func (gm *GameManager) GetScores(roomID string) []PlayerScore {
 		return nil
 	}
 
+	// Le détail est copié avant de verrouiller la salle : recordRoundScores
+	// prend les deux verrous dans l'ordre inverse.
+	breakdowns := make(map[int64]map[string]int)
+	if state := gm.GetGameState(roomID); state != nil {
+		state.Mutex.RLock()
+		for userID, totals := range state.Breakdown {
+			breakdowns[userID] = maps.Clone(totals)
+		}
+		state.Mutex.RUnlock()
+	}
+
 	room.Mutex.RLock()
 	defer room.Mutex.RUnlock()
 
 	scores := make([]PlayerScore, 0, len(room.Players))
 	for _, player := range room.Players {
 		scores = append(scores, PlayerScore{
-			UserID: player.UserID,
-			Pseudo: player.Pseudo,
-			Score:  player.Score,
+			UserID:    player.UserID,
+			Pseudo:    player.Pseudo,
+			Score:     player.Score,
+			Breakdown: breakdowns[player.UserID],
 		})
 	}
 
@@ -224,10 +574,13 @@ func (gm *GameManager) GetScores(roomID string) []PlayerScore {
 	return scores
 }
 
+// PlayerScore.Breakdown cumule sur la partie les points par catégorie
+// (ScoreTitle, ScoreStreak...).
 type PlayerScore struct {
-	UserID int64  `json:"user_id"`
-	Pseudo string `json:"pseudo"`
-	Score  int    `json:"score"`
+	UserID    int64          `json:"user_id"`
+	Pseudo    string         `json:"pseudo"`
+	Score     int            `json:"score"`
+	Breakdown map[string]int `json:"breakdown,omitempty"`
 }
 
 func (gm *GameManager) EndGame(roomID string) *GameResult {
@@ -243,6 +596,9 @@ func (gm *GameManager) EndGame(roomID string) *GameResult {
 	gm.mutex.Lock()
 	delete(gm.games, roomID)
 	gm.mutex.Unlock()
+	gm.previews.EndGame(roomID)
+
+	gm.saveGameScores(roomID, state)
 
 	log.Printf("[BlindTest] Partie terminée dans la salle %s", roomID)
 
@@ -257,108 +613,40 @@ func (gm *GameManager) EndGame(roomID string) *GameResult {
 	}
 }
 
-type GameResult struct {
-	Scores []PlayerScore `json:"scores"`
-	Winner string        `json:"winner"`
-}
-
-func (gm *GameManager) IsGameOver(roomID string) bool {
-	state := gm.GetGameState(roomID)
-	if state == nil {
-		return true
-	}
+func (gm *GameManager) saveGameScores(roomID string, state *GameState) {
 	state.Mutex.RLock()
 	defer state.Mutex.RUnlock()
-	return state.CurrentRound >= state.TotalRounds
-}
-
-func checkAnswer(answer, trackName, artistName string) bool {
-	answer = normalizeString(answer)
-	trackName = normalizeString(trackName)
-	artistName = normalizeString(artistName)
-
-	if strings.Contains(answer, trackName) || strings.Contains(trackName, answer) {
-		return true
-	}
-	if strings.Contains(answer, artistName) || strings.Contains(artistName, answer) {
-		return true
-	}
 
-	if similarity(answer, trackName) > 0.7 || similarity(answer, artistName) > 0.7 {
-		return true
+	if err := gm.roomManager.RecordGameScores(roomID, state.RoundScores); err != nil {
+		log.Printf("[BlindTest] Erreur sauvegarde scores: %v", err)
 	}
-
-	return false
 }
 
-func normalizeString(s string) string {
-	s = strings.ToLower(s)
-	s = strings.TrimSpace(s)
-	replacer := strings.NewReplacer(
-		"é", "e", "è", "e", "ê", "e", "ë", "e",
-		"à", "a", "â", "a", "ä", "a",
-		"ô", "o", "ö", "o",
-		"ù", "u", "û", "u", "ü", "u",
-		"î", "i", "ï", "i",
-		"ç", "c",
-	)
-	return replacer.Replace(s)
+type GameResult struct {
+	Scores []PlayerScore `json:"scores"`
+	Winner string        `json:"winner"`
 }
 
-func similarity(s1, s2 string) float64 {
-	if s1 == s2 {
-		return 1.0
-	}
-	if len(s1) == 0 || len(s2) == 0 {
-		return 0.0
-	}
+func (gm *GameManager) StopGame(roomID string) {
+	gm.mutex.Lock()
+	_, exists := gm.games[roomID]
+	delete(gm.games, roomID)
+	gm.mutex.Unlock()
+	gm.previews.EndGame(roomID)
 
-	maxLen := len(s1)
-	if len(s2) > maxLen {
-		maxLen = len(s2)
+	if exists {
+		log.Printf("[BlindTest] Partie interrompue dans la salle %s", roomID)
 	}
-
-	distance := levenshteinDistance(s1, s2)
-	return 1.0 - float64(distance)/float64(maxLen)
 }
 
-func levenshteinDistance(s1, s2 string) int {
-	if len(s1) == 0 {
-		return len(s2)
-	}
-	if len(s2) == 0 {
-		return len(s1)
-	}
-
-	matrix := make([][]int, len(s1)+1)
-	for i := range matrix {
-		matrix[i] = make([]int, len(s2)+1)
-		matrix[i][0] = i
-	}
-	for j := range matrix[0] {
-		matrix[0][j] = j
-	}
-
-	for i := 1; i <= len(s1); i++ {
-		for j := 1; j <= len(s2); j++ {
-			cost := 1
-			if s1[i-1] == s2[j-1] {
-				cost = 0
-			}
-			matrix[i][j] = min(
-				matrix[i-1][j]+1,
-				min(matrix[i][j-1]+1, matrix[i-1][j-1]+cost),
-			)
-		}
+func (gm *GameManager) IsGameOver(roomID string) bool {
+	state := gm.GetGameState(roomID)
+	if state == nil {
+		return true
 	}
-
-	return matrix[len(s1)][len(s2)]
-}
-
-func calculatePoints(timeLeft, totalTime int) int {
-	basePoints := 100
-	timeBonus := int(float64(timeLeft) / float64(totalTime) * 50)
-	return basePoints + timeBonus
+	state.Mutex.RLock()
+	defer state.Mutex.RUnlock()
+	return state.CurrentRound >= state.TotalRounds
 }
 
 func ShuffleTracks(tracks []*models.SpotifyTrack) {
@@ -0,0 +1,141 @@
+package blindtest
+
+import (
+	"errors"
+	"testing"
+
+	"groupie-tracker/internal/media"
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/rooms"
+)
+
+const testRoomID = "salle-test"
+
+func testTrack() *models.SpotifyTrack {
+	return &models.SpotifyTrack{ID: "1", Name: "Bohemian Rhapsody", Artist: "Queen", Album: "A Night at the Opera"}
+}
+
+// newTestRound installe une partie à la première manche, jouée sur
+// testTrack, comme le ferait NextRound mais sans publier d'extrait.
+func newTestRound(t *testing.T, settings Settings) (*GameManager, *GameState) {
+	t.Helper()
+	store := rooms.NewMemoryStore()
+	gm := &GameManager{
+		games:       make(map[string]*GameState),
+		roomManager: rooms.NewManager(store, store),
+		previews:    media.GetPreviewProxy(),
+	}
+
+	state := &GameState{
+		RoomID:       testRoomID,
+		CurrentRound: 1,
+		TotalRounds:  settings.Rounds,
+		Settings:     settings,
+		Scoring:      GetScoringStrategy(settings.Scoring),
+		CurrentTrack: testTrack(),
+		TimeLeft:     settings.RoundTime,
+		Answers:      make(map[int64]string),
+		HasAnswered:  make(map[int64]bool),
+		FoundTitle:   make(map[int64]bool),
+		FoundArtist:  make(map[int64]bool),
+		RoundPoints:  make(map[int64]int),
+		RoundScores:  make(map[int64][]int),
+		WrongGuesses: make(map[int64]int),
+		Streaks:      make(map[int64]int),
+		Breakdown:    make(map[int64]map[string]int),
+		Chosen:       make(map[int64]int),
+		StageGuesses: make(map[int64]int),
+		LockedOut:    make(map[int64]bool),
+	}
+	if settings.AnswerMode == AnswerModeChoices {
+		state.Choices, state.CorrectChoice = buildChoices(state.CurrentTrack, nil)
+	}
+	gm.games[testRoomID] = state
+	return gm, state
+}
+
+func testSettings(answerMode string) Settings {
+	return SettingsFromConfig(models.GameConfig{AnswerMode: answerMode})
+}
+
+func TestSubmitAnswer(t *testing.T) {
+	gm, state := newTestRound(t, testSettings(AnswerModeText))
+
+	tests := []struct {
+		answer      string
+		foundTitle  bool
+		foundArtist bool
+		complete    bool
+		already     bool
+	}{
+		{"Abba", false, false, false, false},
+		{"bohemian rhapsody", true, false, false, false},
+		{"Bohemian Rhapsody", false, false, false, false},
+		{"Queen", false, true, true, false},
+		{"Queen", false, false, true, true},
+	}
+
+	total := 0
+	for _, tt := range tests {
+		result, err := gm.SubmitAnswer(testRoomID, 1, tt.answer)
+		if err != nil {
+			t.Fatalf("SubmitAnswer(%q): %v", tt.answer, err)
+		}
+		if result.FoundTitle != tt.foundTitle || result.FoundArtist != tt.foundArtist || result.Complete() != tt.complete || result.AlreadyAnswered != tt.already {
+			t.Errorf("SubmitAnswer(%q) = %+v", tt.answer, *result)
+		}
+		if result.IsCorrect != (tt.foundTitle || tt.foundArtist) || (result.Points > 0) != result.IsCorrect {
+			t.Errorf("SubmitAnswer(%q): correct %v, %d points", tt.answer, result.IsCorrect, result.Points)
+		}
+		if (result.Bonus > 0) != (tt.complete && result.IsCorrect) {
+			t.Errorf("SubmitAnswer(%q): bonus %d", tt.answer, result.Bonus)
+		}
+		total += result.Points
+	}
+
+	if state.RoundPoints[1] != total || state.RoundPoints[2] != 0 {
+		t.Errorf("points de la manche = %v, attendu %d pour le joueur 1", state.RoundPoints, total)
+	}
+}
+
+func TestAnswersOutsideRound(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		revealed  bool
+		noTrack   bool
+		wantError error
+	}{
+		{"texte en cours", AnswerModeText, false, false, nil},
+		{"texte après révélation", AnswerModeText, true, false, ErrRoundOver},
+		{"texte sans manche", AnswerModeText, false, true, ErrRoundOver},
+		{"choix en cours", AnswerModeChoices, false, false, nil},
+		{"choix après révélation", AnswerModeChoices, true, false, ErrRoundOver},
+		{"choix sans manche", AnswerModeChoices, false, true, ErrRoundOver},
+		{"buzzer après révélation", AnswerModeBuzzer, true, false, ErrRoundOver},
+		{"buzzer sans manche", AnswerModeBuzzer, false, true, ErrRoundOver},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm, state := newTestRound(t, testSettings(tt.mode))
+			state.IsRevealed = tt.revealed
+			if tt.noTrack {
+				state.CurrentTrack = nil
+			}
+
+			var err error
+			if tt.mode == AnswerModeChoices {
+				_, err = gm.SubmitChoice(testRoomID, 1, state.CorrectChoice)
+			} else {
+				_, err = gm.SubmitAnswer(testRoomID, 1, "Queen")
+			}
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("erreur = %v, attendu %v", err, tt.wantError)
+			}
+			if tt.wantError != nil && (state.HasAnswered[1] || state.RoundPoints[1] != 0) {
+				t.Errorf("réponse enregistrée hors manche: %v, %d points", state.HasAnswered[1], state.RoundPoints[1])
+			}
+		})
+	}
+}
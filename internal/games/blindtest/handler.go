@@ -8,6 +8,7 @@ import (
 
 	"groupie-tracker/internal/models"
 	"groupie-tracker/internal/rooms"
+	"groupie-tracker/internal/tracks"
 	"groupie-tracker/internal/websocket"
 )
 
@@ -20,22 +21,14 @@ type Handler struct {
 	mutex       sync.Mutex
 }
 
-var (
-	handlerInstance *Handler
-	handlerOnce     sync.Once
-)
-
-func GetHandler() *Handler {
-	handlerOnce.Do(func() {
-		handlerInstance = &Handler{
-			gameManager: GetGameManager(),
-			roomManager: rooms.GetManager(),
-			hub:         websocket.GetHub(),
-			stopTimers:  make(map[string]chan bool),
-			roundLocks:  make(map[string]*sync.Mutex),
-		}
-	})
-	return handlerInstance
+func NewHandler(gameManager *GameManager, roomManager *rooms.Manager, hub *websocket.Hub) *Handler {
+	return &Handler{
+		gameManager: gameManager,
+		roomManager: roomManager,
+		hub:         hub,
+		stopTimers:  make(map[string]chan bool),
+		roundLocks:  make(map[string]*sync.Mutex),
+	}
 }
 
 func (h *Handler) HandleMessage(client *websocket.Client, msg *models.WSMessage) {
@@ -44,12 +37,14 @@ func (h *Handler) HandleMessage(client *websocket.Client, msg *models.WSMessage)
 	switch msg.Type {
 	case models.WSTypeBTAnswer:
 		h.handleAnswer(client, msg)
+	case models.WSTypeBTBuzz:
+		h.handleBuzz(client)
 	default:
 		log.Printf("[BlindTest] ⚠️ Message non géré: %s", msg.Type)
 	}
 }
 
-func (h *Handler) StartGame(roomCode string, genre string, rounds int) error {
+func (h *Handler) StartGame(roomCode string, provider tracks.TrackProvider, genre string, settings Settings) error {
 	room, err := h.roomManager.GetRoomByCode(roomCode)
 	if err != nil {
 		room, err = h.roomManager.GetRoom(roomCode)
@@ -58,12 +53,12 @@ func (h *Handler) StartGame(roomCode string, genre string, rounds int) error {
 		}
 	}
 
-	_, err = h.gameManager.StartGame(room.ID, genre, rounds)
+	_, err = h.gameManager.StartGame(room.ID, provider, genre, settings)
 	if err != nil {
 		return err
 	}
 
-	log.Printf("[BlindTest] ✅ Partie démarrée dans la salle %s (genre: %s, manches: %d)", roomCode, genre, rounds)
+	log.Printf("[BlindTest] ✅ Partie démarrée dans la salle %s (source: %s, genre: %s, manches: %d, %ds/manche)", roomCode, provider.Name(), genre, settings.Rounds, settings.RoundTime)
 
 	h.mutex.Lock()
 	h.stopTimers[room.ID] = make(chan bool, 1)
@@ -118,7 +113,9 @@ func (h *Handler) startNextRound(roomID, roomCode string) {
 		},
 	})
 
-	time.Sleep(1500 * time.Millisecond)
+	// La pause entre les manches laisse aux navigateurs le temps de charger
+	// l'extrait annoncé par bt_preload.
+	time.Sleep(time.Duration(h.gameManager.GetSettings(roomID).PauseTime) * time.Second)
 
 	h.mutex.Lock()
 	if oldChan, exists := h.stopTimers[roomID]; exists {
@@ -135,14 +132,68 @@ func (h *Handler) startNextRound(roomID, roomCode string) {
 		Payload: roundInfo,
 	})
 
+	if h.gameManager.GetSettings(roomID).ClipMode == ClipModeProgressive {
+		go h.runClipStages(roomID, roomCode)
+		return
+	}
 	go h.runRoundTimer(roomID, roomCode, roundInfo.Duration)
 }
 
 func (h *Handler) runRoundTimer(roomID, roomCode string, duration int) {
+	state, stopChan := h.timerState(roomID)
+	if state == nil {
+		return
+	}
+
+	log.Printf("[BlindTest] ⏱️ Timer démarré: %d secondes", duration)
+
+	if !h.countdown(roomID, roomCode, state, stopChan, duration, 0) {
+		return
+	}
+
+	log.Printf("[BlindTest] ⏰ Temps écoulé pour salle %s", roomCode)
+	h.revealAndContinue(roomID, roomCode)
+}
+
+// runClipStages déroule une manche en mode progressif : chaque étape publie
+// un extrait plus long et son propre compte à rebours. La réponse est
+// révélée après la dernière étape.
+func (h *Handler) runClipStages(roomID, roomCode string) {
+	state, stopChan := h.timerState(roomID)
+	if state == nil {
+		return
+	}
+
+	for {
+		stage, err := h.gameManager.NextStage(roomID)
+		if err != nil {
+			log.Printf("[BlindTest] ❌ Erreur NextStage: %v", err)
+			break
+		}
+		if stage == nil {
+			break
+		}
+
+		log.Printf("[BlindTest] 🎧 Étape %d/%d (%ds d'extrait) pour salle %s", stage.Stage, stage.Stages, stage.Seconds, roomCode)
+		h.hub.Broadcast(roomCode, &models.WSMessage{
+			Type:    models.WSTypeBTClipStage,
+			Payload: stage,
+		})
+
+		if !h.countdown(roomID, roomCode, state, stopChan, stage.Duration, stage.Stage) {
+			return
+		}
+	}
+
+	log.Printf("[BlindTest] ⏰ Dernière étape écoulée pour salle %s", roomCode)
+	h.revealAndContinue(roomID, roomCode)
+}
+
+func (h *Handler) timerState(roomID string) (*GameState, chan bool) {
 	state := h.gameManager.GetGameState(roomID)
 	if state == nil {
 		log.Printf("[BlindTest] ❌ État du jeu non trouvé pour %s", roomID)
-		return
+		return nil, nil
 	}
 
 	h.mutex.Lock()
@@ -151,11 +202,16 @@ func (h *Handler) runRoundTimer(roomID, roomCode string, duration int) {
 
 	if stopChan == nil {
 		log.Printf("[BlindTest] ❌ Stop channel non trouvé")
-		return
+		return nil, nil
 	}
+	return state, stopChan
+}
 
-	log.Printf("[BlindTest] ⏱️ Timer démarré: %d secondes", duration)
-
+// countdown envoie time_update chaque seconde pendant duration secondes. Il
+// renvoie false si le timer a été interrompu ou la partie terminée : la
+// révélation est alors déjà prise en charge ailleurs. stage vaut 0 hors mode
+// progressif ; les indices ne sont publiés que dans ce cas.
+func (h *Handler) countdown(roomID, roomCode string, state *GameState, stopChan chan bool, duration, stage int) bool {
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
@@ -165,7 +221,7 @@ func (h *Handler) runRoundTimer(roomID, roomCode string, duration int) {
 		select {
 		case <-stopChan:
 			log.Printf("[BlindTest] ⏹️ Timer interrompu")
-			return
+			return false
 		default:
 		}
 
@@ -173,16 +229,30 @@ func (h *Handler) runRoundTimer(roomID, roomCode string, duration int) {
 		state.TimeLeft = timeLeft
 		state.Mutex.Unlock()
 
+		payload := map[string]int{
+			"time_left": timeLeft,
+		}
+		if stage > 0 {
+			payload["stage"] = stage
+		}
 		h.hub.Broadcast(roomCode, &models.WSMessage{
-			Type: models.WSTypeTimeUpdate,
-			Payload: map[string]int{
-				"time_left": timeLeft,
-			},
+			Type:    models.WSTypeTimeUpdate,
+			Payload: payload,
 		})
+		if stage == 0 {
+			h.releaseHints(roomID, roomCode, duration-timeLeft)
+		}
 
 		if h.gameManager.GetGameState(roomID) == nil {
 			log.Printf("[BlindTest] Jeu terminé pendant le timer")
-			return
+			return false
+		}
+
+		// Un joueur qui se déconnecte peut laisser une manche où tous les
+		// joueurs encore là ont fini.
+		if timeLeft < duration && h.allPlayersFinished(roomID) {
+			h.endRoundEarly(roomID, roomCode)
+			return false
 		}
 
 		if timeLeft == 0 {
@@ -192,21 +262,35 @@ func (h *Handler) runRoundTimer(roomID, roomCode string, duration int) {
 		select {
 		case <-stopChan:
 			log.Printf("[BlindTest] ⏹️ Timer interrompu pendant l'attente")
-			return
+			return false
 		case <-ticker.C:
-			timeLeft--
+			// Le temps ne s'écoule pas pendant qu'un joueur a la main au
+			// buzzer.
+			if !h.gameManager.IsBuzzed(roomID) {
+				timeLeft--
+			}
 		}
 	}
 
 	select {
 	case <-stopChan:
 		log.Printf("[BlindTest] ⏹️ Timer déjà interrompu, on ne révèle pas")
-		return
+		return false
 	default:
 	}
+	return true
+}
 
-	log.Printf("[BlindTest] ⏰ Temps écoulé pour salle %s", roomCode)
-	h.revealAndContinue(roomID, roomCode)
+// releaseHints envoie les indices dont le moment est passé. Plusieurs
+// peuvent partir ensemble si le timer a été interrompu.
+func (h *Handler) releaseHints(roomID, roomCode string, elapsed int) {
+	for hint := h.gameManager.NextHint(roomID, elapsed); hint != nil; hint = h.gameManager.NextHint(roomID, elapsed) {
+		log.Printf("[BlindTest] 💡 Indice %d (%s) pour salle %s, %d points max", hint.Index, hint.Kind, roomCode, hint.MaxPoints)
+		h.hub.Broadcast(roomCode, &models.WSMessage{
+			Type:    models.WSTypeBTHint,
+			Payload: hint,
+		})
+	}
 }
 
 func (h *Handler) handleAnswer(client *websocket.Client, msg *models.WSMessage) {
@@ -216,15 +300,18 @@ func (h *Handler) handleAnswer(client *websocket.Client, msg *models.WSMessage)
 		return
 	}
 
+	// En mode choix multiple, le joueur envoie l'identifiant d'une
+	// proposition de bt_new_round au lieu d'un texte.
 	var answer struct {
-		Answer string `json:"answer"`
+		Answer   string `json:"answer"`
+		ChoiceID int    `json:"choice_id"`
 	}
 	if err := json.Unmarshal(payloadBytes, &answer); err != nil {
 		client.SendError("Format de réponse invalide")
 		return
 	}
 
-	log.Printf("[BlindTest] 🔍 Réponse de %s: %s", client.Pseudo, answer.Answer)
+	log.Printf("[BlindTest] 🔍 Réponse de %s: %q (choix: %d)", client.Pseudo, answer.Answer, answer.ChoiceID)
 
 	room, err := h.roomManager.GetRoomByCode(client.RoomCode)
 	if err != nil {
@@ -235,7 +322,12 @@ func (h *Handler) handleAnswer(client *websocket.Client, msg *models.WSMessage)
 		}
 	}
 
-	result, err := h.gameManager.SubmitAnswer(room.ID, client.UserID, answer.Answer)
+	var result *AnswerResult
+	if answer.ChoiceID != 0 {
+		result, err = h.gameManager.SubmitChoice(room.ID, client.UserID, answer.ChoiceID)
+	} else {
+		result, err = h.gameManager.SubmitAnswer(room.ID, client.UserID, answer.Answer)
+	}
 	if err != nil {
 		client.SendError(err.Error())
 		return
@@ -246,43 +338,133 @@ func (h *Handler) handleAnswer(client *websocket.Client, msg *models.WSMessage)
 		Payload: result,
 	})
 
-	if result.IsCorrect && !result.AlreadyAnswered {
-		log.Printf("[BlindTest] ✅ Bonne réponse de %s ! +%d points", client.Pseudo, result.Points)
+	if result.IsCorrect {
+		log.Printf("[BlindTest] ✅ %s trouve (titre: %v, artiste: %v) +%d points", client.Pseudo, result.HasTitle, result.HasArtist, result.Points)
 
 		h.hub.Broadcast(client.RoomCode, &models.WSMessage{
 			Type: models.WSTypePlayerFound,
 			Payload: map[string]interface{}{
-				"user_id": client.UserID,
-				"pseudo":  client.Pseudo,
-				"points":  result.Points,
+				"user_id":      client.UserID,
+				"pseudo":       client.Pseudo,
+				"points":       result.Points,
+				"found_title":  result.FoundTitle,
+				"found_artist": result.FoundArtist,
+				"complete":     result.Complete(),
 			},
 		})
 
 		h.broadcastScores(room.ID, client.RoomCode)
+	} else if !result.AlreadyAnswered {
+		log.Printf("[BlindTest] ❌ Mauvaise réponse de %s (%d points)", client.Pseudo, result.Points)
+		if result.Points != 0 {
+			h.broadcastScores(room.ID, client.RoomCode)
+		}
+	}
 
-		if h.allPlayersAnsweredCorrectly(room.ID) {
-			log.Printf("[BlindTest] 🎉 Tous les joueurs ont trouvé !")
-
-			h.mutex.Lock()
-			if stopChan, exists := h.stopTimers[room.ID]; exists {
-				select {
-				case stopChan <- true:
-				default:
-				}
+	if h.gameManager.GetSettings(room.ID).AnswerMode == AnswerModeBuzzer {
+		if !result.AlreadyAnswered {
+			var lockout *BuzzLockout
+			if result.LockedOut {
+				lockout = &BuzzLockout{UserID: client.UserID, Pseudo: client.Pseudo, Reason: LockoutWrong}
 			}
-			h.mutex.Unlock()
+			h.resumeAfterBuzz(room.ID, client.RoomCode, lockout)
+		}
+		return
+	}
+
+	if result.Locked && !result.AlreadyAnswered && h.allPlayersFinished(room.ID) {
+		h.endRoundEarly(room.ID, client.RoomCode)
+	}
+}
 
-			go func() {
-				time.Sleep(1 * time.Second)
-				h.revealAndContinue(room.ID, client.RoomCode)
-			}()
+// endRoundEarly arrête le timer et révèle la réponse quand plus personne
+// n'a rien à proposer.
+func (h *Handler) endRoundEarly(roomID, roomCode string) {
+	log.Printf("[BlindTest] 🎉 Tous les joueurs ont fini la manche !")
+
+	h.mutex.Lock()
+	if stopChan, exists := h.stopTimers[roomID]; exists {
+		select {
+		case stopChan <- true:
+		default:
 		}
-	} else if !result.IsCorrect {
-		log.Printf("[BlindTest] ❌ Mauvaise réponse de %s", client.Pseudo)
 	}
+	h.mutex.Unlock()
+
+	go func() {
+		time.Sleep(1 * time.Second)
+		h.revealAndContinue(roomID, roomCode)
+	}()
 }
 
-func (h *Handler) allPlayersAnsweredCorrectly(roomID string) bool {
+// handleBuzz donne la main au premier joueur qui buzze et fige la lecture
+// pour tous. Sans réponse au bout de BuzzTime secondes, il est éliminé.
+func (h *Handler) handleBuzz(client *websocket.Client) {
+	room, err := h.roomManager.GetRoomByCode(client.RoomCode)
+	if err != nil {
+		room, err = h.roomManager.GetRoom(client.RoomCode)
+		if err != nil {
+			client.SendError("Salle non trouvée")
+			return
+		}
+	}
+
+	buzz, err := h.gameManager.Buzz(room.ID, client.UserID, client.Pseudo)
+	if err != nil {
+		client.SendError(err.Error())
+		return
+	}
+
+	log.Printf("[BlindTest] 🔔 %s a buzzé (%ds pour répondre)", client.Pseudo, buzz.AnswerTime)
+	h.hub.Broadcast(client.RoomCode, &models.WSMessage{
+		Type:    models.WSTypeBTBuzzed,
+		Payload: buzz,
+	})
+
+	roomID, roomCode := room.ID, client.RoomCode
+	time.AfterFunc(time.Duration(buzz.AnswerTime)*time.Second, func() {
+		if lockout := h.gameManager.ExpireBuzz(roomID, buzz.seq); lockout != nil {
+			log.Printf("[BlindTest] ⌛ %s n'a pas répondu à temps", lockout.Pseudo)
+			h.resumeAfterBuzz(roomID, roomCode, lockout)
+		}
+	})
+}
+
+// resumeAfterBuzz annonce l'élimination éventuelle du joueur qui avait la
+// main, puis relance la lecture pour les autres, ou termine la manche si
+// plus personne ne peut répondre.
+func (h *Handler) resumeAfterBuzz(roomID, roomCode string, lockout *BuzzLockout) {
+	if lockout != nil {
+		h.hub.Broadcast(roomCode, &models.WSMessage{
+			Type:    models.WSTypeBTBuzzLock,
+			Payload: lockout,
+		})
+	}
+
+	if h.allPlayersFinished(roomID) {
+		h.endRoundEarly(roomID, roomCode)
+		return
+	}
+
+	state := h.gameManager.GetGameState(roomID)
+	if state == nil {
+		return
+	}
+	state.Mutex.RLock()
+	timeLeft := state.TimeLeft
+	state.Mutex.RUnlock()
+
+	h.hub.Broadcast(roomCode, &models.WSMessage{
+		Type: models.WSTypeBTResume,
+		Payload: map[string]int{
+			"time_left": timeLeft,
+		},
+	})
+}
+
+// allPlayersFinished indique que plus personne n'a de réponse à donner :
+// tous ont tout trouvé, ou tous ont choisi en mode choix multiple.
+func (h *Handler) allPlayersFinished(roomID string) bool {
 	state := h.gameManager.GetGameState(roomID)
 	if state == nil {
 		return false
@@ -293,21 +475,29 @@ func (h *Handler) allPlayersAnsweredCorrectly(roomID string) bool {
 		return false
 	}
 
+	// Les joueurs déconnectés, même pendant leur délai de grâce, ne retiennent
+	// pas la manche.
 	room.Mutex.RLock()
-	playerCount := len(room.Players)
+	connected := make([]int64, 0, len(room.Players))
+	for userID, player := range room.Players {
+		if player.Connected {
+			connected = append(connected, userID)
+		}
+	}
 	room.Mutex.RUnlock()
 
+	if len(connected) == 0 {
+		return false
+	}
+
 	state.Mutex.RLock()
-	correctCount := 0
-	for userID := range state.HasAnswered {
-		answer := state.Answers[userID]
-		if checkAnswer(answer, state.CurrentTrack.Name, state.CurrentTrack.Artist) {
-			correctCount++
+	defer state.Mutex.RUnlock()
+	for _, userID := range connected {
+		if !state.isDone(userID) {
+			return false
 		}
 	}
-	state.Mutex.RUnlock()
-
-	return correctCount >= playerCount && playerCount > 0
+	return true
 }
 
 func (h *Handler) broadcastScores(roomID, roomCode string) {
@@ -325,26 +515,22 @@ func (h *Handler) revealAndContinue(roomID, roomCode string) {
 		return
 	}
 
-	state.Mutex.Lock()
-	if state.IsRevealed {
-		state.Mutex.Unlock()
+	// RevealAnswer ne révèle qu'une fois : la fin anticipée et la fin du
+	// timer peuvent arriver ensemble.
+	revealInfo := h.gameManager.RevealAnswer(roomID)
+	if revealInfo == nil {
 		log.Printf("[BlindTest] ⚠️ Déjà révélé, on ignore")
 		return
 	}
-	state.Mutex.Unlock()
-
-	revealInfo := h.gameManager.RevealAnswer(roomID)
-	if revealInfo != nil {
-		log.Printf("[BlindTest] 🔔 Révélation: %s - %s", revealInfo.TrackName, revealInfo.ArtistName)
-		h.hub.Broadcast(roomCode, &models.WSMessage{
-			Type:    models.WSTypeBTReveal,
-			Payload: revealInfo,
-		})
-	}
+	log.Printf("[BlindTest] 🔔 Révélation: %s - %s", revealInfo.TrackName, revealInfo.ArtistName)
+	h.hub.Broadcast(roomCode, &models.WSMessage{
+		Type:    models.WSTypeBTReveal,
+		Payload: revealInfo,
+	})
 
 	h.broadcastScores(roomID, roomCode)
 
-	time.Sleep(4 * time.Second)
+	time.Sleep(time.Duration(h.gameManager.GetSettings(roomID).RevealTime) * time.Second)
 
 	if h.gameManager.IsGameOver(roomID) {
 		log.Printf("[BlindTest] 🏁 Partie terminée pour salle %s", roomCode)
@@ -0,0 +1,146 @@
+package blindtest
+
+import (
+	"log"
+	"strings"
+	"unicode"
+)
+
+// Indices d'une manche, dans l'ordre où ils sont envoyés (bt_hint).
+const (
+	HintWordCount    = "word_count"
+	HintFirstLetters = "first_letters"
+	HintCoverBlurred = "cover_blurred"
+	HintCover        = "cover"
+	HintArtistLetter = "artist_letter"
+)
+
+var hintKinds = []string{HintWordCount, HintFirstLetters, HintCoverBlurred, HintCover, HintArtistLetter}
+
+// DefaultHintAt place les indices en pourcentage de la manche écoulée.
+var DefaultHintAt = []int{20, 40, 55, 70, 85}
+
+// Chaque indice publié retire hintDiscount % des points d'une bonne réponse.
+const hintDiscount = 15
+
+// Hint est le contenu d'un message bt_hint. Seuls les champs de l'indice
+// concerné sont remplis : ImageURL est la pochette floutée par le serveur
+// pour cover_blurred, la vraie pour cover. MaxPoints est le maximum que
+// rapporte encore la manche au barème de la salle.
+type Hint struct {
+	Index     int      `json:"index"`
+	Kind      string   `json:"kind"`
+	WordCount int      `json:"word_count,omitempty"`
+	Letters   []string `json:"letters,omitempty"`
+	ImageURL  string   `json:"image_url,omitempty"`
+	Letter    string   `json:"letter,omitempty"`
+	MaxPoints int      `json:"max_points"`
+}
+
+// hintSecond renvoie la seconde de la manche à laquelle l'indice index est
+// publié.
+func hintSecond(hintAt []int, index, roundTime int) int {
+	return roundTime * hintAt[index] / 100
+}
+
+// NextHint publie l'indice suivant si son heure est venue, elapsed étant le
+// nombre de secondes écoulées dans la manche. Il renvoie nil s'il n'y a rien
+// à publier.
+func (gm *GameManager) NextHint(roomID string, elapsed int) *Hint {
+	state := gm.GetGameState(roomID)
+	if state == nil {
+		return nil
+	}
+
+	state.Mutex.Lock()
+	defer state.Mutex.Unlock()
+
+	settings := state.Settings
+	if !settings.Hints || state.CurrentTrack == nil || state.IsRevealed {
+		return nil
+	}
+	index := len(state.Hints)
+	if index >= len(hintKinds) || index >= len(settings.HintAt) {
+		return nil
+	}
+	if elapsed < hintSecond(settings.HintAt, index, settings.RoundTime) {
+		return nil
+	}
+
+	// La vraie pochette n'est envoyée qu'avec l'indice cover : avant, les
+	// clients ne reçoivent que le jeton d'une version floutée.
+	imageURL := state.CurrentTrack.ImageURL
+	if hintKinds[index] == HintCoverBlurred && imageURL != "" {
+		blurred, err := gm.previews.BlurCover(roomID, imageURL)
+		if err != nil {
+			log.Printf("[BlindTest] Erreur pochette floutée salle %s: %v", roomID, err)
+		}
+		imageURL = blurred
+	}
+
+	hint := buildHint(state.CurrentTrack.Name, state.CurrentTrack.Artist, imageURL, index)
+	hint.MaxPoints = maxRoundPoints(state.Scoring, index+1)
+	state.Hints = append(state.Hints, hint)
+	return hint
+}
+
+// buildHint remplit l'indice index ; imageURL est l'adresse à publier pour
+// les indices de pochette.
+func buildHint(title, artist, imageURL string, index int) *Hint {
+	hint := &Hint{
+		Index: index + 1,
+		Kind:  hintKinds[index],
+	}
+	words := hintWords(title)
+	switch hint.Kind {
+	case HintWordCount:
+		hint.WordCount = len(words)
+	case HintFirstLetters:
+		for _, word := range words {
+			hint.Letters = append(hint.Letters, firstLetter(word))
+		}
+	case HintCoverBlurred, HintCover:
+		hint.ImageURL = imageURL
+	case HintArtistLetter:
+		hint.Letter = firstLetter(artist)
+	}
+	return hint
+}
+
+// hintWords découpe le titre tel que le joueur doit le trouver : sans
+// invités, parenthèses ni mention de version, mais avec ses articles.
+func hintWords(title string) []string {
+	cleaned := bracketPattern.ReplaceAllString(title, " ")
+	cleaned = versionPattern.ReplaceAllString(cleaned, "")
+	cleaned = featPattern.ReplaceAllString(cleaned, "")
+
+	var words []string
+	for _, word := range strings.Fields(cleaned) {
+		if firstLetter(word) != "" {
+			words = append(words, word)
+		}
+	}
+	if len(words) == 0 {
+		return strings.Fields(title)
+	}
+	return words
+}
+
+func firstLetter(s string) string {
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return string(unicode.ToUpper(r))
+		}
+	}
+	return ""
+}
+
+// maxRoundPoints est le maximum d'une manche au barème strategy après hints
+// indices.
+func maxRoundPoints(strategy ScoringStrategy, hints int) int {
+	return maxAnswerPoints(strategy) * (100 - hintPercent(hints)) / 100
+}
+
+func hintPercent(hints int) int {
+	return min(hints*hintDiscount, 100)
+}
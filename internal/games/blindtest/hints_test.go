@@ -0,0 +1,153 @@
+package blindtest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"groupie-tracker/internal/games"
+	"groupie-tracker/internal/media"
+	"groupie-tracker/internal/models"
+)
+
+func TestBuildHint(t *testing.T) {
+	const cover = "https://cdn.example.com/cover.jpg"
+
+	tests := []struct {
+		title  string
+		artist string
+		index  int
+		want   Hint
+	}{
+		{"Get Lucky (feat. Pharrell Williams)", "Daft Punk", 0, Hint{Index: 1, Kind: HintWordCount, WordCount: 2}},
+		{"L'Aventurier", "Indochine", 0, Hint{Index: 1, Kind: HintWordCount, WordCount: 1}},
+		{"Don't Stop Me Now - Remastered 2011", "Queen", 1, Hint{Index: 2, Kind: HintFirstLetters, Letters: []string{"D", "S", "M", "N"}}},
+		{"Ça plane pour moi", "Plastic Bertrand", 1, Hint{Index: 2, Kind: HintFirstLetters, Letters: []string{"Ç", "P", "P", "M"}}},
+		{"Formidable", "Stromae", 2, Hint{Index: 3, Kind: HintCoverBlurred, ImageURL: cover}},
+		{"Formidable", "Stromae", 3, Hint{Index: 4, Kind: HintCover, ImageURL: cover}},
+		{"Yesterday", "The Beatles", 4, Hint{Index: 5, Kind: HintArtistLetter, Letter: "T"}},
+		{"99 Luftballons", "Nena", 1, Hint{Index: 2, Kind: HintFirstLetters, Letters: []string{"9", "L"}}},
+	}
+
+	for _, tt := range tests {
+		got := buildHint(tt.title, tt.artist, cover, tt.index)
+		if got.Index != tt.want.Index || got.Kind != tt.want.Kind || got.WordCount != tt.want.WordCount ||
+			!slices.Equal(got.Letters, tt.want.Letters) || got.ImageURL != tt.want.ImageURL || got.Letter != tt.want.Letter {
+			t.Errorf("buildHint(%q, %q, %d) = %+v, attendu %+v", tt.title, tt.artist, tt.index, *got, tt.want)
+		}
+	}
+}
+
+func TestMaxRoundPoints(t *testing.T) {
+	tests := []struct {
+		strategy string
+		hints    int
+		want     int
+	}{
+		{"classic", 0, 180},
+		{"classic", 1, 153},
+		{"classic", 5, 45},
+		{"first_finder", 0, 210},
+		{"first_finder", 2, 147},
+		{"penalty", 3, 99},
+	}
+
+	for _, tt := range tests {
+		if got := maxRoundPoints(GetScoringStrategy(tt.strategy), tt.hints); got != tt.want {
+			t.Errorf("maxRoundPoints(%s, %d) = %d, attendu %d", tt.strategy, tt.hints, got, tt.want)
+		}
+	}
+}
+
+func TestNextHint(t *testing.T) {
+	// La pochette floutée est préparée en arrière-plan : une 404 suffit ici.
+	images := httptest.NewServer(http.NotFoundHandler())
+	defer images.Close()
+
+	settings := SettingsFromConfig(models.GameConfig{TimePerRound: 20, Hints: true, Scoring: "first_finder"})
+	gm, state := newTestRound(t, settings)
+	state.CurrentTrack.ImageURL = images.URL + "/cover.jpg"
+
+	// Avec une manche de 20s, les indices tombent à 4, 8, 11, 14 et 17s.
+	tests := []struct {
+		elapsed   int
+		wantKind  string
+		wantMax   int
+		wantHints int
+	}{
+		{3, "", 0, 0},
+		{4, HintWordCount, 178, 1},
+		{4, "", 0, 1},
+		{9, HintFirstLetters, 147, 2},
+		{11, HintCoverBlurred, 115, 3},
+		{14, HintCover, 84, 4},
+		{20, HintArtistLetter, 52, 5},
+		{20, "", 0, 5},
+	}
+
+	for _, tt := range tests {
+		hint := gm.NextHint(testRoomID, tt.elapsed)
+		switch {
+		case tt.wantKind == "" && hint != nil:
+			t.Errorf("à %ds: indice %s inattendu", tt.elapsed, hint.Kind)
+		case tt.wantKind != "" && hint == nil:
+			t.Errorf("à %ds: indice %s attendu", tt.elapsed, tt.wantKind)
+		case hint != nil && (hint.Kind != tt.wantKind || hint.MaxPoints != tt.wantMax):
+			t.Errorf("à %ds: %s à %d points, attendu %s à %d points", tt.elapsed, hint.Kind, hint.MaxPoints, tt.wantKind, tt.wantMax)
+		}
+		if len(state.Hints) != tt.wantHints {
+			t.Errorf("à %ds: %d indices publiés, attendu %d", tt.elapsed, len(state.Hints), tt.wantHints)
+		}
+	}
+
+	blurred, revealed := state.Hints[2], state.Hints[3]
+	if !strings.HasPrefix(blurred.ImageURL, media.CoverPrefix) {
+		t.Errorf("pochette floutée servie par %q, attendu un jeton %s", blurred.ImageURL, media.CoverPrefix)
+	}
+	if revealed.ImageURL != state.CurrentTrack.ImageURL {
+		t.Errorf("pochette de l'indice cover = %q, attendu %q", revealed.ImageURL, state.CurrentTrack.ImageURL)
+	}
+}
+
+func TestNextHintOutsideRound(t *testing.T) {
+	tests := []struct {
+		name     string
+		hints    bool
+		revealed bool
+	}{
+		{"indices désactivés", false, false},
+		{"manche révélée", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm, state := newTestRound(t, SettingsFromConfig(models.GameConfig{Hints: tt.hints}))
+			state.IsRevealed = tt.revealed
+			if hint := gm.NextHint(testRoomID, state.Settings.RoundTime); hint != nil {
+				t.Errorf("indice publié: %+v", hint)
+			}
+		})
+	}
+}
+
+func TestValidateHintsWithClipMode(t *testing.T) {
+	tests := []struct {
+		hints    bool
+		clipMode string
+		want     error
+	}{
+		{true, ClipModeFull, nil},
+		{false, ClipModeProgressive, nil},
+		{true, ClipModeProgressive, games.ErrInvalidConfig},
+	}
+
+	for _, tt := range tests {
+		settings := SettingsFromConfig(models.GameConfig{Hints: tt.hints, ClipMode: tt.clipMode})
+		if err := settings.Validate(); !errors.Is(err, tt.want) {
+			t.Errorf("Validate(indices %v, %s) = %v, attendu %v", tt.hints, tt.clipMode, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package blindtest
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// minAnswerLength est la longueur minimale d'une réponse, sauf si elle
+// correspond exactement à la cible (ex. "U2", "M").
+const minAnswerLength = 3
+
+var (
+	// Parties entre parenthèses ou crochets : "(feat. X)", "[Live]", "(2011 Remaster)".
+	bracketPattern = regexp.MustCompile(`[\(\[\{][^\)\]\}]*[\)\]\}]`)
+	// Suffixe de version après un tiret : "- Remastered 2009", "- Radio Edit", "- Remix".
+	versionPattern = regexp.MustCompile(`(?i)\s[-–—]\s.*\b(remaster(ed)?|version|edit|mix|remix|live|mono|stereo|acoustic|demo|bonus|deluxe)\b.*$`)
+	// Invités : "feat. X", "ft. X", "featuring X" jusqu'à la fin.
+	featPattern = regexp.MustCompile(`(?i)\s(feat\.?|ft\.?|featuring)\s.*$`)
+	// Séparateurs entre artistes d'un même crédit. Virgules et "&" n'en font
+	// pas partie : ils appartiennent souvent au nom ("Earth, Wind & Fire").
+	creditPattern = regexp.MustCompile(`\s*(?:;|/|\s(?:feat\.?|ft\.?|featuring|x|vs\.?|with)\s)\s*`)
+)
+
+// leadingArticles sont retirés en tête de titre et d'artiste : "The Beatles"
+// et "Beatles" sont acceptés tous les deux. connectorWords sont ignorés
+// partout : "Simon & Garfunkel", "Simon and Garfunkel", "Simon et Garfunkel".
+var leadingArticles = map[string]bool{
+	"the": true, "a": true, "an": true,
+	"le": true, "la": true, "les": true, "l": true, "un": true, "une": true,
+	"el": true, "los": true, "las": true,
+	"die": true, "der": true, "das": true,
+}
+
+var connectorWords = map[string]bool{"and": true, "et": true, "n": true}
+
+// ligatures ne sont pas décomposées par NFKD.
+var ligatures = strings.NewReplacer("œ", "oe", "Œ", "oe", "æ", "ae", "Æ", "ae", "ß", "ss", "ø", "o", "Ø", "o", "ł", "l", "Ł", "l", "đ", "d", "Đ", "d")
+
+// knownAliases associe un artiste aux autres noms sous lesquels les joueurs
+// le connaissent. Les clés et les alias sont normalisés au démarrage.
+var knownAliases = map[string][]string{
+	"JAY-Z":                        {"Jigga", "Hov"},
+	"Eminem":                       {"Slim Shady", "Marshall Mathers"},
+	"The Notorious B.I.G.":         {"Biggie", "Biggie Smalls", "Notorious"},
+	"2Pac":                         {"Tupac", "Makaveli"},
+	"Snoop Dogg":                   {"Snoop", "Snoop Doggy Dogg", "Snoop Lion"},
+	"Beyoncé":                      {"Queen B"},
+	"MC Solaar":                    {"Solaar"},
+	"Johnny Hallyday":              {"Johnny"},
+	"Michael Jackson":              {"MJ", "Bambi"},
+	"Puff Daddy":                   {"Diddy", "P. Diddy", "Sean Combs"},
+	"Lady Gaga":                    {"Gaga"},
+	"Guns N' Roses":                {"GNR"},
+	"Red Hot Chili Peppers":        {"RHCP", "Red Hot"},
+	"Supreme NTM":                  {"NTM"},
+	"Stromae":                      {"Paul Van Haver"},
+	"Elton John":                   {"Elton"},
+	"David Bowie":                  {"Bowie", "Ziggy Stardust"},
+	"The Weeknd":                   {"Abel Tesfaye"},
+	"will.i.am":                    {"Will I Am"},
+	"The Black Eyed Peas":          {"BEP"},
+	"Sexion d'Assaut":              {"Sexion"},
+	"Maître Gims":                  {"Gims"},
+	"GIMS":                         {"Maître Gims"},
+	"Booba":                        {"B2O", "Le Duc"},
+	"Nekfeu":                       {"Ken Samaras"},
+	"Dr. Dre":                      {"Dre"},
+	"50 Cent":                      {"Fifty Cent"},
+	"Lil Wayne":                    {"Weezy"},
+	"Kanye West":                   {"Kanye", "Ye"},
+	"Creedence Clearwater Revival": {"CCR", "Creedence"},
+	"Electric Light Orchestra":     {"ELO"},
+	"Earth, Wind & Fire":           {"EWF"},
+}
+
+var artistAliases = normalizeAliases(knownAliases)
+
+func normalizeAliases(aliases map[string][]string) map[string][]string {
+	normalized := make(map[string][]string, len(aliases))
+	for artist, names := range aliases {
+		key := normalizeAnswer(artist)
+		for _, name := range names {
+			normalized[key] = append(normalized[key], normalizeAnswer(name))
+		}
+	}
+	return normalized
+}
+
+// normalizeAnswer ramène une réponse ou une cible à une forme comparable :
+// décomposition NFKD sans accents, minuscules, ponctuation remplacée par des
+// espaces (sauf les points, retirés pour "B.I.G." ou "Dr."), espaces réduits,
+// mots de liaison et article initial retirés.
+func normalizeAnswer(s string) string {
+	s = ligatures.Replace(s)
+	s = norm.NFKD.String(s)
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Mn, r), r == '.':
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteByte(' ')
+		}
+	}
+
+	words := strings.Fields(b.String())
+	kept := words[:0]
+	for _, word := range words {
+		if !connectorWords[word] {
+			kept = append(kept, word)
+		}
+	}
+	if len(kept) > 1 && leadingArticles[kept[0]] {
+		kept = kept[1:]
+	}
+	return strings.Join(kept, " ")
+}
+
+// cleanTitle retire d'un titre ce qu'un joueur n'a pas à deviner : invités,
+// parenthèses, mentions de version.
+func cleanTitle(title string) string {
+	cleaned := strings.ToLower(title)
+	cleaned = bracketPattern.ReplaceAllString(cleaned, " ")
+	cleaned = versionPattern.ReplaceAllString(cleaned, "")
+	cleaned = featPattern.ReplaceAllString(cleaned, "")
+	cleaned = normalizeAnswer(cleaned)
+	if cleaned == "" {
+		// Titre entièrement entre parenthèses : on garde l'original.
+		return normalizeAnswer(title)
+	}
+	return cleaned
+}
+
+// artistNames renvoie les noms acceptés pour un crédit d'artiste : le crédit
+// complet, chaque artiste qui le compose et leurs alias connus.
+func artistNames(artist string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	add := func(name string) {
+		name = normalizeAnswer(name)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	add(artist)
+	for _, part := range creditPattern.Split(strings.ToLower(artist), -1) {
+		if runeCount(normalizeAnswer(part)) >= minAnswerLength {
+			add(part)
+		}
+	}
+
+	for _, name := range append([]string(nil), names...) {
+		for _, alias := range artistAliases[name] {
+			add(alias)
+		}
+	}
+	return names
+}
+
+func matchesTitle(answer, trackName string) bool {
+	normalized := normalizeAnswer(answer)
+	return matchesTarget(normalized, cleanTitle(trackName)) ||
+		matchesTarget(normalized, normalizeAnswer(trackName))
+}
+
+func matchesArtist(answer, artistName string) bool {
+	normalized := normalizeAnswer(answer)
+	for _, name := range artistNames(artistName) {
+		if matchesTarget(normalized, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesTarget compare deux chaînes déjà normalisées. La réponse peut
+// contenir autre chose que la cible (ex. "titre artiste") : la cible est
+// alors cherchée mot à mot dans la réponse, avec la même tolérance.
+func matchesTarget(answer, target string) bool {
+	if answer == "" || target == "" {
+		return false
+	}
+	if answer == target {
+		return true
+	}
+	if runeCount(answer) < minAnswerLength {
+		return false
+	}
+
+	if closeEnough(answer, target) {
+		return true
+	}
+
+	answerWords := strings.Fields(answer)
+	targetWords := strings.Fields(target)
+	if len(answerWords) <= len(targetWords) {
+		return false
+	}
+	// Fenêtres de la taille de la cible, à un mot près pour tolérer un mot
+	// oublié ou ajouté.
+	for size := len(targetWords) - 1; size <= len(targetWords)+1; size++ {
+		if size < 1 || size > len(answerWords) {
+			continue
+		}
+		for start := 0; start+size <= len(answerWords); start++ {
+			window := strings.Join(answerWords[start:start+size], " ")
+			if window == target || (runeCount(window) >= minAnswerLength && closeEnough(window, target)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// closeEnough tolère un nombre de fautes qui dépend de la longueur de la
+// cible : aucune jusqu'à 3 lettres, 1 jusqu'à 6, 2 jusqu'à 10, puis 20 %.
+func closeEnough(answer, target string) bool {
+	a := []rune(answer)
+	t := []rune(target)
+	allowed := maxDistance(len(t))
+	if abs(len(a)-len(t)) > allowed {
+		return false
+	}
+	return levenshteinDistance(a, t) <= allowed
+}
+
+func maxDistance(length int) int {
+	switch {
+	case length <= 3:
+		return 0
+	case length <= 6:
+		return 1
+	case length <= 10:
+		return 2
+	default:
+		return length / 5
+	}
+}
+
+func levenshteinDistance(s1, s2 []rune) int {
+	if len(s1) == 0 {
+		return len(s2)
+	}
+	if len(s2) == 0 {
+		return len(s1)
+	}
+
+	previous := make([]int, len(s2)+1)
+	current := make([]int, len(s2)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(s1); i++ {
+		current[0] = i
+		for j := 1; j <= len(s2); j++ {
+			cost := 1
+			if s1[i-1] == s2[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(s2)]
+}
+
+func runeCount(s string) int {
+	return len([]rune(s))
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,221 @@
+package blindtest
+
+// Barème commun : le titre et l'artiste rapportent chacun leur base plus un
+// bonus de rapidité, et trouver les deux dans la manche ajoute bothBonus.
+const (
+	titlePoints  = 60
+	artistPoints = 40
+	bothBonus    = 30
+	maxTimeBonus = 25
+)
+
+// maxAnswerPoints est le maximum d'une manche au barème strategy : titre et
+// artiste trouvés dès la première seconde, par le premier joueur. Les bonus
+// propres à chaque joueur (séries) n'y sont pas comptés.
+func maxAnswerPoints(strategy ScoringStrategy) int {
+	return sumPoints(strategy.ScoreAnswer(ScoringContext{
+		FoundTitle:  true,
+		FoundArtist: true,
+		Complete:    true,
+		TimeLeft:    1,
+		RoundTime:   1,
+		FinishRank:  1,
+	}))
+}
+
+// Bonus des stratégies optionnelles.
+const (
+	streakStep          = 10
+	maxStreakBonus      = 50
+	wrongGuessPenalty   = 10
+	maxPenalizedGuesses = 3
+)
+
+// firstFinderBonus récompense les trois premiers joueurs à trouver le titre
+// et l'artiste dans la manche.
+var firstFinderBonus = []int{30, 20, 10}
+
+// DefaultScoring est le barème des salles qui n'en choisissent pas.
+const DefaultScoring = "classic"
+
+// Catégories du détail des points, reprises dans bt_result et bt_scores.
+const (
+	ScoreTitle       = "title"
+	ScoreArtist      = "artist"
+	ScoreSpeed       = "speed"
+	ScoreBoth        = "both"
+	ScoreFirstFinder = "first_finder"
+	ScoreStreak      = "streak"
+	ScoreWrongGuess  = "wrong_guess"
+	ScoreHints       = "hints"
+	ScoreStage       = "stage"
+)
+
+// ScoreLine est une ligne du détail des points d'une réponse.
+type ScoreLine struct {
+	Kind   string `json:"kind"`
+	Points int    `json:"points"`
+}
+
+// ScoringContext décrit une réponse au moment où elle est notée. Les
+// compteurs sont tenus par GameState : les stratégies n'ont pas d'état.
+type ScoringContext struct {
+	FoundTitle   bool // titre trouvé par cette réponse
+	FoundArtist  bool // artiste trouvé par cette réponse
+	Complete     bool // titre et artiste trouvés, cette réponse comprise
+	TimeLeft     int
+	RoundTime    int
+	FinishRank   int // rang du joueur parmi ceux qui ont tout trouvé, 0 sinon
+	Streak       int // manches complètes consécutives avant celle-ci
+	WrongGuesses int // réponses fausses dans la manche, celle-ci comprise
+	Score        int // score du joueur dans la partie avant cette réponse
+}
+
+// ScoringStrategy décide des points d'une réponse. Elle est choisie par
+// salle (GameConfig.Scoring) et renvoie le détail plutôt qu'un total.
+type ScoringStrategy interface {
+	Name() string
+	DisplayName() string
+	// ScoreAnswer note une réponse qui trouve le titre et/ou l'artiste.
+	ScoreAnswer(ctx ScoringContext) []ScoreLine
+	// ScoreMiss note une réponse qui ne correspond ni au titre ni à l'artiste.
+	ScoreMiss(ctx ScoringContext) []ScoreLine
+}
+
+var scoringStrategies = []ScoringStrategy{
+	classicScoring{},
+	firstFinderScoring{},
+	streakScoring{},
+	penaltyScoring{},
+}
+
+// ScoringStrategies renvoie les barèmes proposés, dans l'ordre d'affichage.
+func ScoringStrategies() []ScoringStrategy {
+	return scoringStrategies
+}
+
+// GetScoringStrategy renvoie le barème demandé, ou nil s'il est inconnu.
+// Un nom vide désigne le barème par défaut.
+func GetScoringStrategy(name string) ScoringStrategy {
+	if name == "" {
+		name = DefaultScoring
+	}
+	for _, strategy := range scoringStrategies {
+		if strategy.Name() == name {
+			return strategy
+		}
+	}
+	return nil
+}
+
+func sumPoints(lines []ScoreLine) int {
+	total := 0
+	for _, line := range lines {
+		total += line.Points
+	}
+	return total
+}
+
+// discount ajoute au détail d'une bonne réponse une ligne négative de
+// percent % des points gagnés. Les pénalités ne sont pas concernées.
+func discount(lines []ScoreLine, kind string, percent int) []ScoreLine {
+	if percent <= 0 {
+		return lines
+	}
+	earned := 0
+	for _, line := range lines {
+		if line.Points > 0 {
+			earned += line.Points
+		}
+	}
+	if points := earned * min(percent, 100) / 100; points > 0 {
+		lines = append(lines, ScoreLine{Kind: kind, Points: -points})
+	}
+	return lines
+}
+
+func timeBonus(timeLeft, totalTime int) int {
+	if totalTime <= 0 {
+		return 0
+	}
+	return int(float64(timeLeft) / float64(totalTime) * maxTimeBonus)
+}
+
+// classicScoring est le barème historique : base, rapidité et bonus pour
+// le titre et l'artiste.
+type classicScoring struct{}
+
+func (classicScoring) Name() string        { return "classic" }
+func (classicScoring) DisplayName() string { return "Classique" }
+
+func (classicScoring) ScoreAnswer(ctx ScoringContext) []ScoreLine {
+	var lines []ScoreLine
+	speed := 0
+	if ctx.FoundTitle {
+		lines = append(lines, ScoreLine{Kind: ScoreTitle, Points: titlePoints})
+		speed += timeBonus(ctx.TimeLeft, ctx.RoundTime)
+	}
+	if ctx.FoundArtist {
+		lines = append(lines, ScoreLine{Kind: ScoreArtist, Points: artistPoints})
+		speed += timeBonus(ctx.TimeLeft, ctx.RoundTime)
+	}
+	if speed > 0 {
+		lines = append(lines, ScoreLine{Kind: ScoreSpeed, Points: speed})
+	}
+	if ctx.Complete {
+		lines = append(lines, ScoreLine{Kind: ScoreBoth, Points: bothBonus})
+	}
+	return lines
+}
+
+func (classicScoring) ScoreMiss(ctx ScoringContext) []ScoreLine {
+	return nil
+}
+
+// firstFinderScoring ajoute un bonus aux trois premiers joueurs qui
+// trouvent le titre et l'artiste.
+type firstFinderScoring struct{ classicScoring }
+
+func (firstFinderScoring) Name() string        { return "first_finder" }
+func (firstFinderScoring) DisplayName() string { return "Premiers trouveurs" }
+
+func (s firstFinderScoring) ScoreAnswer(ctx ScoringContext) []ScoreLine {
+	lines := s.classicScoring.ScoreAnswer(ctx)
+	if ctx.Complete && ctx.FinishRank >= 1 && ctx.FinishRank <= len(firstFinderBonus) {
+		lines = append(lines, ScoreLine{Kind: ScoreFirstFinder, Points: firstFinderBonus[ctx.FinishRank-1]})
+	}
+	return lines
+}
+
+// streakScoring récompense les manches complètes enchaînées : +10 dès la
+// deuxième, jusqu'à +50.
+type streakScoring struct{ classicScoring }
+
+func (streakScoring) Name() string        { return "streak" }
+func (streakScoring) DisplayName() string { return "Séries" }
+
+func (s streakScoring) ScoreAnswer(ctx ScoringContext) []ScoreLine {
+	lines := s.classicScoring.ScoreAnswer(ctx)
+	if ctx.Complete && ctx.Streak > 0 {
+		lines = append(lines, ScoreLine{Kind: ScoreStreak, Points: min(ctx.Streak*streakStep, maxStreakBonus)})
+	}
+	return lines
+}
+
+// penaltyScoring retire des points pour les mauvaises réponses, trois fois
+// au plus par manche, sans faire passer le score sous zéro.
+type penaltyScoring struct{ classicScoring }
+
+func (penaltyScoring) Name() string        { return "penalty" }
+func (penaltyScoring) DisplayName() string { return "Pénalités" }
+
+func (penaltyScoring) ScoreMiss(ctx ScoringContext) []ScoreLine {
+	if ctx.WrongGuesses > maxPenalizedGuesses {
+		return nil
+	}
+	penalty := min(wrongGuessPenalty, ctx.Score)
+	if penalty <= 0 {
+		return nil
+	}
+	return []ScoreLine{{Kind: ScoreWrongGuess, Points: -penalty}}
+}
@@ -0,0 +1,155 @@
+package blindtest
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestScoreAnswer(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		ctx      ScoringContext
+		want     []ScoreLine
+	}{
+		{"classique titre seul", "classic",
+			ScoringContext{FoundTitle: true, TimeLeft: 10, RoundTime: 20},
+			[]ScoreLine{{ScoreTitle, 60}, {ScoreSpeed, 12}}},
+		{"classique tout d'un coup", "classic",
+			ScoringContext{FoundTitle: true, FoundArtist: true, Complete: true, TimeLeft: 20, RoundTime: 20},
+			[]ScoreLine{{ScoreTitle, 60}, {ScoreArtist, 40}, {ScoreSpeed, 50}, {ScoreBoth, 30}}},
+		{"classique artiste en dernière seconde", "classic",
+			ScoringContext{FoundArtist: true, Complete: true, TimeLeft: 0, RoundTime: 20},
+			[]ScoreLine{{ScoreArtist, 40}, {ScoreBoth, 30}}},
+		{"premier trouveur", "first_finder",
+			ScoringContext{FoundTitle: true, FoundArtist: true, Complete: true, TimeLeft: 0, RoundTime: 20, FinishRank: 1},
+			[]ScoreLine{{ScoreTitle, 60}, {ScoreArtist, 40}, {ScoreBoth, 30}, {ScoreFirstFinder, 30}}},
+		{"troisième trouveur", "first_finder",
+			ScoringContext{FoundArtist: true, Complete: true, RoundTime: 20, FinishRank: 3},
+			[]ScoreLine{{ScoreArtist, 40}, {ScoreBoth, 30}, {ScoreFirstFinder, 10}}},
+		{"quatrième trouveur", "first_finder",
+			ScoringContext{FoundArtist: true, Complete: true, RoundTime: 20, FinishRank: 4},
+			[]ScoreLine{{ScoreArtist, 40}, {ScoreBoth, 30}}},
+		{"premier trouveur incomplet", "first_finder",
+			ScoringContext{FoundTitle: true, RoundTime: 20},
+			[]ScoreLine{{ScoreTitle, 60}}},
+		{"série de deux", "streak",
+			ScoringContext{FoundArtist: true, Complete: true, RoundTime: 20, Streak: 2},
+			[]ScoreLine{{ScoreArtist, 40}, {ScoreBoth, 30}, {ScoreStreak, 20}}},
+		{"série plafonnée", "streak",
+			ScoringContext{FoundArtist: true, Complete: true, RoundTime: 20, Streak: 7},
+			[]ScoreLine{{ScoreArtist, 40}, {ScoreBoth, 30}, {ScoreStreak, 50}}},
+		{"série sans manche précédente", "streak",
+			ScoringContext{FoundArtist: true, Complete: true, RoundTime: 20},
+			[]ScoreLine{{ScoreArtist, 40}, {ScoreBoth, 30}}},
+		{"série incomplète", "streak",
+			ScoringContext{FoundTitle: true, RoundTime: 20, Streak: 3},
+			[]ScoreLine{{ScoreTitle, 60}}},
+		{"pénalités comme le classique", "penalty",
+			ScoringContext{FoundTitle: true, FoundArtist: true, Complete: true, TimeLeft: 10, RoundTime: 20, WrongGuesses: 2},
+			[]ScoreLine{{ScoreTitle, 60}, {ScoreArtist, 40}, {ScoreSpeed, 24}, {ScoreBoth, 30}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetScoringStrategy(tt.strategy).ScoreAnswer(tt.ctx)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ScoreAnswer = %v, attendu %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreMiss(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		ctx      ScoringContext
+		want     []ScoreLine
+	}{
+		{"classique", "classic", ScoringContext{WrongGuesses: 1, Score: 100}, nil},
+		{"premier trouveur", "first_finder", ScoringContext{WrongGuesses: 1, Score: 100}, nil},
+		{"série", "streak", ScoringContext{WrongGuesses: 1, Score: 100}, nil},
+		{"pénalité", "penalty", ScoringContext{WrongGuesses: 1, Score: 100}, []ScoreLine{{ScoreWrongGuess, -10}}},
+		{"troisième erreur", "penalty", ScoringContext{WrongGuesses: 3, Score: 100}, []ScoreLine{{ScoreWrongGuess, -10}}},
+		{"quatrième erreur", "penalty", ScoringContext{WrongGuesses: 4, Score: 100}, nil},
+		{"score presque nul", "penalty", ScoringContext{WrongGuesses: 1, Score: 4}, []ScoreLine{{ScoreWrongGuess, -4}}},
+		{"score nul", "penalty", ScoringContext{WrongGuesses: 1, Score: 0}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetScoringStrategy(tt.strategy).ScoreMiss(tt.ctx)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ScoreMiss = %v, attendu %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetScoringStrategy(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"", "classic"},
+		{"classic", "classic"},
+		{"first_finder", "first_finder"},
+		{"streak", "streak"},
+		{"penalty", "penalty"},
+	}
+
+	for _, tt := range tests {
+		if got := GetScoringStrategy(tt.name); got == nil || got.Name() != tt.want {
+			t.Errorf("GetScoringStrategy(%q) = %v, attendu %s", tt.name, got, tt.want)
+		}
+	}
+	if got := GetScoringStrategy("inconnu"); got != nil {
+		t.Errorf("GetScoringStrategy(inconnu) = %v, attendu nil", got)
+	}
+}
+
+func TestMaxAnswerPoints(t *testing.T) {
+	tests := []struct {
+		strategy string
+		want     int
+	}{
+		{"classic", 180},
+		{"first_finder", 210},
+		{"streak", 180},
+		{"penalty", 180},
+	}
+
+	for _, tt := range tests {
+		if got := maxAnswerPoints(GetScoringStrategy(tt.strategy)); got != tt.want {
+			t.Errorf("maxAnswerPoints(%s) = %d, attendu %d", tt.strategy, got, tt.want)
+		}
+	}
+}
+
+func TestDiscount(t *testing.T) {
+	lines := []ScoreLine{{ScoreTitle, 60}, {ScoreSpeed, 12}, {ScoreWrongGuess, -10}}
+
+	tests := []struct {
+		name    string
+		percent int
+		want    []ScoreLine
+	}{
+		{"sans remise", 0, lines},
+		{"moitié des gains", 50, append(slices.Clone(lines), ScoreLine{ScoreHints, -36})},
+		{"plafonnée à 100 %", 150, append(slices.Clone(lines), ScoreLine{ScoreHints, -72})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := discount(slices.Clone(lines), ScoreHints, tt.percent)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("discount = %v, attendu %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := discount([]ScoreLine{{ScoreWrongGuess, -10}}, ScoreHints, 50); len(got) != 1 {
+		t.Errorf("remise appliquée à une pénalité seule: %v", got)
+	}
+}
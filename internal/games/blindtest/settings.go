@@ -0,0 +1,262 @@
+package blindtest
+
+import (
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"groupie-tracker/internal/games"
+	"groupie-tracker/internal/models"
+)
+
+// Bornes des réglages qu'un hôte peut choisir (en secondes pour les durées).
+// La pause sépare la fin de la révélation du début de la manche suivante :
+// c'est aussi le temps laissé aux navigateurs pour charger l'extrait.
+const (
+	DefaultRounds     = 10
+	MinRounds         = 3
+	MaxRounds         = 30
+	MinRoundTime      = 10
+	MaxRoundTime      = 90
+	DefaultPauseTime  = 2
+	MinPauseTime      = 1
+	MaxPauseTime      = 10
+	DefaultRevealTime = 4
+	MinRevealTime     = 2
+	MaxRevealTime     = 15
+
+	DefaultStageGuesses = 2
+	MinStageGuesses     = 1
+	MaxStageGuesses     = 5
+	DefaultStageTime    = 6
+	MinStageTime        = 3
+	MaxStageTime        = 15
+
+	DefaultBuzzTime = 5
+	MinBuzzTime     = 3
+	MaxBuzzTime     = 15
+)
+
+// Settings regroupe les réglages d'une partie, lus dans la config de la salle.
+type Settings struct {
+	Rounds     int    `json:"rounds"`
+	RoundTime  int    `json:"round_time"`
+	PauseTime  int    `json:"pause_time"`
+	RevealTime int    `json:"reveal_time"`
+	Scoring    string `json:"scoring"`
+	AnswerMode string `json:"answer_mode"`
+	Hints      bool   `json:"hints"`
+	HintAt     []int  `json:"hint_at"`
+
+	// En mode progressif, la durée de manche vient des étapes (ClipStages et
+	// StageTime) : RoundTime ne s'applique pas et les indices sont refusés.
+	ClipMode     string `json:"clip_mode"`
+	StageGuesses int    `json:"stage_guesses"`
+	StageTime    int    `json:"stage_time"`
+
+	// BuzzTime est le temps de réponse après un buzz (mode buzzer).
+	BuzzTime int `json:"buzz_time"`
+}
+
+// SettingsFromConfig complète les champs absents (salles créées avant ces
+// réglages) avec les valeurs par défaut.
+func SettingsFromConfig(config models.GameConfig) Settings {
+	settings := Settings{
+		Rounds:     config.NbRounds,
+		RoundTime:  config.TimePerRound,
+		PauseTime:  config.PauseTime,
+		RevealTime: config.RevealTime,
+		Scoring:    config.Scoring,
+		AnswerMode: config.AnswerMode,
+		Hints:      config.Hints,
+		HintAt:     config.HintAt,
+
+		ClipMode:     config.ClipMode,
+		StageGuesses: config.StageGuesses,
+		StageTime:    config.StageTime,
+
+		BuzzTime: config.BuzzTime,
+	}
+	if settings.Rounds == 0 {
+		settings.Rounds = DefaultRounds
+	}
+	if settings.RoundTime == 0 {
+		settings.RoundTime = models.BlindTestDefaultTime
+	}
+	if settings.PauseTime == 0 {
+		settings.PauseTime = DefaultPauseTime
+	}
+	if settings.RevealTime == 0 {
+		settings.RevealTime = DefaultRevealTime
+	}
+	if settings.Scoring == "" {
+		settings.Scoring = DefaultScoring
+	}
+	if settings.AnswerMode == "" {
+		settings.AnswerMode = AnswerModeText
+	}
+	if len(settings.HintAt) == 0 {
+		settings.HintAt = append([]int(nil), DefaultHintAt...)
+	}
+	if settings.ClipMode == "" {
+		settings.ClipMode = ClipModeFull
+	}
+	if settings.StageGuesses == 0 {
+		settings.StageGuesses = DefaultStageGuesses
+	}
+	if settings.StageTime == 0 {
+		settings.StageTime = DefaultStageTime
+	}
+	if settings.BuzzTime == 0 {
+		settings.BuzzTime = DefaultBuzzTime
+	}
+	return settings
+}
+
+func (s Settings) Validate() error {
+	if s.Rounds < MinRounds || s.Rounds > MaxRounds {
+		return games.ErrInvalidConfig
+	}
+	if s.RoundTime < MinRoundTime || s.RoundTime > MaxRoundTime {
+		return games.ErrInvalidConfig
+	}
+	if s.PauseTime < MinPauseTime || s.PauseTime > MaxPauseTime {
+		return games.ErrInvalidConfig
+	}
+	if s.RevealTime < MinRevealTime || s.RevealTime > MaxRevealTime {
+		return games.ErrInvalidConfig
+	}
+	if GetScoringStrategy(s.Scoring) == nil {
+		return games.ErrInvalidConfig
+	}
+	if !validAnswerMode(s.AnswerMode) {
+		return games.ErrInvalidConfig
+	}
+	if !validHintAt(s.HintAt) {
+		return games.ErrInvalidConfig
+	}
+	if s.ClipMode != ClipModeFull && s.ClipMode != ClipModeProgressive {
+		return games.ErrInvalidConfig
+	}
+	if s.Hints && s.ClipMode == ClipModeProgressive {
+		return games.ErrInvalidConfig
+	}
+	if s.StageGuesses < MinStageGuesses || s.StageGuesses > MaxStageGuesses {
+		return games.ErrInvalidConfig
+	}
+	if s.StageTime < MinStageTime || s.StageTime > MaxStageTime {
+		return games.ErrInvalidConfig
+	}
+	if s.BuzzTime < MinBuzzTime || s.BuzzTime > MaxBuzzTime {
+		return games.ErrInvalidConfig
+	}
+	return nil
+}
+
+// parseSettings lit les champs bt_* du formulaire de création ou d'un
+// update_config. Comme pour le Petit Bac, une valeur hors bornes est ignorée.
+func parseSettings(form url.Values, config *models.GameConfig) {
+	if roundTime, ok := formInt(form, "bt_round_time", MinRoundTime, MaxRoundTime); ok {
+		config.TimePerRound = roundTime
+		log.Printf("[BlindTest] Temps par manche: %ds", roundTime)
+	}
+	if rounds, ok := formInt(form, "bt_round_count", MinRounds, MaxRounds); ok {
+		config.NbRounds = rounds
+		log.Printf("[BlindTest] Nombre de manches: %d", rounds)
+	}
+	if pause, ok := formInt(form, "bt_pause_time", MinPauseTime, MaxPauseTime); ok {
+		config.PauseTime = pause
+		log.Printf("[BlindTest] Pause entre les manches: %ds", pause)
+	}
+	if reveal, ok := formInt(form, "bt_reveal_time", MinRevealTime, MaxRevealTime); ok {
+		config.RevealTime = reveal
+		log.Printf("[BlindTest] Durée de la révélation: %ds", reveal)
+	}
+	if scoring := form.Get("bt_scoring"); scoring != "" && GetScoringStrategy(scoring) != nil {
+		config.Scoring = scoring
+		log.Printf("[BlindTest] Barème: %s", scoring)
+	}
+	if mode := form.Get("bt_answer_mode"); validAnswerMode(mode) {
+		config.AnswerMode = mode
+		log.Printf("[BlindTest] Mode de réponse: %s", mode)
+	}
+	if values, sent := form["bt_hints"]; sent && len(values) > 0 {
+		config.Hints = formBool(values[len(values)-1])
+		log.Printf("[BlindTest] Indices: %v", config.Hints)
+	}
+	if hintAt, ok := parseHintAt(form.Get("bt_hint_at")); ok {
+		config.HintAt = hintAt
+		log.Printf("[BlindTest] Moments des indices: %v%%", hintAt)
+	}
+	if mode := form.Get("bt_clip_mode"); mode == ClipModeFull || mode == ClipModeProgressive {
+		config.ClipMode = mode
+		log.Printf("[BlindTest] Mode d'écoute: %s", mode)
+	}
+	if guesses, ok := formInt(form, "bt_stage_guesses", MinStageGuesses, MaxStageGuesses); ok {
+		config.StageGuesses = guesses
+		log.Printf("[BlindTest] Essais par étape: %d", guesses)
+	}
+	if stageTime, ok := formInt(form, "bt_stage_time", MinStageTime, MaxStageTime); ok {
+		config.StageTime = stageTime
+		log.Printf("[BlindTest] Temps de réponse par étape: %ds", stageTime)
+	}
+	if buzzTime, ok := formInt(form, "bt_buzz_time", MinBuzzTime, MaxBuzzTime); ok {
+		config.BuzzTime = buzzTime
+		log.Printf("[BlindTest] Temps de réponse au buzzer: %ds", buzzTime)
+	}
+}
+
+func validAnswerMode(mode string) bool {
+	return mode == AnswerModeText || mode == AnswerModeChoices || mode == AnswerModeBuzzer
+}
+
+// formBool accepte une case à cocher ("on") comme un booléen JSON.
+func formBool(value string) bool {
+	switch strings.ToLower(value) {
+	case "on", "true", "1":
+		return true
+	}
+	return false
+}
+
+// parseHintAt lit les moments des indices, en pourcentage de la manche
+// écoulée : "20,40,55,70,85".
+func parseHintAt(value string) ([]int, bool) {
+	if strings.TrimSpace(value) == "" {
+		return nil, false
+	}
+	parts := strings.Split(value, ",")
+	hintAt := make([]int, 0, len(parts))
+	for _, part := range parts {
+		percent, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, false
+		}
+		hintAt = append(hintAt, percent)
+	}
+	return hintAt, validHintAt(hintAt)
+}
+
+// validHintAt exige un moment par indice, croissants, entre 5 et 95 %.
+func validHintAt(hintAt []int) bool {
+	if len(hintAt) != len(hintKinds) {
+		return false
+	}
+	previous := 0
+	for _, percent := range hintAt {
+		if percent < 5 || percent > 95 || percent <= previous {
+			return false
+		}
+		previous = percent
+	}
+	return true
+}
+
+func formInt(form url.Values, key string, minValue, maxValue int) (int, bool) {
+	value, err := strconv.Atoi(form.Get(key))
+	if err != nil || value < minValue || value > maxValue {
+		return 0, false
+	}
+	return value, true
+}
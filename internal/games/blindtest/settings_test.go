@@ -0,0 +1,125 @@
+package blindtest
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"groupie-tracker/internal/games"
+	"groupie-tracker/internal/models"
+)
+
+func TestSettingsFromConfigDefaults(t *testing.T) {
+	settings := SettingsFromConfig(models.GameConfig{})
+	want := Settings{
+		Rounds:     DefaultRounds,
+		RoundTime:  models.BlindTestDefaultTime,
+		PauseTime:  DefaultPauseTime,
+		RevealTime: DefaultRevealTime,
+		Scoring:    DefaultScoring,
+		AnswerMode: AnswerModeText,
+		HintAt:     DefaultHintAt,
+
+		ClipMode:     ClipModeFull,
+		StageGuesses: DefaultStageGuesses,
+		StageTime:    DefaultStageTime,
+
+		BuzzTime: DefaultBuzzTime,
+	}
+	if !reflect.DeepEqual(settings, want) {
+		t.Errorf("réglages par défaut = %+v, attendu %+v", settings, want)
+	}
+	if err := settings.Validate(); err != nil {
+		t.Errorf("réglages par défaut refusés: %v", err)
+	}
+}
+
+func TestSettingsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(*Settings)
+		wantErr bool
+	}{
+		{"défaut", func(s *Settings) {}, false},
+		{"manches au minimum", func(s *Settings) { s.Rounds = MinRounds }, false},
+		{"manches au maximum", func(s *Settings) { s.Rounds = MaxRounds }, false},
+		{"trop peu de manches", func(s *Settings) { s.Rounds = MinRounds - 1 }, true},
+		{"trop de manches", func(s *Settings) { s.Rounds = MaxRounds + 1 }, true},
+		{"manche trop courte", func(s *Settings) { s.RoundTime = MinRoundTime - 1 }, true},
+		{"manche trop longue", func(s *Settings) { s.RoundTime = MaxRoundTime + 1 }, true},
+		{"pause au maximum", func(s *Settings) { s.PauseTime = MaxPauseTime }, false},
+		{"pause nulle", func(s *Settings) { s.PauseTime = 0 }, true},
+		{"pause trop longue", func(s *Settings) { s.PauseTime = MaxPauseTime + 1 }, true},
+		{"révélation trop courte", func(s *Settings) { s.RevealTime = MinRevealTime - 1 }, true},
+		{"révélation trop longue", func(s *Settings) { s.RevealTime = MaxRevealTime + 1 }, true},
+		{"barème connu", func(s *Settings) { s.Scoring = "streak" }, false},
+		{"barème inconnu", func(s *Settings) { s.Scoring = "inconnu" }, true},
+		{"choix multiple", func(s *Settings) { s.AnswerMode = AnswerModeChoices }, false},
+		{"mode de réponse inconnu", func(s *Settings) { s.AnswerMode = "inconnu" }, true},
+		{"indices", func(s *Settings) { s.Hints = true }, false},
+		{"moments des indices décroissants", func(s *Settings) { s.HintAt = []int{20, 40, 30, 70, 85} }, true},
+		{"indice manquant", func(s *Settings) { s.HintAt = []int{20, 40, 55, 70} }, true},
+		{"écoute progressive", func(s *Settings) { s.ClipMode = ClipModeProgressive }, false},
+		{"écoute inconnue", func(s *Settings) { s.ClipMode = "inconnu" }, true},
+		{"trop d'essais par étape", func(s *Settings) { s.StageGuesses = MaxStageGuesses + 1 }, true},
+		{"étape trop courte", func(s *Settings) { s.StageTime = MinStageTime - 1 }, true},
+		{"buzzer", func(s *Settings) { s.AnswerMode = AnswerModeBuzzer }, false},
+		{"temps de buzz trop court", func(s *Settings) { s.BuzzTime = MinBuzzTime - 1 }, true},
+		{"temps de buzz trop long", func(s *Settings) { s.BuzzTime = MaxBuzzTime + 1 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := SettingsFromConfig(models.GameConfig{})
+			tt.edit(&settings)
+			err := settings.Validate()
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Validate() = %v, erreur attendue: %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, games.ErrInvalidConfig) {
+				t.Errorf("Validate() = %v, attendu %v", err, games.ErrInvalidConfig)
+			}
+		})
+	}
+}
+
+func TestParseSettings(t *testing.T) {
+	tests := []struct {
+		name string
+		form url.Values
+		want models.GameConfig
+	}{
+		{
+			"valeurs valides",
+			url.Values{"bt_round_time": {"45"}, "bt_round_count": {"15"}, "bt_pause_time": {"5"}, "bt_reveal_time": {"8"}},
+			models.GameConfig{TimePerRound: 45, NbRounds: 15, PauseTime: 5, RevealTime: 8},
+		},
+		{
+			"bornes incluses",
+			url.Values{"bt_round_time": {"10"}, "bt_round_count": {"30"}, "bt_pause_time": {"1"}, "bt_reveal_time": {"15"}},
+			models.GameConfig{TimePerRound: 10, NbRounds: 30, PauseTime: 1, RevealTime: 15},
+		},
+		{
+			"hors bornes ignorées",
+			url.Values{"bt_round_time": {"9"}, "bt_round_count": {"31"}, "bt_pause_time": {"0"}, "bt_reveal_time": {"16"}},
+			models.GameConfig{TimePerRound: 30, NbRounds: 10, PauseTime: 2, RevealTime: 4},
+		},
+		{
+			"valeurs illisibles ignorées",
+			url.Values{"bt_round_time": {"abc"}, "bt_round_count": {""}, "bt_pause_time": {"2.5"}},
+			models.GameConfig{TimePerRound: 30, NbRounds: 10, PauseTime: 2, RevealTime: 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := models.GameConfig{TimePerRound: 30, NbRounds: 10, PauseTime: 2, RevealTime: 4}
+			parseSettings(tt.form, &config)
+			if config.TimePerRound != tt.want.TimePerRound || config.NbRounds != tt.want.NbRounds ||
+				config.PauseTime != tt.want.PauseTime || config.RevealTime != tt.want.RevealTime {
+				t.Errorf("config = %+v, attendu %+v", config, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package games
+
+import (
+	"errors"
+	"log"
+	"net/url"
+	"sync"
+
+	"groupie-tracker/internal/models"
+)
+
+var (
+	ErrUnknownGame   = errors.New("type de jeu inconnu")
+	ErrInvalidConfig = errors.New("configuration de jeu invalide")
+	ErrNoHandler     = errors.New("le moteur ne traite pas ses messages")
+)
+
+// Engine est implémenté par chaque jeu. Les handlers WebSocket, la création
+// de salle et main.go ne connaissent que cette interface.
+// Le routage des messages passe par une méthode
+// HandleMessage(*websocket.Client, *models.WSMessage) : elle ne peut pas
+// figurer ici sans cycle d'import, c'est l'interface optionnelle
+// websocket.EngineMessageHandler. Un moteur qui déclare des MessageTypes doit
+// l'implémenter ; le paquet websocket le vérifie à l'enregistrement (AddCheck)
+// et les messages d'un moteur refusé ne sont pas routés.
+// Snapshot renvoie l'état de la partie en cours vu par un joueur (nil si
+// aucune partie n'est en cours) ; il est envoyé à la reconnexion.
+type Engine interface {
+	GameType() models.GameType
+	DisplayName() string
+	MessageTypes() []models.WSMessageType
+	Template() string
+	DefaultConfig() models.GameConfig
+	ParseConfig(form url.Values, config *models.GameConfig)
+	ValidateConfig(config *models.GameConfig) error
+	Start(room *models.Room, options map[string]interface{}) error
+	Stop(roomID string)
+	Snapshot(roomID string, userID int64) map[string]interface{}
+}
+
+// DisconnectHandler est implémenté par les moteurs qui réagissent à la perte
+// de connexion d'un joueur pendant la partie. Le joueur garde sa place
+// pendant le délai de grâce ; le moteur est prévenu dès la déconnexion.
+type DisconnectHandler interface {
+	PlayerDisconnected(roomID string, userID int64)
+}
+
+type Registry struct {
+	engines  map[models.GameType]Engine
+	order    []models.GameType
+	messages map[models.GameType]map[models.WSMessageType]bool
+	checks   []func(Engine) error
+	mutex    sync.RWMutex
+}
+
+var (
+	registryInstance *Registry
+	registryOnce     sync.Once
+)
+
+func NewRegistry() *Registry {
+	return &Registry{
+		engines:  make(map[models.GameType]Engine),
+		messages: make(map[models.GameType]map[models.WSMessageType]bool),
+	}
+}
+
+func GetRegistry() *Registry {
+	registryOnce.Do(func() {
+		registryInstance = NewRegistry()
+	})
+	return registryInstance
+}
+
+func (r *Registry) Register(engine Engine) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	gameType := engine.GameType()
+	if _, exists := r.engines[gameType]; !exists {
+		r.order = append(r.order, gameType)
+	}
+	r.engines[gameType] = engine
+
+	r.messages[gameType] = r.messageTypesLocked(engine)
+
+	log.Printf("[Games] Moteur enregistré: %s (%d types de messages)", gameType, len(r.messages[gameType]))
+}
+
+// AddCheck ajoute une vérification appliquée aux moteurs déjà enregistrés et
+// aux suivants. Un moteur qui échoue reste listé mais aucun de ses messages
+// n'est routé.
+func (r *Registry) AddCheck(check func(Engine) error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.checks = append(r.checks, check)
+	for gameType, engine := range r.engines {
+		r.messages[gameType] = r.messageTypesLocked(engine)
+	}
+}
+
+func (r *Registry) messageTypesLocked(engine Engine) map[models.WSMessageType]bool {
+	types := make(map[models.WSMessageType]bool)
+	for _, check := range r.checks {
+		if err := check(engine); err != nil {
+			log.Printf("[Games] ❌ Moteur %s: %v, ses messages %v ne seront pas traités", engine.GameType(), err, engine.MessageTypes())
+			return types
+		}
+	}
+	for _, msgType := range engine.MessageTypes() {
+		types[msgType] = true
+	}
+	return types
+}
+
+func (r *Registry) Get(gameType models.GameType) (Engine, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	engine, exists := r.engines[gameType]
+	if !exists {
+		return nil, ErrUnknownGame
+	}
+	return engine, nil
+}
+
+func (r *Registry) All() []Engine {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	engines := make([]Engine, 0, len(r.order))
+	for _, gameType := range r.order {
+		engines = append(engines, r.engines[gameType])
+	}
+	return engines
+}
+
+func (r *Registry) Handles(gameType models.GameType, msgType models.WSMessageType) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.messages[gameType][msgType]
+}
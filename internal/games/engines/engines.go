@@ -0,0 +1,49 @@
+// Package engines construit les moteurs de jeu au démarrage. Chaque jeu
+// inscrit sa fabrique dans son init() ; main importe le paquet games/all et
+// appelle RegisterAll, sans rien savoir des jeux disponibles.
+package engines
+
+import (
+	"sync"
+
+	"groupie-tracker/internal/games"
+	"groupie-tracker/internal/media"
+	"groupie-tracker/internal/rooms"
+	"groupie-tracker/internal/websocket"
+)
+
+// Deps regroupe les services partagés dont un moteur peut avoir besoin.
+type Deps struct {
+	Rooms    *rooms.Manager
+	Hub      *websocket.Hub
+	Previews *media.PreviewProxy
+}
+
+// Factory construit le moteur d'un jeu à partir des services partagés.
+type Factory func(deps Deps) games.Engine
+
+var (
+	factories []Factory
+	mutex     sync.Mutex
+)
+
+// Add inscrit la fabrique d'un jeu. Elle est appelée depuis l'init() du
+// paquet du jeu ; les moteurs sont enregistrés dans l'ordre d'inscription.
+func Add(factory Factory) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	factories = append(factories, factory)
+}
+
+// RegisterAll construit chaque moteur inscrit et l'enregistre dans registry.
+// Il renvoie le nombre de moteurs enregistrés.
+func RegisterAll(registry *games.Registry, deps Deps) int {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for _, factory := range factories {
+		registry.Register(factory(deps))
+	}
+	return len(factories)
+}
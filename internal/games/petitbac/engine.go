@@ -0,0 +1,167 @@
+package petitbac
+
+import (
+	"log"
+	"net/url"
+	"strconv"
+
+	"groupie-tracker/internal/games"
+	"groupie-tracker/internal/games/engines"
+	"groupie-tracker/internal/models"
+)
+
+func init() {
+	engines.Add(func(deps engines.Deps) games.Engine {
+		return NewHandler(NewGameManager(deps.Rooms), deps.Rooms, deps.Hub)
+	})
+}
+
+func (h *Handler) GameType() models.GameType {
+	return models.GameTypePetitBac
+}
+
+func (h *Handler) DisplayName() string {
+	return "Petit Bac Musical"
+}
+
+func (h *Handler) MessageTypes() []models.WSMessageType {
+	return []models.WSMessageType{
+		models.WSTypePBSubmitAnswers,
+		models.WSTypePBStopRound,
+		models.WSTypePBSubmitVotes,
+	}
+}
+
+func (h *Handler) Template() string {
+	return "room_petitbac.html"
+}
+
+func (h *Handler) DefaultConfig() models.GameConfig {
+	return models.GameConfig{
+		Categories:   models.DefaultPetitBacCategories,
+		TimePerRound: DefaultAnswerTime,
+		NbRounds:     models.NbrsManche,
+		UsedLetters:  []string{},
+	}
+}
+
+func (h *Handler) ParseConfig(form url.Values, config *models.GameConfig) {
+	categories := form["categories"]
+	if len(categories) >= MinCategories {
+		config.Categories = categories
+		log.Printf("[PetitBac] Catégories personnalisées: %v", categories)
+	}
+
+	if roundTime, err := strconv.Atoi(form.Get("round_time")); err == nil && roundTime >= MinAnswerTime && roundTime <= MaxAnswerTime {
+		config.TimePerRound = roundTime
+		log.Printf("[PetitBac] Temps par manche: %ds", roundTime)
+	}
+
+	if roundCount, err := strconv.Atoi(form.Get("round_count")); err == nil && roundCount >= MinRounds && roundCount <= MaxRounds {
+		config.NbRounds = roundCount
+		log.Printf("[PetitBac] Nombre de manches: %d", roundCount)
+	}
+
+	log.Printf("[PetitBac] Config: %d catégories, %ds/manche, %d manches",
+		len(config.Categories), config.TimePerRound, config.NbRounds)
+}
+
+func (h *Handler) ValidateConfig(config *models.GameConfig) error {
+	if len(config.Categories) < MinCategories {
+		return games.ErrInvalidConfig
+	}
+	if config.NbRounds <= 0 || config.NbRounds > len(AvailableLetters) {
+		return games.ErrInvalidConfig
+	}
+	if config.TimePerRound <= 0 {
+		return games.ErrInvalidConfig
+	}
+	return nil
+}
+
+func (h *Handler) Start(room *models.Room, options map[string]interface{}) error {
+	room.Mutex.RLock()
+	categories := room.Config.Categories
+	rounds := room.Config.NbRounds
+	room.Mutex.RUnlock()
+
+	if len(categories) == 0 {
+		categories = models.DefaultPetitBacCategories
+	}
+	if rounds <= 0 {
+		rounds = models.NbrsManche
+	}
+
+	if err := h.StartGame(room.Code, categories, rounds); err != nil {
+		return err
+	}
+
+	log.Printf("[PetitBac] ✅ PetitBac démarré: categories=%v, rounds=%d", categories, rounds)
+	return nil
+}
+
+func (h *Handler) Stop(roomID string) {
+	h.mutex.Lock()
+	delete(h.stopTimers, roomID)
+	h.mutex.Unlock()
+
+	h.gameManager.StopGame(roomID)
+
+	room, err := h.roomManager.GetRoom(roomID)
+	if err != nil {
+		return
+	}
+
+	room.Mutex.Lock()
+	room.Config.UsedLetters = []string{}
+	room.Mutex.Unlock()
+}
+
+func (h *Handler) Snapshot(roomID string, userID int64) map[string]interface{} {
+	state := h.gameManager.GetGameState(roomID)
+	if state == nil {
+		return nil
+	}
+
+	state.Mutex.RLock()
+	snapshot := map[string]interface{}{
+		"game_type":     models.GameTypePetitBac,
+		"phase":         state.Phase,
+		"round":         state.CurrentRound,
+		"total":         state.TotalRounds,
+		"letter":        state.CurrentLetter,
+		"categories":    state.Categories,
+		"time_left":     state.TimeLeft,
+		"duration":      state.RoundDuration,
+		"answers":       state.Answers[userID],
+		"has_submitted": state.HasSubmitted[userID],
+	}
+
+	var allAnswers map[string]map[int64]string
+	if state.Phase == PhaseVoting {
+		// Les votes sont stockés par cible : on reconstruit ceux du joueur
+		// sous la même clé "userID_catégorie" que le formulaire de vote.
+		rejected := make(map[string]bool)
+		for targetID, categories := range state.Votes {
+			for category, voters := range categories {
+				for _, voterID := range voters {
+					if voterID == userID {
+						rejected[strconv.FormatInt(targetID, 10)+"_"+category] = true
+					}
+				}
+			}
+		}
+
+		allAnswers = collectAnswers(state)
+		snapshot["vote_duration"] = VoteTime
+		snapshot["has_voted"] = state.HasVoted[userID]
+		snapshot["rejected"] = rejected
+	}
+	state.Mutex.RUnlock()
+
+	if allAnswers != nil {
+		snapshot["voting_answers"] = h.votingAnswers(roomID, allAnswers)
+	}
+	snapshot["scores"] = h.gameManager.GetScores(roomID)
+	return snapshot
+}
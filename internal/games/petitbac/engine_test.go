@@ -0,0 +1,128 @@
+package petitbac
+
+import (
+	"reflect"
+	"testing"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/rooms"
+)
+
+const testRoomID = "salle-test"
+
+// newTestHandler restaure une salle de deux joueurs, Alice (1) et Bob (2),
+// et y installe une manche en cours sur la lettre B.
+func newTestHandler(t *testing.T, phase GamePhase) (*Handler, *GameState) {
+	t.Helper()
+	store := rooms.NewMemoryStore()
+	room := &models.Room{
+		ID:       testRoomID,
+		Code:     "PBTEST",
+		Name:     "Salle de test",
+		HostID:   1,
+		GameType: models.GameTypePetitBac,
+		Status:   models.RoomStatusWaiting,
+		Players: map[int64]*models.Player{
+			1: {UserID: 1, Pseudo: "Alice", Score: 12, IsHost: true},
+			2: {UserID: 2, Pseudo: "Bob", Score: 7},
+		},
+	}
+	if err := store.SaveRoom(room); err != nil {
+		t.Fatalf("SaveRoom: %v", err)
+	}
+	if err := store.SaveRoomPlayers(room); err != nil {
+		t.Fatalf("SaveRoomPlayers: %v", err)
+	}
+	roomManager := rooms.NewManager(store, store)
+	if err := roomManager.RestoreRooms(); err != nil {
+		t.Fatalf("RestoreRooms: %v", err)
+	}
+
+	state := &GameState{
+		RoomID:        testRoomID,
+		CurrentRound:  2,
+		TotalRounds:   5,
+		CurrentLetter: "B",
+		Categories:    []string{"artiste", "album"},
+		Answers: map[int64]map[string]string{
+			1: {"artiste": "Beyoncé", "album": "Blonde"},
+			2: {"artiste": "Blur"},
+		},
+		HasSubmitted:  map[int64]bool{1: true},
+		Votes:         map[int64]map[string][]int64{2: {"artiste": {1}}},
+		HasVoted:      map[int64]bool{1: true},
+		RoundScores:   make(map[int64][]int),
+		TimeLeft:      20,
+		RoundDuration: DefaultAnswerTime,
+		Phase:         phase,
+	}
+	gameManager := &GameManager{
+		games:       map[string]*GameState{testRoomID: state},
+		roomManager: roomManager,
+	}
+	return &Handler{gameManager: gameManager, roomManager: roomManager}, state
+}
+
+func TestSnapshot(t *testing.T) {
+	tests := []struct {
+		name         string
+		phase        GamePhase
+		userID       int64
+		wantSubmit   bool
+		wantVoted    bool
+		wantRejected map[string]bool
+	}{
+		{"réponses, joueur ayant envoyé", PhaseAnswering, 1, true, false, nil},
+		{"réponses, joueur en train d'écrire", PhaseAnswering, 2, false, false, nil},
+		{"vote, votant", PhaseVoting, 1, true, true, map[string]bool{"2_artiste": true}},
+		{"vote, sans vote", PhaseVoting, 2, false, false, map[string]bool{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, state := newTestHandler(t, tt.phase)
+			snapshot := h.Snapshot(testRoomID, tt.userID)
+			if snapshot == nil {
+				t.Fatal("aucun instantané pour une partie en cours")
+			}
+
+			if snapshot["phase"] != tt.phase || snapshot["round"] != 2 || snapshot["total"] != 5 || snapshot["letter"] != "B" {
+				t.Errorf("manche = %v", snapshot)
+			}
+			if answers := snapshot["answers"]; !reflect.DeepEqual(answers, state.Answers[tt.userID]) {
+				t.Errorf("réponses = %v, attendu celles du joueur %v", answers, state.Answers[tt.userID])
+			}
+			if snapshot["has_submitted"] != tt.wantSubmit {
+				t.Errorf("has_submitted = %v, attendu %v", snapshot["has_submitted"], tt.wantSubmit)
+			}
+			if scores, _ := snapshot["scores"].([]PlayerScore); len(scores) != 2 {
+				t.Errorf("scores = %v", snapshot["scores"])
+			}
+
+			if tt.phase != PhaseVoting {
+				for _, key := range []string{"has_voted", "rejected", "voting_answers"} {
+					if _, exists := snapshot[key]; exists {
+						t.Errorf("%s envoyé hors phase de vote", key)
+					}
+				}
+				return
+			}
+			if snapshot["has_voted"] != tt.wantVoted {
+				t.Errorf("has_voted = %v, attendu %v", snapshot["has_voted"], tt.wantVoted)
+			}
+			if rejected := snapshot["rejected"]; !reflect.DeepEqual(rejected, tt.wantRejected) {
+				t.Errorf("rejets = %v, attendu %v", rejected, tt.wantRejected)
+			}
+			if answers, _ := snapshot["voting_answers"].([]map[string]interface{}); len(answers) != 3 {
+				t.Errorf("réponses à voter = %v, attendu 3", snapshot["voting_answers"])
+			}
+		})
+	}
+}
+
+func TestSnapshotWithoutGame(t *testing.T) {
+	h, _ := newTestHandler(t, PhaseAnswering)
+	if snapshot := h.Snapshot("autre-salle", 1); snapshot != nil {
+		t.Errorf("instantané sans partie = %v, attendu nil", snapshot)
+	}
+}
@@ -26,7 +26,9 @@ type GameState struct {
 	Answers        map[int64]map[string]string  `json:"answers"`
 	HasSubmitted   map[int64]bool               `json:"has_submitted"`
 	Votes          map[int64]map[string][]int64 `json:"votes"`
+	HasVoted       map[int64]bool               `json:"has_voted"`
 	RoundStoppedBy int64                        `json:"round_stopped_by"`
+	RoundScores    map[int64][]int              `json:"round_scores"`
 	TimeLeft       int                          `json:"time_left"`
 	RoundDuration  int                          `json:"round_duration"`
 	Phase          GamePhase                    `json:"phase"`
@@ -45,6 +47,11 @@ const (
 
 const (
 	DefaultAnswerTime = 60
+	MinAnswerTime     = 30
+	MaxAnswerTime     = 120
+	MinCategories     = 3
+	MinRounds         = 3
+	MaxRounds         = 15
 	VoteTime          = 30
 )
 
@@ -54,19 +61,11 @@ type GameManager struct {
 	roomManager *rooms.Manager
 }
 
-var (
-	gameManagerInstance *GameManager
-	gameManagerOnce     sync.Once
-)
-
-func GetGameManager() *GameManager {
-	gameManagerOnce.Do(func() {
-		gameManagerInstance = &GameManager{
-			games:       make(map[string]*GameState),
-			roomManager: rooms.GetManager(),
-		}
-	})
-	return gameManagerInstance
+func NewGameManager(roomManager *rooms.Manager) *GameManager {
+	return &GameManager{
+		games:       make(map[string]*GameState),
+		roomManager: roomManager,
+	}
 }
 
 func (gm *GameManager) StartGame(roomID string, categories []string, rounds int) (*GameState, error) {
@@ -93,6 +92,7 @@ func (gm *GameManager) StartGameWithDuration(roomID string, categories []string,
 		Categories:    categories,
 		UsedLetters:   []string{},
 		RoundDuration: duration,
+		RoundScores:   make(map[int64][]int),
 		Phase:         PhaseWaiting,
 	}
 
@@ -135,6 +135,7 @@ func (gm *GameManager) NextRound(roomID string) (*RoundInfo, error) {
 	state.Answers = make(map[int64]map[string]string)
 	state.HasSubmitted = make(map[int64]bool)
 	state.Votes = make(map[int64]map[string][]int64)
+	state.HasVoted = make(map[int64]bool)
 	state.RoundStoppedBy = 0
 	state.Phase = PhaseAnswering
 
@@ -241,6 +242,18 @@ func (gm *GameManager) StartVoting(roomID string) *VotingInfo {
 	state.Phase = PhaseVoting
 	state.TimeLeft = VoteTime
 
+	log.Printf("[PetitBac] Phase de vote démarrée")
+
+	return &VotingInfo{
+		Answers:    collectAnswers(state),
+		Duration:   VoteTime,
+		Categories: state.Categories,
+	}
+}
+
+// collectAnswers regroupe les réponses non vides par catégorie.
+// L'appelant doit détenir state.Mutex.
+func collectAnswers(state *GameState) map[string]map[int64]string {
 	allAnswers := make(map[string]map[int64]string)
 	for _, cat := range state.Categories {
 		allAnswers[cat] = make(map[int64]string)
@@ -250,14 +263,7 @@ func (gm *GameManager) StartVoting(roomID string) *VotingInfo {
 			}
 		}
 	}
-
-	log.Printf("[PetitBac] Phase de vote démarrée")
-
-	return &VotingInfo{
-		Answers:    allAnswers,
-		Duration:   VoteTime,
-		Categories: state.Categories,
-	}
+	return allAnswers
 }
 
 type VotingInfo struct {
@@ -279,6 +285,8 @@ func (gm *GameManager) SubmitVote(roomID string, voterID int64, targetUserID int
 		return nil
 	}
 
+	state.HasVoted[voterID] = true
+
 	if voterID == targetUserID {
 		return nil
 	}
@@ -312,6 +320,10 @@ func (gm *GameManager) CalculateRoundScores(roomID string) *RoundScores {
 
 	room.Mutex.RLock()
 	totalPlayers := len(room.Players)
+	playerIDs := make([]int64, 0, len(room.Players))
+	for userID := range room.Players {
+		playerIDs = append(playerIDs, userID)
+	}
 	room.Mutex.RUnlock()
 
 	scores := make(map[int64]int)
@@ -361,6 +373,10 @@ func (gm *GameManager) CalculateRoundScores(roomID string) *RoundScores {
 		gm.roomManager.AddPlayerScore(roomID, userID, pts)
 	}
 
+	for _, userID := range playerIDs {
+		state.RoundScores[userID] = append(state.RoundScores[userID], scores[userID])
+	}
+
 	log.Printf("[PetitBac] Scores de la manche calculés")
 
 	return &RoundScores{
@@ -446,6 +462,8 @@ func (gm *GameManager) EndGame(roomID string) *GameResult {
 	delete(gm.games, roomID)
 	gm.mutex.Unlock()
 
+	gm.saveGameScores(roomID, state)
+
 	log.Printf("[PetitBac] Partie terminée dans la salle %s", roomID)
 
 	winner := ""
@@ -459,11 +477,31 @@ func (gm *GameManager) EndGame(roomID string) *GameResult {
 	}
 }
 
+func (gm *GameManager) saveGameScores(roomID string, state *GameState) {
+	state.Mutex.RLock()
+	defer state.Mutex.RUnlock()
+
+	if err := gm.roomManager.RecordGameScores(roomID, state.RoundScores); err != nil {
+		log.Printf("[PetitBac] Erreur sauvegarde scores: %v", err)
+	}
+}
+
 type GameResult struct {
 	Scores []PlayerScore `json:"scores"`
 	Winner string        `json:"winner"`
 }
 
+func (gm *GameManager) StopGame(roomID string) {
+	gm.mutex.Lock()
+	_, exists := gm.games[roomID]
+	delete(gm.games, roomID)
+	gm.mutex.Unlock()
+
+	if exists {
+		log.Printf("[PetitBac] Partie interrompue dans la salle %s", roomID)
+	}
+}
+
 func (gm *GameManager) IsGameOver(roomID string) bool {
 	state := gm.GetGameState(roomID)
 	if state == nil {
@@ -491,13 +529,20 @@ func (gm *GameManager) AllPlayersSubmitted(roomID string) bool {
 	room.Mutex.RLock()
 	defer room.Mutex.RUnlock()
 
-	for userID := range room.Players {
+	// Les joueurs déconnectés, même pendant leur délai de grâce, ne retiennent
+	// pas la manche.
+	connected := 0
+	for userID, player := range room.Players {
+		if !player.Connected {
+			continue
+		}
+		connected++
 		if !state.HasSubmitted[userID] {
 			return false
 		}
 	}
 
-	return true
+	return connected > 0
 }
 
 func (gm *GameManager) AnyPlayerFilledAll(roomID string) (bool, int64) {
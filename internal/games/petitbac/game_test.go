@@ -0,0 +1,36 @@
+package petitbac
+
+import "testing"
+
+func TestAllPlayersSubmitted(t *testing.T) {
+	// Alice (1) a envoyé ses réponses, Bob (2) non.
+	tests := []struct {
+		name      string
+		connected map[int64]bool
+		submitted map[int64]bool
+		want      bool
+	}{
+		{"Bob écrit encore", map[int64]bool{1: true, 2: true}, nil, false},
+		{"Bob déconnecté", map[int64]bool{1: true, 2: false}, nil, true},
+		{"tout le monde a envoyé", map[int64]bool{1: true, 2: true}, map[int64]bool{2: true}, true},
+		{"personne en ligne", map[int64]bool{1: false, 2: false}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, state := newTestHandler(t, PhaseAnswering)
+			for userID, connected := range tt.connected {
+				if err := h.roomManager.SetPlayerConnected(testRoomID, userID, connected); err != nil {
+					t.Fatalf("SetPlayerConnected: %v", err)
+				}
+			}
+			for userID := range tt.submitted {
+				state.HasSubmitted[userID] = true
+			}
+
+			if got := h.gameManager.AllPlayersSubmitted(testRoomID); got != tt.want {
+				t.Errorf("AllPlayersSubmitted = %v, attendu %v", got, tt.want)
+			}
+		})
+	}
+}
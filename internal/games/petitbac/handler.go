@@ -21,21 +21,13 @@ type Handler struct {
 	mutex       sync.Mutex
 }
 
-var (
-	handlerInstance *Handler
-	handlerOnce     sync.Once
-)
-
-func GetHandler() *Handler {
-	handlerOnce.Do(func() {
-		handlerInstance = &Handler{
-			gameManager: GetGameManager(),
-			roomManager: rooms.GetManager(),
-			hub:         websocket.GetHub(),
-			stopTimers:  make(map[string]chan bool),
-		}
-	})
-	return handlerInstance
+func NewHandler(gameManager *GameManager, roomManager *rooms.Manager, hub *websocket.Hub) *Handler {
+	return &Handler{
+		gameManager: gameManager,
+		roomManager: roomManager,
+		hub:         hub,
+		stopTimers:  make(map[string]chan bool),
+	}
 }
 
 func (h *Handler) HandleMessage(client *websocket.Client, msg *models.WSMessage) {
@@ -388,8 +380,25 @@ func (h *Handler) startVotingPhase(roomID, roomCode string) {
 		return
 	}
 
+	answers := h.votingAnswers(roomID, votingInfo.Answers)
+
+	log.Printf("[PetitBac] 🗳️ Phase de vote - %d réponses à valider", len(answers))
+
+	h.hub.Broadcast(roomCode, &models.WSMessage{
+		Type: "voting_start",
+		Payload: map[string]interface{}{
+			"answers":    answers,
+			"duration":   votingInfo.Duration,
+			"categories": votingInfo.Categories,
+		},
+	})
+
+	go h.runVotingTimer(roomID, roomCode, votingInfo.Duration)
+}
+
+func (h *Handler) votingAnswers(roomID string, allAnswers map[string]map[int64]string) []map[string]interface{} {
 	var answers []map[string]interface{}
-	for category, userAnswers := range votingInfo.Answers {
+	for category, userAnswers := range allAnswers {
 		for userID, answer := range userAnswers {
 			player, _ := h.roomManager.GetPlayer(roomID, userID)
 			pseudo := "Inconnu"
@@ -405,28 +414,27 @@ func (h *Handler) startVotingPhase(roomID, roomCode string) {
 			})
 		}
 	}
-
-	log.Printf("[PetitBac] 🗳️ Phase de vote - %d réponses à valider", len(answers))
-
-	h.hub.Broadcast(roomCode, &models.WSMessage{
-		Type: "voting_start",
-		Payload: map[string]interface{}{
-			"answers":    answers,
-			"duration":   votingInfo.Duration,
-			"categories": votingInfo.Categories,
-		},
-	})
-
-	go h.runVotingTimer(roomID, roomCode, votingInfo.Duration)
+	return answers
 }
 
 func (h *Handler) runVotingTimer(roomID, roomCode string, duration int) {
 	log.Printf("[PetitBac] ⏱️ Timer vote démarré - %d secondes", duration)
 
+	state := h.gameManager.GetGameState(roomID)
+
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
 	for timeLeft := duration; timeLeft >= 0; timeLeft-- {
+		if h.gameManager.GetGameState(roomID) != state {
+			log.Printf("[PetitBac] ⚠️ Partie interrompue pendant le vote")
+			return
+		}
+
+		state.Mutex.Lock()
+		state.TimeLeft = timeLeft
+		state.Mutex.Unlock()
+
 		h.hub.Broadcast(roomCode, &models.WSMessage{
 			Type: "vote_time_update",
 			Payload: map[string]interface{}{
@@ -0,0 +1,57 @@
+package media
+
+// previewSeconds est la durée supposée d'un extrait quand son débit ne peut
+// pas être lu (autre format que le MP3).
+const previewSeconds = 30
+
+// Débits MPEG Layer III en kbit/s, indexés par le champ bitrate de l'en-tête.
+var (
+	mpeg1Layer3Bitrates = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mpeg2Layer3Bitrates = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+)
+
+// clipLength estime le nombre d'octets qui couvrent les seconds premières
+// secondes de l'extrait. Pour un MP3 à débit constant (les extraits Deezer),
+// c'est l'en-tête ID3 plus seconds fois le débit de la première trame ;
+// sinon l'extrait est supposé durer previewSeconds.
+func clipLength(data []byte, seconds int) int {
+	if offset, bytesPerSecond, ok := mp3Layout(data); ok {
+		return min(len(data), offset+seconds*bytesPerSecond)
+	}
+	return min(len(data), len(data)*seconds/previewSeconds)
+}
+
+// mp3Layout renvoie la position de la première trame MP3 et le débit en
+// octets par seconde lu dans son en-tête.
+func mp3Layout(data []byte) (int, int, bool) {
+	offset := 0
+	if len(data) >= 10 && string(data[:3]) == "ID3" {
+		// Taille "syncsafe" : 7 bits utiles par octet.
+		size := int(data[6])<<21 | int(data[7])<<14 | int(data[8])<<7 | int(data[9])
+		offset = 10 + size
+		if data[5]&0x10 != 0 {
+			offset += 10
+		}
+	}
+
+	for i := offset; i+3 < len(data) && i < offset+4096; i++ {
+		if data[i] != 0xFF || data[i+1]&0xE0 != 0xE0 {
+			continue
+		}
+		version := (data[i+1] >> 3) & 0x03
+		layer := (data[i+1] >> 1) & 0x03
+		if version == 1 || layer != 1 {
+			continue
+		}
+		index := data[i+2] >> 4
+		kbps := mpeg2Layer3Bitrates[index]
+		if version == 3 {
+			kbps = mpeg1Layer3Bitrates[index]
+		}
+		if kbps == 0 {
+			continue
+		}
+		return i, kbps * 1000 / 8, true
+	}
+	return 0, 0, false
+}
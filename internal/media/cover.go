@@ -0,0 +1,155 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"groupie-tracker/internal/auth"
+)
+
+// CoverPrefix est la route des pochettes floutées (indice cover_blurred du
+// Blind Test). Comme pour les extraits, les clients ne reçoivent qu'un jeton :
+// l'URL de la pochette identifierait l'album avant l'indice suivant.
+const CoverPrefix = "/media/cover/"
+
+// coverWidth est la largeur de la pochette floutée : agrandie par le
+// navigateur, elle ne laisse voir que des couleurs et des formes.
+const coverWidth = 12
+
+// coverToken garde la pochette floutée de la salle, préparée une seule fois
+// en arrière-plan dès la publication du jeton.
+type coverToken struct {
+	roomID  string
+	blurred *cachedPreview
+}
+
+// BlurCover publie une version floutée de la pochette source pour la salle et
+// renvoie son URL publique. Le jeton précédent de la salle cesse d'être
+// valide, comme à chaque nouvelle manche.
+func (p *PreviewProxy) BlurCover(roomID, source string) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	cover := &coverToken{roomID: roomID, blurred: &cachedPreview{ready: make(chan struct{})}}
+
+	p.mutex.Lock()
+	p.dropCoverLocked(roomID)
+	p.covers[token] = cover
+	p.roomCovers[roomID] = token
+	p.mutex.Unlock()
+
+	go func() {
+		data, err := p.fetch(source)
+		if err == nil {
+			data, err = blurImage(data)
+		}
+		cover.blurred.data, cover.blurred.err = data, err
+		cover.blurred.fetchedAt = time.Now()
+		close(cover.blurred.ready)
+	}()
+
+	return CoverPrefix + token, nil
+}
+
+// dropCoverLocked invalide la pochette floutée de la salle. L'appelant tient
+// p.mutex.
+func (p *PreviewProxy) dropCoverLocked(roomID string) {
+	if token, exists := p.roomCovers[roomID]; exists {
+		delete(p.covers, token)
+		delete(p.roomCovers, roomID)
+	}
+}
+
+// ServeCover sert la pochette floutée d'un jeton aux membres de sa salle.
+func (p *PreviewProxy) ServeCover(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := strings.TrimPrefix(r.URL.Path, CoverPrefix)
+
+	p.mutex.Lock()
+	cover, exists := p.covers[token]
+	isMember := p.isMember
+	p.mutex.Unlock()
+
+	if !exists {
+		http.NotFound(w, r)
+		return
+	}
+
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil || isMember == nil || !isMember(cover.roomID, user.ID) {
+		http.Error(w, "Vous n'êtes pas dans cette salle", http.StatusForbidden)
+		return
+	}
+
+	select {
+	case <-cover.blurred.ready:
+	case <-r.Context().Done():
+		return
+	}
+	if cover.blurred.err != nil {
+		log.Printf("[Media] Erreur pochette salle %s: %v", cover.roomID, cover.blurred.err)
+		http.Error(w, ErrPreviewUnavailable.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("Content-Type", "image/jpeg")
+	http.ServeContent(w, r, "", cover.blurred.fetchedAt, bytes.NewReader(cover.blurred.data))
+}
+
+// blurImage réduit l'image à coverWidth pixels de large en moyennant chaque
+// bloc de pixels, puis la réencode en JPEG.
+func blurImage(data []byte) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := src.Bounds()
+	width := min(coverWidth, bounds.Dx())
+	height := max(bounds.Dy()*width/max(bounds.Dx(), 1), 1)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(bounds.Min.Y+(y+1)*bounds.Dy()/height, y0+1)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(bounds.Min.X+(x+1)*bounds.Dx()/width, x0+1)
+
+			var r, g, b, count uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, _ := src.At(sx, sy).RGBA()
+					r, g, b = r+uint64(pr), g+uint64(pg), b+uint64(pb)
+					count++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / count),
+				G: uint16(g / count),
+				B: uint16(b / count),
+				A: 0xFFFF,
+			})
+		}
+	}
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,113 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testCover(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestBlurImage(t *testing.T) {
+	tests := []struct {
+		name       string
+		width      int
+		height     int
+		wantWidth  int
+		wantHeight int
+	}{
+		{"carrée", 300, 300, coverWidth, coverWidth},
+		{"large", 240, 120, coverWidth, coverWidth / 2},
+		{"plus petite que le flou", 5, 5, 5, 5},
+		{"très plate", 600, 10, coverWidth, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := blurImage(testCover(t, tt.width, tt.height))
+			if err != nil {
+				t.Fatalf("blurImage: %v", err)
+			}
+			img, err := jpeg.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("JPEG illisible: %v", err)
+			}
+			if size := img.Bounds().Size(); size.X != tt.wantWidth || size.Y != tt.wantHeight {
+				t.Errorf("taille %v, attendu %dx%d", size, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+
+	if _, err := blurImage([]byte("pas une image")); err == nil {
+		t.Error("image illisible acceptée")
+	}
+}
+
+func TestServeCover(t *testing.T) {
+	proxy, _, _ := newTestProxy(t)
+	cover := testCover(t, 64, 64)
+	images := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(cover)
+	}))
+	defer images.Close()
+
+	first, err := proxy.BlurCover("salle-a", images.URL+"/album-secret.png")
+	if err != nil {
+		t.Fatalf("BlurCover: %v", err)
+	}
+	if !strings.HasPrefix(first, CoverPrefix) || strings.Contains(first, "album-secret") {
+		t.Fatalf("URL publique %q", first)
+	}
+	url, _ := proxy.BlurCover("salle-a", images.URL+"/album-secret.png")
+
+	tests := []struct {
+		name       string
+		url        string
+		userID     int64
+		wantStatus int
+	}{
+		{"joueur de la salle", url, 1, http.StatusOK},
+		{"joueur d'une autre salle", url, 3, http.StatusForbidden},
+		{"sans session", url, 0, http.StatusForbidden},
+		{"pochette de la manche précédente", first, 1, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			proxy.ServeCover(rec, previewRequest(http.MethodGet, tt.url, tt.userID))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("statut %d, attendu %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && rec.Header().Get("Content-Type") != "image/jpeg" {
+				t.Errorf("Content-Type %q", rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+
+	proxy.EndGame("salle-a")
+	rec := httptest.NewRecorder()
+	proxy.ServeCover(rec, previewRequest(http.MethodGet, url, 1))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("pochette après la partie: statut %d", rec.Code)
+	}
+}
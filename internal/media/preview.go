@@ -0,0 +1,384 @@
+package media
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"groupie-tracker/internal/auth"
+)
+
+// PreviewPrefix est la route des extraits servis par le proxy. Les clients ne
+// reçoivent que PreviewPrefix + jeton : l'URL d'origine (Deezer, catalogue,
+// fichier local) identifierait la piste avant la réponse.
+const PreviewPrefix = "/media/preview/"
+
+const (
+	maxCachedPreviews = 50
+	maxPreviewSize    = 10 << 20
+)
+
+var ErrPreviewUnavailable = errors.New("extrait indisponible")
+
+// seconds limite le jeton au début de l'extrait (mode progressif du Blind
+// Test) ; 0 sert l'extrait complet.
+type previewToken struct {
+	roomID  string
+	source  string
+	seconds int
+}
+
+// cachedPreview est partagé par les requêtes simultanées sur le même extrait :
+// ready est fermé une fois data ou err renseigné.
+type cachedPreview struct {
+	ready     chan struct{}
+	data      []byte
+	err       error
+	fetchedAt time.Time
+}
+
+// LocalSource ouvre les fichiers audio locaux désignés par une source (ex.
+// local:{id}). Ils n'ont pas de route publique : seul le proxy les sert.
+type LocalSource interface {
+	Open(source string) (*os.File, error)
+}
+
+// MembershipFunc indique si l'utilisateur est joueur ou spectateur de la
+// salle.
+type MembershipFunc func(roomID string, userID int64) bool
+
+// PreviewProxy associe un jeton opaque à l'extrait de la manche en cours de
+// chaque salle et le sert avec prise en charge des requêtes Range, aux seuls
+// membres de la salle.
+type PreviewProxy struct {
+	httpClient *http.Client
+	tokens     map[string]previewToken
+	rooms      map[string]string
+	cache      map[string]*cachedPreview
+	local      map[string]LocalSource
+	covers     map[string]*coverToken
+	roomCovers map[string]string
+	isMember   MembershipFunc
+	mutex      sync.Mutex
+}
+
+var (
+	proxyInstance *PreviewProxy
+	proxyOnce     sync.Once
+)
+
+func GetPreviewProxy() *PreviewProxy {
+	proxyOnce.Do(func() {
+		proxyInstance = &PreviewProxy{
+			httpClient: &http.Client{
+				Timeout: 15 * time.Second,
+			},
+			tokens:     make(map[string]previewToken),
+			rooms:      make(map[string]string),
+			cache:      make(map[string]*cachedPreview),
+			local:      make(map[string]LocalSource),
+			covers:     make(map[string]*coverToken),
+			roomCovers: make(map[string]string),
+		}
+	})
+	return proxyInstance
+}
+
+// AddLocalSource lit les sources commençant par prefix (ex. local:) dans
+// source au lieu de les télécharger.
+func (p *PreviewProxy) AddLocalSource(prefix string, source LocalSource) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.local[prefix] = source
+}
+
+// SetMembership règle le contrôle d'accès aux extraits. Sans lui, aucun
+// extrait n'est servi.
+func (p *PreviewProxy) SetMembership(isMember MembershipFunc) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.isMember = isMember
+}
+
+// StartRound remplace l'extrait de la salle et renvoie l'URL publique de la
+// manche. Les jetons précédents de la salle, extrait et pochette floutée,
+// cessent d'être valides. Le fichier est téléchargé en arrière-plan pour être
+// prêt au preload.
+func (p *PreviewProxy) StartRound(roomID, source string) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	p.mutex.Lock()
+	if previous, exists := p.rooms[roomID]; exists {
+		delete(p.tokens, previous)
+	}
+	p.tokens[token] = previewToken{roomID: roomID, source: source}
+	p.rooms[roomID] = token
+	p.dropCoverLocked(roomID)
+	local := p.localSourceLocked(source) != nil
+	p.mutex.Unlock()
+
+	if !local {
+		go p.load(source)
+	}
+
+	return PreviewPrefix + token, nil
+}
+
+// LimitRound remplace le jeton de la salle par un jeton qui ne sert que les
+// seconds premières secondes de l'extrait en cours. Le navigateur ne peut pas
+// lire plus loin que ce que le serveur a débloqué.
+func (p *PreviewProxy) LimitRound(roomID string, seconds int) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	previous, exists := p.rooms[roomID]
+	entry, valid := p.tokens[previous]
+	if !exists || !valid {
+		return "", ErrPreviewUnavailable
+	}
+	delete(p.tokens, previous)
+	p.tokens[token] = previewToken{roomID: roomID, source: entry.source, seconds: seconds}
+	p.rooms[roomID] = token
+
+	return PreviewPrefix + token, nil
+}
+
+// EndGame invalide les jetons de la salle.
+func (p *PreviewProxy) EndGame(roomID string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if token, exists := p.rooms[roomID]; exists {
+		delete(p.tokens, token)
+		delete(p.rooms, roomID)
+	}
+	p.dropCoverLocked(roomID)
+}
+
+func (p *PreviewProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := strings.TrimPrefix(r.URL.Path, PreviewPrefix)
+
+	p.mutex.Lock()
+	entry, exists := p.tokens[token]
+	var local LocalSource
+	if exists {
+		local = p.localSourceLocked(entry.source)
+	}
+	isMember := p.isMember
+	p.mutex.Unlock()
+
+	if !exists {
+		http.NotFound(w, r)
+		return
+	}
+
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil || isMember == nil || !isMember(entry.roomID, user.ID) {
+		http.Error(w, "Vous n'êtes pas dans cette salle", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, no-store")
+
+	if entry.seconds > 0 {
+		p.serveClip(w, r, entry, local)
+		return
+	}
+
+	if local != nil {
+		p.serveLocal(w, r, entry, local)
+		return
+	}
+
+	preview := p.load(entry.source)
+	if preview.err != nil {
+		log.Printf("[Media] Erreur extrait salle %s: %v", entry.roomID, preview.err)
+		http.Error(w, ErrPreviewUnavailable.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "audio/mpeg")
+	http.ServeContent(w, r, "", preview.fetchedAt, bytes.NewReader(preview.data))
+}
+
+// serveClip sert le début de l'extrait, local ou distant, tronqué à
+// entry.seconds secondes.
+func (p *PreviewProxy) serveClip(w http.ResponseWriter, r *http.Request, entry previewToken, local LocalSource) {
+	var data []byte
+	var modified time.Time
+	contentType := "audio/mpeg"
+
+	if local != nil {
+		file, info, err := openLocal(local, entry.source)
+		if err != nil {
+			log.Printf("[Media] Erreur fichier local salle %s: %v", entry.roomID, err)
+			http.Error(w, ErrPreviewUnavailable.Error(), http.StatusNotFound)
+			return
+		}
+		defer file.Close()
+
+		data, err = io.ReadAll(io.LimitReader(file, maxPreviewSize+1))
+		if err != nil || len(data) > maxPreviewSize {
+			http.Error(w, ErrPreviewUnavailable.Error(), http.StatusNotFound)
+			return
+		}
+		modified = info.ModTime()
+		if localType := mime.TypeByExtension(filepath.Ext(info.Name())); localType != "" {
+			contentType = localType
+		}
+	} else {
+		preview := p.load(entry.source)
+		if preview.err != nil {
+			log.Printf("[Media] Erreur extrait salle %s: %v", entry.roomID, preview.err)
+			http.Error(w, ErrPreviewUnavailable.Error(), http.StatusBadGateway)
+			return
+		}
+		data = preview.data
+		modified = preview.fetchedAt
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	http.ServeContent(w, r, "", modified, bytes.NewReader(data[:clipLength(data, entry.seconds)]))
+}
+
+// serveLocal sert un fichier local complet. http.ServeContent gère les
+// requêtes Range, nécessaires pour que le navigateur puisse avancer dans le
+// fichier.
+func (p *PreviewProxy) serveLocal(w http.ResponseWriter, r *http.Request, entry previewToken, local LocalSource) {
+	file, info, err := openLocal(local, entry.source)
+	if err != nil {
+		log.Printf("[Media] Erreur fichier local salle %s: %v", entry.roomID, err)
+		http.Error(w, ErrPreviewUnavailable.Error(), http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
+
+func openLocal(local LocalSource, source string) (*os.File, os.FileInfo, error) {
+	file, err := local.Open(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return file, info, nil
+}
+
+func (p *PreviewProxy) localSourceLocked(source string) LocalSource {
+	for prefix, local := range p.local {
+		if strings.HasPrefix(source, prefix) {
+			return local
+		}
+	}
+	return nil
+}
+
+// load renvoie l'extrait en cache ou le télécharge une seule fois, même si
+// plusieurs joueurs le demandent en même temps.
+func (p *PreviewProxy) load(source string) *cachedPreview {
+	p.mutex.Lock()
+	preview, exists := p.cache[source]
+	if exists {
+		p.mutex.Unlock()
+		<-preview.ready
+		return preview
+	}
+
+	if len(p.cache) >= maxCachedPreviews {
+		p.evictLocked()
+	}
+	preview = &cachedPreview{ready: make(chan struct{})}
+	p.cache[source] = preview
+	p.mutex.Unlock()
+
+	preview.data, preview.err = p.fetch(source)
+	preview.fetchedAt = time.Now()
+	close(preview.ready)
+
+	if preview.err != nil {
+		// Un échec n'est pas gardé : la prochaine requête retentera.
+		p.mutex.Lock()
+		if p.cache[source] == preview {
+			delete(p.cache, source)
+		}
+		p.mutex.Unlock()
+	}
+	return preview
+}
+
+// evictLocked retire l'extrait le plus ancien parmi ceux déjà téléchargés.
+func (p *PreviewProxy) evictLocked() {
+	var oldestKey string
+	var oldest time.Time
+	for key, preview := range p.cache {
+		select {
+		case <-preview.ready:
+		default:
+			continue
+		}
+		if oldestKey == "" || preview.fetchedAt.Before(oldest) {
+			oldestKey, oldest = key, preview.fetchedAt
+		}
+	}
+	if oldestKey != "" {
+		delete(p.cache, oldestKey)
+	}
+}
+
+func (p *PreviewProxy) fetch(source string) ([]byte, error) {
+	resp, err := p.httpClient.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: HTTP %d", ErrPreviewUnavailable, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPreviewSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxPreviewSize {
+		return nil, fmt.Errorf("%w: fichier trop volumineux", ErrPreviewUnavailable)
+	}
+	return data, nil
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
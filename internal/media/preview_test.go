@@ -0,0 +1,200 @@
+package media
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"groupie-tracker/internal/auth"
+	"groupie-tracker/internal/models"
+)
+
+const previewData = "ID3 extrait de test"
+
+func newTestProxy(t *testing.T) (*PreviewProxy, *httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var fetches atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Write([]byte(previewData))
+	}))
+	t.Cleanup(upstream.Close)
+
+	proxy := &PreviewProxy{
+		httpClient: &http.Client{Timeout: time.Second},
+		tokens:     make(map[string]previewToken),
+		rooms:      make(map[string]string),
+		cache:      make(map[string]*cachedPreview),
+		local:      make(map[string]LocalSource),
+		covers:     make(map[string]*coverToken),
+		roomCovers: make(map[string]string),
+	}
+	// L'utilisateur 1 joue dans salle-a, le 2 regarde salle-a, le 3 joue ailleurs.
+	proxy.SetMembership(func(roomID string, userID int64) bool {
+		return roomID == "salle-a" && (userID == 1 || userID == 2) || roomID == "salle-b" && userID == 3
+	})
+	return proxy, upstream, &fetches
+}
+
+func previewRequest(method, url string, userID int64) *http.Request {
+	r := httptest.NewRequest(method, url, nil)
+	if userID != 0 {
+		r = r.WithContext(context.WithValue(r.Context(), auth.UserContextKey, &models.User{ID: userID}))
+	}
+	return r
+}
+
+func TestPreviewProxyAccess(t *testing.T) {
+	proxy, upstream, _ := newTestProxy(t)
+	url, err := proxy.StartRound("salle-a", upstream.URL+"/secret-track-42.mp3")
+	if err != nil {
+		t.Fatalf("StartRound: %v", err)
+	}
+	if !strings.HasPrefix(url, PreviewPrefix) || strings.Contains(url, "secret-track-42") {
+		t.Fatalf("URL publique %q", url)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		userID     int64
+		wantStatus int
+	}{
+		{"joueur de la salle", http.MethodGet, url, 1, http.StatusOK},
+		{"spectateur de la salle", http.MethodGet, url, 2, http.StatusOK},
+		{"joueur d'une autre salle", http.MethodGet, url, 3, http.StatusForbidden},
+		{"inconnu connecté", http.MethodGet, url, 4, http.StatusForbidden},
+		{"sans session", http.MethodGet, url, 0, http.StatusForbidden},
+		{"jeton inconnu", http.MethodGet, PreviewPrefix + "0123456789abcdef", 1, http.StatusNotFound},
+		{"méthode refusée", http.MethodPost, url, 1, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			proxy.ServeHTTP(rec, previewRequest(tt.method, tt.url, tt.userID))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("statut %d, attendu %d", rec.Code, tt.wantStatus)
+			}
+			if body := rec.Body.String(); (tt.wantStatus == http.StatusOK) != (body == previewData) {
+				t.Errorf("corps %q", body)
+			}
+		})
+	}
+}
+
+func TestPreviewProxyWithoutMembership(t *testing.T) {
+	proxy, upstream, _ := newTestProxy(t)
+	proxy.SetMembership(nil)
+	url, _ := proxy.StartRound("salle-a", upstream.URL+"/a.mp3")
+
+	rec := httptest.NewRecorder()
+	proxy.ServeHTTP(rec, previewRequest(http.MethodGet, url, 1))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("sans contrôle d'accès: statut %d, attendu %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestPreviewProxyRounds(t *testing.T) {
+	proxy, upstream, fetches := newTestProxy(t)
+	first, _ := proxy.StartRound("salle-a", upstream.URL+"/1.mp3")
+	second, _ := proxy.StartRound("salle-a", upstream.URL+"/2.mp3")
+	other, _ := proxy.StartRound("salle-b", upstream.URL+"/3.mp3")
+
+	serve := func(url string, userID int64, header http.Header) *httptest.ResponseRecorder {
+		r := previewRequest(http.MethodGet, url, userID)
+		for key, values := range header {
+			r.Header[key] = values
+		}
+		rec := httptest.NewRecorder()
+		proxy.ServeHTTP(rec, r)
+		return rec
+	}
+
+	if rec := serve(first, 1, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("jeton de la manche précédente: statut %d", rec.Code)
+	}
+	if rec := serve(other, 3, nil); rec.Code != http.StatusOK {
+		t.Errorf("autre salle non affectée: statut %d", rec.Code)
+	}
+
+	rec := serve(second, 1, http.Header{"Range": {"bytes=0-2"}})
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != previewData[:3] {
+		t.Errorf("requête Range: statut %d, corps %q", rec.Code, rec.Body.String())
+	}
+	if rec := serve(second, 2, nil); rec.Code != http.StatusOK {
+		t.Errorf("second joueur: statut %d", rec.Code)
+	}
+	// Préchargement, Range et lecture complète partagent le même téléchargement.
+	if n := fetches.Load(); n > 3 {
+		t.Errorf("%d téléchargements pour 3 extraits", n)
+	}
+
+	proxy.EndGame("salle-a")
+	if rec := serve(second, 1, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("jeton après la partie: statut %d", rec.Code)
+	}
+}
+
+// dirSource lit les sources local:{nom} dans un dossier de test.
+type dirSource string
+
+func (d dirSource) Open(source string) (*os.File, error) {
+	return os.Open(filepath.Join(string(d), strings.TrimPrefix(source, "local:")))
+}
+
+func TestPreviewProxyLocal(t *testing.T) {
+	proxy, _, fetches := newTestProxy(t)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "abc.mp3"), []byte(previewData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	proxy.AddLocalSource("local:", dirSource(dir))
+
+	tests := []struct {
+		name   string
+		source string
+		userID int64
+		status int
+		body   string
+	}{
+		{"fichier local", "local:abc.mp3", 1, http.StatusOK, previewData},
+		{"fichier local hors salle", "local:abc.mp3", 3, http.StatusForbidden, ""},
+		{"fichier absent", "local:absent.mp3", 1, http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, _ := proxy.StartRound("salle-a", tt.source)
+			rec := httptest.NewRecorder()
+			proxy.ServeHTTP(rec, previewRequest(http.MethodGet, url, tt.userID))
+			if rec.Code != tt.status {
+				t.Fatalf("statut %d, attendu %d", rec.Code, tt.status)
+			}
+			if tt.body != "" && rec.Body.String() != tt.body {
+				t.Errorf("corps %q, attendu %q", rec.Body.String(), tt.body)
+			}
+		})
+	}
+
+	// Le mode progressif tronque aussi les fichiers locaux.
+	proxy.StartRound("salle-a", "local:abc.mp3")
+	clip, err := proxy.LimitRound("salle-a", 1)
+	if err != nil {
+		t.Fatalf("LimitRound: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	proxy.ServeHTTP(rec, previewRequest(http.MethodGet, clip, 1))
+	if rec.Code != http.StatusOK || !strings.HasPrefix(previewData, rec.Body.String()) {
+		t.Errorf("extrait local limité: statut %d, corps %q", rec.Code, rec.Body.String())
+	}
+	if fetches.Load() != 0 {
+		t.Error("source locale téléchargée en HTTP")
+	}
+}
@@ -35,9 +35,10 @@ const (
 type RoomStatus string
 
 const (
-	RoomStatusWaiting  RoomStatus = "waiting"
-	RoomStatusPlaying  RoomStatus = "playing"
-	RoomStatusFinished RoomStatus = "finished"
+	RoomStatusWaiting     RoomStatus = "waiting"
+	RoomStatusPlaying     RoomStatus = "playing"
+	RoomStatusFinished    RoomStatus = "finished"
+	RoomStatusInterrupted RoomStatus = "interrupted"
 )
 
 type RoomStatusInfo struct {
@@ -66,6 +67,12 @@ func (s RoomStatus) GetStatusInfo() RoomStatusInfo {
 			Icon:  "icon-check",
 			Color: "status-finished",
 		}
+	case RoomStatusInterrupted:
+		return RoomStatusInfo{
+			Label: "Interrompue",
+			Icon:  "icon-warning",
+			Color: "status-interrupted",
+		}
 	default:
 		return RoomStatusInfo{
 			Label: "Inconnu",
@@ -80,16 +87,17 @@ func (s RoomStatus) String() string {
 }
 
 type Room struct {
-	ID        string            `json:"id"`
-	Code      string            `json:"code"`
-	Name      string            `json:"name"`
-	HostID    int64             `json:"host_id"`
-	GameType  GameType          `json:"game_type"`
-	Status    RoomStatus        `json:"status"`
-	Players   map[int64]*Player `json:"players"`
-	Config    GameConfig        `json:"config"`
-	CreatedAt time.Time         `json:"created_at"`
-	Mutex     sync.RWMutex      `json:"-"`
+	ID         string               `json:"id"`
+	Code       string               `json:"code"`
+	Name       string               `json:"name"`
+	HostID     int64                `json:"host_id"`
+	GameType   GameType             `json:"game_type"`
+	Status     RoomStatus           `json:"status"`
+	Players    map[int64]*Player    `json:"players"`
+	Spectators map[int64]*Spectator `json:"spectators"`
+	Config     GameConfig           `json:"config"`
+	CreatedAt  time.Time            `json:"created_at"`
+	Mutex      sync.RWMutex         `json:"-"`
 }
 
 func (r *Room) PlayerCount() int {
@@ -98,6 +106,21 @@ func (r *Room) PlayerCount() int {
 	return len(r.Players)
 }
 
+func (r *Room) SpectatorCount() int {
+	r.Mutex.RLock()
+	defer r.Mutex.RUnlock()
+	return len(r.Spectators)
+}
+
+// Spectator suit la partie sans y participer : il reçoit les broadcasts mais
+// n'apparaît ni dans les scores ni dans les conditions de fin de manche.
+// Les spectateurs ne sont pas persistés.
+type Spectator struct {
+	UserID    int64  `json:"user_id"`
+	Pseudo    string `json:"pseudo"`
+	Connected bool   `json:"connected"`
+}
+
 type Player struct {
 	UserID    int64  `json:"user_id"`
 	Pseudo    string `json:"pseudo"`
@@ -108,11 +131,25 @@ type Player struct {
 }
 
 type GameConfig struct {
-	Playlist     string   `json:"playlist,omitempty"`
-	TimePerRound int      `json:"time_per_round,omitempty"`
-	Categories   []string `json:"categories,omitempty"`
-	NbRounds     int      `json:"nb_rounds,omitempty"`
-	UsedLetters  []string `json:"used_letters,omitempty"`
+	Playlist       string   `json:"playlist,omitempty"`
+	TrackSource    string   `json:"track_source,omitempty"`
+	PlaylistID     string   `json:"playlist_id,omitempty"`
+	PlaylistTitle  string   `json:"playlist_title,omitempty"`
+	PlaylistTracks int      `json:"playlist_tracks,omitempty"`
+	TimePerRound   int      `json:"time_per_round,omitempty"`
+	Categories     []string `json:"categories,omitempty"`
+	NbRounds       int      `json:"nb_rounds,omitempty"`
+	PauseTime      int      `json:"pause_time,omitempty"`
+	RevealTime     int      `json:"reveal_time,omitempty"`
+	Scoring        string   `json:"scoring,omitempty"`
+	AnswerMode     string   `json:"answer_mode,omitempty"`
+	Hints          bool     `json:"hints,omitempty"`
+	HintAt         []int    `json:"hint_at,omitempty"`
+	ClipMode       string   `json:"clip_mode,omitempty"`
+	StageGuesses   int      `json:"stage_guesses,omitempty"`
+	StageTime      int      `json:"stage_time,omitempty"`
+	BuzzTime       int      `json:"buzz_time,omitempty"`
+	UsedLetters    []string `json:"used_letters,omitempty"`
 }
 
 func IsRoomReady(r *Room) bool {
@@ -139,6 +176,9 @@ type SpotifyTrack struct {
 	Album      string `json:"album"`
 	PreviewURL string `json:"preview_url"`
 	ImageURL   string `json:"image_url"`
+	// FetchedAt est la date de réception de la piste : l'URL de l'extrait
+	// Deezer est signée et finit par expirer.
+	FetchedAt time.Time `json:"-"`
 }
 
 var DefaultPetitBacCategories = []string{
@@ -169,6 +209,15 @@ const (
 	WSTypePlayerReady  WSMessageType = "player_ready"
 	WSTypeRoomUpdate   WSMessageType = "room_update"
 	WSTypeStartGame    WSMessageType = "start_game"
+	WSTypeGameSnapshot WSMessageType = "game_snapshot"
+	WSTypeUpdateConfig WSMessageType = "update_config"
+	WSTypeConfigUpdate WSMessageType = "config_updated"
+
+	WSTypePlayerDisconnected WSMessageType = "player_disconnected"
+	WSTypePlayerReconnected  WSMessageType = "player_reconnected"
+	WSTypeHostChanged        WSMessageType = "host_changed"
+	WSTypeSpectatorJoined    WSMessageType = "spectator_joined"
+	WSTypeSpectatorLeft      WSMessageType = "spectator_left"
 
 	WSTypeBTPreload   WSMessageType = "bt_preload"
 	WSTypeBTNewRound  WSMessageType = "bt_new_round"
@@ -177,6 +226,12 @@ const (
 	WSTypeBTReveal    WSMessageType = "bt_reveal"
 	WSTypeBTScores    WSMessageType = "bt_scores"
 	WSTypeBTGameEnd   WSMessageType = "bt_game_end"
+	WSTypeBTHint      WSMessageType = "bt_hint"
+	WSTypeBTClipStage WSMessageType = "bt_clip_stage"
+	WSTypeBTBuzz      WSMessageType = "bt_buzz"
+	WSTypeBTBuzzed    WSMessageType = "bt_buzzed"
+	WSTypeBTBuzzLock  WSMessageType = "bt_buzz_lock"
+	WSTypeBTResume    WSMessageType = "bt_resume"
 	WSTypeTimeUpdate  WSMessageType = "time_update"
 	WSTypePlayerFound WSMessageType = "player_found"
 
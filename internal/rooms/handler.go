@@ -6,32 +6,42 @@ import (
 	"log"
 	"net/http"
 	"path/filepath"
-	"strconv"
 	"strings"
 
 	"groupie-tracker/internal/auth"
+	"groupie-tracker/internal/games"
 	"groupie-tracker/internal/models"
 )
 
+// LeaveFunc fait quitter la salle à un joueur ou à un spectateur et
+// l'annonce aux autres. Le handler WebSocket la fournit
+// (websocket.Handler.LeaveRoom) : ce paquet ne peut pas l'importer.
+type LeaveFunc func(room *models.Room, userID int64, pseudo string) error
+
 type Handler struct {
-	templateDir string
+	templateDir    string
+	manager        *Manager
+	sessionManager *auth.SessionManager
+	leave          LeaveFunc
 }
 
-func NewHandler(templateDir string) *Handler {
+func NewHandler(templateDir string, manager *Manager, sessionManager *auth.SessionManager, leave LeaveFunc) *Handler {
 	return &Handler{
-		templateDir: templateDir,
+		templateDir:    templateDir,
+		manager:        manager,
+		sessionManager: sessionManager,
+		leave:          leave,
 	}
 }
 
 func (h *Handler) HandleLobby(w http.ResponseWriter, r *http.Request) {
-	sessionManager := auth.NewSessionManager()
-	user, err := sessionManager.GetUserFromRequest(r)
+	user, err := h.sessionManager.GetUserFromRequest(r)
 	if err != nil {
 		http.Redirect(w, r, "/login?redirect="+r.URL.Path, http.StatusSeeOther)
 		return
 	}
 
-	manager := GetManager()
+	manager := h.manager
 	rooms := manager.GetAllRooms()
 
 	data := map[string]interface{}{
@@ -57,8 +67,7 @@ func (h *Handler) HandleLobby(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) HandleRoom(w http.ResponseWriter, r *http.Request) {
-	sessionManager := auth.NewSessionManager()
-	user, err := sessionManager.GetUserFromRequest(r)
+	user, err := h.sessionManager.GetUserFromRequest(r)
 	if err != nil {
 		http.Redirect(w, r, "/login?redirect="+r.URL.Path, http.StatusSeeOther)
 		return
@@ -70,7 +79,7 @@ func (h *Handler) HandleRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	manager := GetManager()
+	manager := h.manager
 	room, err := manager.GetRoomByCode(code)
 	if err != nil {
 		room, err = manager.GetRoom(code)
@@ -81,9 +90,15 @@ func (h *Handler) HandleRoom(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var player *models.Player
+	isSpectator := false
 	room.Mutex.RLock()
 	if p, exists := room.Players[user.ID]; exists {
 		player = p
+	} else if _, exists := room.Spectators[user.ID]; exists {
+		// Les templates lisent .Player : un spectateur reçoit un joueur fictif
+		// sans droits.
+		player = &models.Player{UserID: user.ID, Pseudo: user.Pseudo}
+		isSpectator = true
 	}
 	room.Mutex.RUnlock()
 
@@ -92,21 +107,17 @@ func (h *Handler) HandleRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var tmplFile string
-	switch room.GameType {
-	case models.GameTypeBlindTest:
-		tmplFile = "room_blindtest.html"
-	case models.GameTypePetitBac:
-		tmplFile = "room_petitbac.html"
-	default:
-		tmplFile = "room.html"
+	tmplFile := "room.html"
+	if engine, err := games.GetRegistry().Get(room.GameType); err == nil {
+		tmplFile = engine.Template()
 	}
 
 	data := map[string]interface{}{
-		"Title":  room.Name,
-		"User":   user,
-		"Room":   room,
-		"Player": player,
+		"Title":       room.Name,
+		"User":        user,
+		"Room":        room,
+		"Player":      player,
+		"IsSpectator": isSpectator,
 	}
 
 	tmplPath := filepath.Join(h.templateDir, tmplFile)
@@ -131,7 +142,7 @@ func (h *Handler) HandleGetRooms(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	manager := GetManager()
+	manager := h.manager
 	rooms := manager.GetAllRooms()
 
 	w.Header().Set("Content-Type", "application/json")
@@ -147,8 +158,7 @@ func (h *Handler) HandleCreateRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sessionManager := auth.NewSessionManager()
-	user, err := sessionManager.GetUserFromRequest(r)
+	user, err := h.sessionManager.GetUserFromRequest(r)
 	if err != nil {
 		http.Error(w, "Non authentifié", http.StatusUnauthorized)
 		return
@@ -167,64 +177,29 @@ func (h *Handler) HandleCreateRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var gameType models.GameType
-	switch gameTypeStr {
-	case "blindtest":
-		gameType = models.GameTypeBlindTest
-	case "petitbac":
-		gameType = models.GameTypePetitBac
-	default:
+	engine, err := games.GetRegistry().Get(models.GameType(gameTypeStr))
+	if err != nil {
 		http.Error(w, "Type de jeu invalide", http.StatusBadRequest)
 		return
 	}
 
-	manager := GetManager()
-	room, err := manager.CreateRoom(roomName, user.ID, user.Pseudo, gameType)
+	config := engine.DefaultConfig()
+	engine.ParseConfig(r.Form, &config)
+	if err := engine.ValidateConfig(&config); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	manager := h.manager
+	room, err := manager.CreateRoom(roomName, user.ID, user.Pseudo, engine.GameType())
 	if err != nil {
 		log.Printf("[ROOMS] Erreur création salle: %v", err)
 		http.Error(w, "Erreur création salle", http.StatusInternalServerError)
 		return
 	}
 
-	if gameType == models.GameTypePetitBac {
-		room.Mutex.Lock()
-
-		categories := r.Form["categories"]
-		if len(categories) >= 3 {
-			room.Config.Categories = categories
-			log.Printf("[ROOMS] Catégories personnalisées: %v", categories)
-		} else {
-			room.Config.Categories = models.DefaultPetitBacCategories
-		}
-
-		roundTimeStr := r.FormValue("round_time")
-		if roundTimeStr != "" {
-			if roundTime, err := strconv.Atoi(roundTimeStr); err == nil && roundTime >= 30 && roundTime <= 120 {
-				room.Config.TimePerRound = roundTime
-				log.Printf("[ROOMS] Temps par manche: %ds", roundTime)
-			} else {
-				room.Config.TimePerRound = 60
-			}
-		} else {
-			room.Config.TimePerRound = 60
-		}
-
-		roundCountStr := r.FormValue("round_count")
-		if roundCountStr != "" {
-			if roundCount, err := strconv.Atoi(roundCountStr); err == nil && roundCount >= 3 && roundCount <= 15 {
-				room.Config.NbRounds = roundCount
-				log.Printf("[ROOMS] Nombre de manches: %d", roundCount)
-			} else {
-				room.Config.NbRounds = models.NbrsManche
-			}
-		} else {
-			room.Config.NbRounds = models.NbrsManche
-		}
-
-		room.Mutex.Unlock()
-
-		log.Printf("[ROOMS] Config Petit Bac: %d catégories, %ds/manche, %d manches",
-			len(room.Config.Categories), room.Config.TimePerRound, room.Config.NbRounds)
+	if err := manager.UpdateRoomConfig(room.ID, config); err != nil {
+		log.Printf("[ROOMS] Erreur sauvegarde config: %v", err)
 	}
 
 	log.Printf("[ROOMS] Salle créée: %s (%s) par %s", room.Code, room.Name, user.Pseudo)
@@ -238,8 +213,7 @@ func (h *Handler) HandleJoinRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sessionManager := auth.NewSessionManager()
-	user, err := sessionManager.GetUserFromRequest(r)
+	user, err := h.sessionManager.GetUserFromRequest(r)
 	if err != nil {
 		http.Error(w, "Non authentifié", http.StatusUnauthorized)
 		return
@@ -256,7 +230,7 @@ func (h *Handler) HandleJoinRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	manager := GetManager()
+	manager := h.manager
 	room, err := manager.GetRoomByCode(code)
 	if err != nil {
 		room, err = manager.GetRoom(code)
@@ -266,30 +240,27 @@ func (h *Handler) HandleJoinRoom(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	player := &models.Player{
-		UserID:  user.ID,
-		Pseudo:  user.Pseudo,
-		Score:   0,
-		IsReady: false,
-	}
-
-	room.Mutex.Lock()
-
-	if _, exists := room.Players[user.ID]; exists {
-		room.Mutex.Unlock()
+	if r.FormValue("spectate") != "" {
+		if _, err := manager.AddSpectator(room.ID, user.ID, user.Pseudo); err != nil && err != ErrAlreadyInRoom {
+			http.Redirect(w, r, "/room/join?error=Salle+pleine", http.StatusSeeOther)
+			return
+		}
 		http.Redirect(w, r, "/room/"+room.Code, http.StatusSeeOther)
 		return
 	}
 
-	if len(room.Players) >= 8 {
-		room.Mutex.Unlock()
-		http.Redirect(w, r, "/room/join?error=Salle+pleine", http.StatusSeeOther)
+	if _, err := manager.JoinRoom(room.ID, user.ID, user.Pseudo); err != nil {
+		switch err {
+		case ErrGameInProgress:
+			http.Redirect(w, r, "/room/join?error=Partie+en+cours,+rejoignez+en+spectateur", http.StatusSeeOther)
+		case ErrRoomFull:
+			http.Redirect(w, r, "/room/join?error=Salle+pleine", http.StatusSeeOther)
+		default:
+			http.Redirect(w, r, "/room/join?error=Salle+introuvable", http.StatusSeeOther)
+		}
 		return
 	}
 
-	room.Players[user.ID] = player
-	room.Mutex.Unlock()
-
 	log.Printf("[ROOMS] %s a rejoint la salle %s", user.Pseudo, room.Code)
 
 	http.Redirect(w, r, "/room/"+room.Code, http.StatusSeeOther)
@@ -301,8 +272,7 @@ func (h *Handler) HandleLeaveRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sessionManager := auth.NewSessionManager()
-	user, err := sessionManager.GetUserFromRequest(r)
+	user, err := h.sessionManager.GetUserFromRequest(r)
 	if err != nil {
 		http.Error(w, "Non authentifié", http.StatusUnauthorized)
 		return
@@ -316,7 +286,7 @@ func (h *Handler) HandleLeaveRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	manager := GetManager()
+	manager := h.manager
 	room, err := manager.GetRoomByCode(req.RoomCode)
 	if err != nil {
 		room, err = manager.GetRoom(req.RoomCode)
@@ -326,9 +296,14 @@ func (h *Handler) HandleLeaveRoom(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	room.Mutex.Lock()
-	delete(room.Players, user.ID)
-	room.Mutex.Unlock()
+	if err := h.leave(room, user.ID, user.Pseudo); err != nil {
+		if err == ErrPlayerNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	log.Printf("[ROOMS] %s a quitté la salle %s", user.Pseudo, room.Code)
 
@@ -345,8 +320,7 @@ func (h *Handler) HandleRestartRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sessionManager := auth.NewSessionManager()
-	user, err := sessionManager.GetUserFromRequest(r)
+	user, err := h.sessionManager.GetUserFromRequest(r)
 	if err != nil {
 		http.Error(w, "Non authentifié", http.StatusUnauthorized)
 		return
@@ -355,7 +329,7 @@ func (h *Handler) HandleRestartRoom(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/rooms/")
 	code := strings.TrimSuffix(path, "/restart")
 
-	manager := GetManager()
+	manager := h.manager
 	room, err := manager.GetRoomByCode(code)
 	if err != nil {
 		room, err = manager.GetRoom(code)
@@ -370,17 +344,19 @@ func (h *Handler) HandleRestartRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if engine, err := games.GetRegistry().Get(room.GameType); err == nil {
+		engine.Stop(room.ID)
+	}
+
 	room.Mutex.Lock()
-	room.Status = models.RoomStatusWaiting
 	for _, player := range room.Players {
-		player.Score = 0
 		player.IsReady = false
 	}
-	if room.GameType == models.GameTypePetitBac {
-		room.Config.UsedLetters = []string{}
-	}
 	room.Mutex.Unlock()
 
+	manager.ResetPlayerScores(room.ID)
+	manager.UpdateRoomStatus(room.ID, models.RoomStatusWaiting)
+
 	log.Printf("[ROOMS] Partie redémarrée dans la salle %s par %s", room.Code, user.Pseudo)
 
 	w.Header().Set("Content-Type", "application/json")
@@ -2,7 +2,6 @@ package rooms
 
 import (
 	"crypto/rand"
-	"database/sql"
 	"encoding/hex"
 	"errors"
 	"log"
@@ -10,7 +9,7 @@ import (
 	"sync"
 	"time"
 
-	"groupie-tracker/internal/database"
+	"groupie-tracker/internal/games"
 	"groupie-tracker/internal/models"
 )
 
@@ -21,19 +20,23 @@ var (
 	ErrNotHost         = errors.New("seul l'hôte peut effectuer cette action")
 	ErrGameInProgress  = errors.New("une partie est déjà en cours")
 	ErrInvalidRoomName = errors.New("nom de salle invalide (3-50 caractères)")
+	ErrGameNotFound    = errors.New("partie introuvable")
+	ErrPlayerNotFound  = errors.New("joueur absent de la salle")
 )
 
 const (
-	MaxPlayersPerRoom   = 10
+	MaxPlayersPerRoom   = 8
+	MaxSpectators       = 20
 	RoomCodeLength      = 6
 	InactiveRoomTimeout = 2 * time.Hour
 )
 
 type Manager struct {
-	rooms map[string]*models.Room
-	codes map[string]string
-	mutex sync.RWMutex
-	db    *sql.DB
+	rooms  map[string]*models.Room
+	codes  map[string]string
+	mutex  sync.RWMutex
+	store  RoomStore
+	scores ScoreStore
 }
 
 var (
@@ -41,18 +44,35 @@ var (
 	managerOnce     sync.Once
 )
 
-func GetManager() *Manager {
+func NewManager(store RoomStore, scores ScoreStore) *Manager {
+	return &Manager{
+		rooms:  make(map[string]*models.Room),
+		codes:  make(map[string]string),
+		store:  store,
+		scores: scores,
+	}
+}
+
+// InitManager crée le manager partagé avec ses stockages. Il doit être appelé
+// depuis main avant tout GetManager ; les appels suivants sont sans effet.
+// Les tests construisent leur propre manager avec NewManager.
+func InitManager(store RoomStore, scores ScoreStore) *Manager {
 	managerOnce.Do(func() {
-		managerInstance = &Manager{
-			rooms: make(map[string]*models.Room),
-			codes: make(map[string]string),
-			db:    database.GetDB(),
-		}
+		managerInstance = NewManager(store, scores)
 		go managerInstance.cleanupInactiveRooms()
 	})
 	return managerInstance
 }
 
+// GetManager renvoie le manager partagé. Il panique si InitManager n'a pas
+// été appelé : un manager créé implicitement perdrait la persistance.
+func GetManager() *Manager {
+	if managerInstance == nil {
+		log.Panic("[Rooms] GetManager appelé avant InitManager")
+	}
+	return managerInstance
+}
+
 func (m *Manager) CreateRoom(roomName string, hostID int64, hostPseudo string, gameType models.GameType) (*models.Room, error) {
 	roomName = strings.TrimSpace(roomName)
 	if len(roomName) < 3 || len(roomName) > 50 {
@@ -72,16 +92,11 @@ func (m *Manager) CreateRoom(roomName string, hostID int64, hostPseudo string, g
 		return nil, err
 	}
 
-	config := models.GameConfig{}
-	switch gameType {
-	case models.GameTypeBlindTest:
-		config.Playlist = "Pop"
-		config.TimePerRound = models.BlindTestDefaultTime
-	case models.GameTypePetitBac:
-		config.Categories = models.DefaultPetitBacCategories
-		config.NbRounds = models.NbrsManche
-		config.UsedLetters = []string{}
+	engine, err := games.GetRegistry().Get(gameType)
+	if err != nil {
+		return nil, err
 	}
+	config := engine.DefaultConfig()
 
 	room := &models.Room{
 		ID:       roomID,
@@ -100,23 +115,16 @@ func (m *Manager) CreateRoom(roomName string, hostID int64, hostPseudo string, g
 				Connected: true,
 			},
 		},
-		Config:    config,
-		CreatedAt: time.Now(),
+		Spectators: make(map[int64]*models.Spectator),
+		Config:     config,
+		CreatedAt:  time.Now(),
 	}
 
 	m.rooms[roomID] = room
 	m.codes[code] = roomID
 
-	if m.db != nil {
-		_, err = m.db.Exec(`
-			INSERT INTO rooms (id, code, name, host_id, game_type, status, created_at) 
-			VALUES (?, ?, ?, ?, ?, ?, ?)`,
-			roomID, code, roomName, hostID, string(gameType), string(models.RoomStatusWaiting), room.CreatedAt,
-		)
-		if err != nil {
-			log.Printf("[Rooms] Erreur sauvegarde DB: %v", err)
-		}
-	}
+	m.saveRoom(room)
+	m.saveRoomPlayers(room)
 
 	log.Printf("[Rooms] Salle créée: %s (%s) par %s, type: %s", roomName, code, hostPseudo, gameType)
 	return room, nil
@@ -151,18 +159,21 @@ func (m *Manager) JoinRoom(roomID string, userID int64, pseudo string) (*models.
 	}
 
 	room.Mutex.Lock()
-	defer room.Mutex.Unlock()
-
-	if room.Status == models.RoomStatusPlaying {
-		return nil, ErrGameInProgress
-	}
 
+	// Un joueur déjà assis retrouve sa place, même pendant la partie ; sa
+	// présence est suivie par la connexion WebSocket.
 	if _, exists := room.Players[userID]; exists {
-		room.Players[userID].Connected = true
+		room.Mutex.Unlock()
 		return room, nil
 	}
 
+	if room.Status == models.RoomStatusPlaying {
+		room.Mutex.Unlock()
+		return nil, ErrGameInProgress
+	}
+
 	if len(room.Players) >= MaxPlayersPerRoom {
+		room.Mutex.Unlock()
 		return nil, ErrRoomFull
 	}
 
@@ -174,6 +185,11 @@ func (m *Manager) JoinRoom(roomID string, userID int64, pseudo string) (*models.
 		IsReady:   false,
 		Connected: true,
 	}
+	// Un spectateur qui rejoint la salle devient joueur.
+	delete(room.Spectators, userID)
+	room.Mutex.Unlock()
+
+	m.saveRoomPlayers(room)
 
 	log.Printf("[Rooms] %s a rejoint la salle %s", pseudo, room.Name)
 	return room, nil
@@ -205,19 +221,76 @@ func (m *Manager) LeaveRoom(roomID string, userID int64) error {
 	}
 
 	if wasHost {
-		for id, p := range room.Players {
-			p.IsHost = true
-			room.HostID = id
-			log.Printf("[Rooms] Nouvel hôte: %s", p.Pseudo)
-			break
-		}
+		newHost := pickNewHost(room)
+		newHost.IsHost = true
+		room.HostID = newHost.UserID
+		log.Printf("[Rooms] Nouvel hôte: %s", newHost.Pseudo)
 	}
 
 	room.Mutex.Unlock()
+
+	if wasHost {
+		m.saveRoom(room)
+	}
+	m.saveRoomPlayers(room)
 	return nil
 }
 
-func (m *Manager) SetPlayerReady(roomID string, userID int64, ready bool) error {
+// AddSpectator ajoute un spectateur, y compris pendant une partie. Un joueur
+// de la salle reste joueur.
+func (m *Manager) AddSpectator(roomID string, userID int64, pseudo string) (*models.Room, error) {
+	room, err := m.GetRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	room.Mutex.Lock()
+	defer room.Mutex.Unlock()
+
+	if _, exists := room.Players[userID]; exists {
+		return nil, ErrAlreadyInRoom
+	}
+
+	if room.Spectators == nil {
+		room.Spectators = make(map[int64]*models.Spectator)
+	}
+
+	if spectator, exists := room.Spectators[userID]; exists {
+		spectator.Connected = true
+		return room, nil
+	}
+
+	if len(room.Spectators) >= MaxSpectators {
+		return nil, ErrRoomFull
+	}
+
+	room.Spectators[userID] = &models.Spectator{
+		UserID:    userID,
+		Pseudo:    pseudo,
+		Connected: true,
+	}
+
+	log.Printf("[Rooms] %s regarde la salle %s", pseudo, room.Name)
+	return room, nil
+}
+
+func (m *Manager) RemoveSpectator(roomID string, userID int64) bool {
+	room, err := m.GetRoom(roomID)
+	if err != nil {
+		return false
+	}
+
+	room.Mutex.Lock()
+	defer room.Mutex.Unlock()
+
+	if _, exists := room.Spectators[userID]; !exists {
+		return false
+	}
+	delete(room.Spectators, userID)
+	return true
+}
+
+func (m *Manager) SetSpectatorConnected(roomID string, userID int64, connected bool) error {
 	room, err := m.GetRoom(roomID)
 	if err != nil {
 		return err
@@ -226,12 +299,92 @@ func (m *Manager) SetPlayerReady(roomID string, userID int64, ready bool) error
 	room.Mutex.Lock()
 	defer room.Mutex.Unlock()
 
+	spectator, exists := room.Spectators[userID]
+	if !exists {
+		return ErrPlayerNotFound
+	}
+
+	spectator.Connected = connected
+	return nil
+}
+
+func (m *Manager) IsSpectator(roomID string, userID int64) bool {
+	room, err := m.GetRoom(roomID)
+	if err != nil {
+		return false
+	}
+
+	room.Mutex.RLock()
+	defer room.Mutex.RUnlock()
+
+	_, exists := room.Spectators[userID]
+	return exists
+}
+
+// IsMember indique si l'utilisateur est joueur ou spectateur de la salle.
+func (m *Manager) IsMember(roomID string, userID int64) bool {
+	room, err := m.GetRoom(roomID)
+	if err != nil {
+		return false
+	}
+
+	room.Mutex.RLock()
+	defer room.Mutex.RUnlock()
+
+	_, isPlayer := room.Players[userID]
+	_, isSpectator := room.Spectators[userID]
+	return isPlayer || isSpectator
+}
+
+// pickNewHost choisit un joueur connecté de préférence, puis le plus petit
+// ID pour que le choix soit stable. La salle doit être verrouillée et non vide.
+func pickNewHost(room *models.Room) *models.Player {
+	var best *models.Player
+	for _, p := range room.Players {
+		if best == nil ||
+			(p.Connected && !best.Connected) ||
+			(p.Connected == best.Connected && p.UserID < best.UserID) {
+			best = p
+		}
+	}
+	return best
+}
+
+func (m *Manager) SetPlayerConnected(roomID string, userID int64, connected bool) error {
+	room, err := m.GetRoom(roomID)
+	if err != nil {
+		return err
+	}
+
+	room.Mutex.Lock()
 	player, exists := room.Players[userID]
 	if !exists {
-		return ErrRoomNotFound
+		room.Mutex.Unlock()
+		return ErrPlayerNotFound
+	}
+	player.Connected = connected
+	room.Mutex.Unlock()
+
+	m.saveRoomPlayers(room)
+	return nil
+}
+
+func (m *Manager) SetPlayerReady(roomID string, userID int64, ready bool) error {
+	room, err := m.GetRoom(roomID)
+	if err != nil {
+		return err
 	}
 
+	room.Mutex.Lock()
+	player, exists := room.Players[userID]
+	if !exists {
+		room.Mutex.Unlock()
+		return ErrRoomNotFound
+	}
 	player.IsReady = ready
+	room.Mutex.Unlock()
+
+	m.saveRoomPlayers(room)
 	return nil
 }
 
@@ -245,12 +398,7 @@ func (m *Manager) UpdateRoomStatus(roomID string, status models.RoomStatus) erro
 	room.Status = status
 	room.Mutex.Unlock()
 
-	if m.db != nil {
-		_, err = m.db.Exec("UPDATE rooms SET status = ? WHERE id = ?", string(status), roomID)
-		if err != nil {
-			log.Printf("[Rooms] Erreur mise à jour statut DB: %v", err)
-		}
-	}
+	m.saveRoom(room)
 
 	statusInfo := status.GetStatusInfo()
 	log.Printf("[Rooms] Salle %s -> %s", room.Name, statusInfo.Label)
@@ -293,11 +441,8 @@ func (m *Manager) DeleteRoom(roomID string) error {
 	delete(m.codes, room.Code)
 	delete(m.rooms, roomID)
 
-	if m.db != nil {
-		_, err := m.db.Exec("DELETE FROM rooms WHERE id = ?", roomID)
-		if err != nil {
-			log.Printf("[Rooms] Erreur suppression DB: %v", err)
-		}
+	if err := m.store.DeleteRoom(roomID); err != nil {
+		log.Printf("[Rooms] Erreur suppression DB: %v", err)
 	}
 
 	log.Printf("[Rooms] Salle supprimée: %s", room.Name)
@@ -311,12 +456,12 @@ func (m *Manager) ResetPlayerScores(roomID string) error {
 	}
 
 	room.Mutex.Lock()
-	defer room.Mutex.Unlock()
-
 	for _, player := range room.Players {
 		player.Score = 0
 	}
+	room.Mutex.Unlock()
 
+	m.saveRoomPlayers(room)
 	return nil
 }
 
@@ -327,17 +472,94 @@ func (m *Manager) AddPlayerScore(roomID string, userID int64, points int) error
 	}
 
 	room.Mutex.Lock()
-	defer room.Mutex.Unlock()
-
 	player, exists := room.Players[userID]
 	if !exists {
+		room.Mutex.Unlock()
 		return ErrRoomNotFound
 	}
-
 	player.Score += points
+	room.Mutex.Unlock()
+
+	m.saveRoomPlayers(room)
 	return nil
 }
 
+func (m *Manager) UpdateRoomConfig(roomID string, config models.GameConfig) error {
+	room, err := m.GetRoom(roomID)
+	if err != nil {
+		return err
+	}
+
+	room.Mutex.Lock()
+	room.Config = config
+	room.Mutex.Unlock()
+
+	m.saveRoom(room)
+	return nil
+}
+
+func (m *Manager) RestoreRooms() error {
+	loaded, err := m.store.LoadRooms()
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	interrupted := make([]*models.Room, 0)
+	empty := make([]string, 0)
+	restored := 0
+	for _, room := range loaded {
+		if len(room.Players) == 0 {
+			empty = append(empty, room.ID)
+			continue
+		}
+		if room.Status == models.RoomStatusPlaying {
+			room.Status = models.RoomStatusInterrupted
+			interrupted = append(interrupted, room)
+		}
+		m.rooms[room.ID] = room
+		m.codes[room.Code] = room.ID
+		restored++
+	}
+	m.mutex.Unlock()
+
+	for _, room := range interrupted {
+		m.saveRoom(room)
+		log.Printf("[Rooms] Salle %s (%s) marquée comme interrompue", room.Name, room.Code)
+	}
+
+	for _, roomID := range empty {
+		if err := m.store.DeleteRoom(roomID); err != nil {
+			log.Printf("[Rooms] Erreur suppression salle vide %s: %v", roomID, err)
+		}
+	}
+
+	log.Printf("[Rooms] %d salle(s) restaurée(s) depuis la base", restored)
+	return nil
+}
+
+// RecordGameScores enregistre la partie terminée dans la salle, avec les
+// points de chaque joueur manche par manche.
+func (m *Manager) RecordGameScores(roomID string, roundScores map[int64][]int) error {
+	room, err := m.GetRoom(roomID)
+	if err != nil {
+		return err
+	}
+	return m.scores.SaveGameScores(room, roundScores)
+}
+
+func (m *Manager) saveRoom(room *models.Room) {
+	if err := m.store.SaveRoom(room); err != nil {
+		log.Printf("[Rooms] Erreur sauvegarde salle %s: %v", room.Code, err)
+	}
+}
+
+func (m *Manager) saveRoomPlayers(room *models.Room) {
+	if err := m.store.SaveRoomPlayers(room); err != nil {
+		log.Printf("[Rooms] Erreur sauvegarde joueurs %s: %v", room.Code, err)
+	}
+}
+
 func (m *Manager) GetPlayer(roomID string, userID int64) (*models.Player, error) {
 	room, err := m.GetRoom(roomID)
 	if err != nil {
@@ -402,32 +624,39 @@ func (m *Manager) cleanupInactiveRooms() {
 	ticker := time.NewTicker(30 * time.Minute)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		m.mutex.Lock()
-		now := time.Now()
-		toDelete := []string{}
-
-		for id, room := range m.rooms {
-			if room.Status == models.RoomStatusFinished {
-				toDelete = append(toDelete, id)
-			} else if room.Status == models.RoomStatusWaiting && now.Sub(room.CreatedAt) > InactiveRoomTimeout {
-				toDelete = append(toDelete, id)
-			}
+	for now := range ticker.C {
+		if removed := m.removeInactiveRooms(now); removed > 0 {
+			log.Printf("[Rooms] Nettoyage: %d salle(s) supprimée(s)", removed)
 		}
+	}
+}
 
-		for _, id := range toDelete {
-			room := m.rooms[id]
-			delete(m.codes, room.Code)
-			delete(m.rooms, id)
-			log.Printf("[Rooms] Salle inactive supprimée: %s", room.Name)
-		}
+// removeInactiveRooms retire les salles terminées ou abandonnées et les
+// supprime du stockage, qui garde celles ayant des parties enregistrées.
+func (m *Manager) removeInactiveRooms(now time.Time) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 
-		m.mutex.Unlock()
+	toDelete := []string{}
+	for id, room := range m.rooms {
+		if room.Status == models.RoomStatusFinished {
+			toDelete = append(toDelete, id)
+		} else if (room.Status == models.RoomStatusWaiting || room.Status == models.RoomStatusInterrupted) && now.Sub(room.CreatedAt) > InactiveRoomTimeout {
+			toDelete = append(toDelete, id)
+		}
+	}
 
-		if len(toDelete) > 0 {
-			log.Printf("[Rooms] Nettoyage: %d salle(s) supprimée(s)", len(toDelete))
+	for _, id := range toDelete {
+		room := m.rooms[id]
+		delete(m.codes, room.Code)
+		delete(m.rooms, id)
+		if err := m.store.DeleteRoom(id); err != nil {
+			log.Printf("[Rooms] Erreur suppression DB: %v", err)
 		}
+		log.Printf("[Rooms] Salle inactive supprimée: %s", room.Name)
 	}
+
+	return len(toDelete)
 }
 
 func (m *Manager) StartGame(roomID string) error {
@@ -0,0 +1,268 @@
+package rooms
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"groupie-tracker/internal/games"
+	"groupie-tracker/internal/models"
+)
+
+const testGameType models.GameType = "test"
+
+// testEngine est le moteur minimal dont CreateRoom a besoin pour la config
+// par défaut.
+type testEngine struct{}
+
+func (testEngine) GameType() models.GameType                  { return testGameType }
+func (testEngine) DisplayName() string                        { return "Test" }
+func (testEngine) MessageTypes() []models.WSMessageType       { return nil }
+func (testEngine) Template() string                           { return "" }
+func (testEngine) DefaultConfig() models.GameConfig           { return models.GameConfig{NbRounds: 3} }
+func (testEngine) ParseConfig(url.Values, *models.GameConfig) {}
+func (testEngine) ValidateConfig(*models.GameConfig) error    { return nil }
+func (testEngine) Start(*models.Room, map[string]interface{}) error {
+	return nil
+}
+func (testEngine) Stop(string) {}
+func (testEngine) Snapshot(string, int64) map[string]interface{} {
+	return nil
+}
+
+func newTestManager(t *testing.T) (*Manager, *MemoryStore) {
+	t.Helper()
+	games.GetRegistry().Register(testEngine{})
+	store := NewMemoryStore()
+	return NewManager(store, store), store
+}
+
+func TestManagerPersistsToStore(t *testing.T) {
+	manager, store := newTestManager(t)
+
+	room, err := manager.CreateRoom("Salle de test", 1, "Alice", testGameType)
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+	if room.Config.NbRounds != 3 {
+		t.Errorf("config par défaut non appliquée: %+v", room.Config)
+	}
+	if _, err := manager.JoinRoom(room.ID, 2, "Bob"); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	if err := manager.AddPlayerScore(room.ID, 2, 40); err != nil {
+		t.Fatalf("AddPlayerScore: %v", err)
+	}
+
+	saved, err := store.LoadRooms()
+	if err != nil {
+		t.Fatalf("LoadRooms: %v", err)
+	}
+	if len(saved) != 1 || saved[0].Code != room.Code {
+		t.Fatalf("salle non enregistrée: %+v", saved)
+	}
+	if bob := saved[0].Players[2]; bob == nil || bob.Score != 40 {
+		t.Errorf("score de Bob non enregistré: %+v", saved[0].Players)
+	}
+
+	if err := manager.DeleteRoom(room.ID); err != nil {
+		t.Fatalf("DeleteRoom: %v", err)
+	}
+	if saved, _ := store.LoadRooms(); len(saved) != 0 {
+		t.Errorf("salle toujours enregistrée après suppression: %+v", saved)
+	}
+}
+
+func TestManagerRestoresRooms(t *testing.T) {
+	manager, store := newTestManager(t)
+
+	room, err := manager.CreateRoom("Salle interrompue", 1, "Alice", testGameType)
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+	if err := manager.UpdateRoomStatus(room.ID, models.RoomStatusPlaying); err != nil {
+		t.Fatalf("UpdateRoomStatus: %v", err)
+	}
+
+	// Un second manager sur le même stockage simule un redémarrage.
+	restarted := NewManager(store, store)
+	if err := restarted.RestoreRooms(); err != nil {
+		t.Fatalf("RestoreRooms: %v", err)
+	}
+	restored, err := restarted.GetRoomByCode(room.Code)
+	if err != nil {
+		t.Fatalf("salle non restaurée: %v", err)
+	}
+	if restored.Status != models.RoomStatusInterrupted {
+		t.Errorf("statut = %s, attendu %s", restored.Status, models.RoomStatusInterrupted)
+	}
+}
+
+func TestManagerRecordsGameScores(t *testing.T) {
+	manager, store := newTestManager(t)
+
+	room, err := manager.CreateRoom("Salle notée", 1, "Alice", testGameType)
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+	if err := manager.AddPlayerScore(room.ID, 1, 120); err != nil {
+		t.Fatalf("AddPlayerScore: %v", err)
+	}
+	if err := manager.RecordGameScores(room.ID, map[int64][]int{1: {50, 70}}); err != nil {
+		t.Fatalf("RecordGameScores: %v", err)
+	}
+
+	history, err := store.GetUserGameHistory(1, 10)
+	if err != nil {
+		t.Fatalf("GetUserGameHistory: %v", err)
+	}
+	if len(history) != 1 || history[0].Score != 120 || !history[0].Won {
+		t.Fatalf("historique inattendu: %+v", history)
+	}
+	if got := history[0].RoundScores; len(got) != 2 || got[0] != 50 || got[1] != 70 {
+		t.Errorf("scores par manche = %v", got)
+	}
+}
+
+func TestManagerJoinRoom(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  int64
+		status  models.RoomStatus
+		players int
+		err     error
+	}{
+		{"nouveau joueur", 3, models.RoomStatusWaiting, 0, nil},
+		{"spectateur promu", 9, models.RoomStatusWaiting, 0, nil},
+		{"joueur déjà assis pendant la partie", 2, models.RoomStatusPlaying, 0, nil},
+		{"partie en cours", 3, models.RoomStatusPlaying, 0, ErrGameInProgress},
+		{"salle pleine", 3, models.RoomStatusWaiting, MaxPlayersPerRoom, ErrRoomFull},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager, _ := newTestManager(t)
+			room, err := manager.CreateRoom("Salle ouverte", 1, "Alice", testGameType)
+			if err != nil {
+				t.Fatalf("CreateRoom: %v", err)
+			}
+			if _, err := manager.JoinRoom(room.ID, 2, "Bob"); err != nil {
+				t.Fatalf("JoinRoom: %v", err)
+			}
+			if _, err := manager.AddSpectator(room.ID, 9, "Ivy"); err != nil {
+				t.Fatalf("AddSpectator: %v", err)
+			}
+			for userID := int64(100); len(room.Players) < tt.players; userID++ {
+				if _, err := manager.JoinRoom(room.ID, userID, "Invité"); err != nil {
+					t.Fatalf("JoinRoom: %v", err)
+				}
+			}
+			if err := manager.UpdateRoomStatus(room.ID, tt.status); err != nil {
+				t.Fatalf("UpdateRoomStatus: %v", err)
+			}
+
+			if _, err := manager.JoinRoom(room.ID, tt.userID, "Nouveau"); err != tt.err {
+				t.Fatalf("JoinRoom = %v, attendu %v", err, tt.err)
+			}
+			_, isPlayer := room.Players[tt.userID]
+			_, isSpectator := room.Spectators[tt.userID]
+			if isPlayer != (tt.err == nil) {
+				t.Errorf("joueur assis = %v, attendu %v", isPlayer, tt.err == nil)
+			}
+			if isPlayer && isSpectator {
+				t.Errorf("joueur %d toujours spectateur", tt.userID)
+			}
+		})
+	}
+}
+
+// countingStore compte les écritures pour vérifier que le manager
+// répercute chaque changement sur le stockage.
+type countingStore struct {
+	*MemoryStore
+	playerSaves int
+	deleted     []string
+}
+
+func (s *countingStore) SaveRoomPlayers(room *models.Room) error {
+	s.playerSaves++
+	return s.MemoryStore.SaveRoomPlayers(room)
+}
+
+func (s *countingStore) DeleteRoom(roomID string) error {
+	s.deleted = append(s.deleted, roomID)
+	return s.MemoryStore.DeleteRoom(roomID)
+}
+
+func TestManagerWritesPlayerChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(m *Manager, roomID string) error
+	}{
+		{"prêt", func(m *Manager, roomID string) error { return m.SetPlayerReady(roomID, 2, true) }},
+		{"déconnecté", func(m *Manager, roomID string) error { return m.SetPlayerConnected(roomID, 2, false) }},
+		{"reconnecté", func(m *Manager, roomID string) error { return m.SetPlayerConnected(roomID, 2, true) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			games.GetRegistry().Register(testEngine{})
+			store := &countingStore{MemoryStore: NewMemoryStore()}
+			manager := NewManager(store, store)
+
+			room, err := manager.CreateRoom("Salle suivie", 1, "Alice", testGameType)
+			if err != nil {
+				t.Fatalf("CreateRoom: %v", err)
+			}
+			if _, err := manager.JoinRoom(room.ID, 2, "Bob"); err != nil {
+				t.Fatalf("JoinRoom: %v", err)
+			}
+
+			before := store.playerSaves
+			if err := tt.change(manager, room.ID); err != nil {
+				t.Fatalf("changement: %v", err)
+			}
+			if store.playerSaves != before+1 {
+				t.Errorf("%d écriture(s) des joueurs, attendu 1", store.playerSaves-before)
+			}
+		})
+	}
+}
+
+func TestManagerRemovesInactiveRooms(t *testing.T) {
+	games.GetRegistry().Register(testEngine{})
+	store := &countingStore{MemoryStore: NewMemoryStore()}
+	manager := NewManager(store, store)
+
+	finished, err := manager.CreateRoom("Salle terminée", 1, "Alice", testGameType)
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+	if err := manager.RecordGameScores(finished.ID, nil); err != nil {
+		t.Fatalf("RecordGameScores: %v", err)
+	}
+	if err := manager.EndGame(finished.ID); err != nil {
+		t.Fatalf("EndGame: %v", err)
+	}
+	active, err := manager.CreateRoom("Salle active", 2, "Bob", testGameType)
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+
+	if removed := manager.removeInactiveRooms(time.Now()); removed != 1 {
+		t.Fatalf("%d salle(s) retirée(s), attendu 1", removed)
+	}
+	if len(store.deleted) != 1 || store.deleted[0] != finished.ID {
+		t.Errorf("salles supprimées du stockage = %v, attendu [%s]", store.deleted, finished.ID)
+	}
+	if _, err := manager.GetRoom(active.ID); err != nil {
+		t.Errorf("salle active retirée: %v", err)
+	}
+
+	if removed := manager.removeInactiveRooms(time.Now().Add(InactiveRoomTimeout + time.Minute)); removed != 1 {
+		t.Errorf("salle abandonnée non retirée")
+	}
+	if history, _ := store.GetUserGameHistory(1, 10); len(history) != 1 {
+		t.Errorf("historique perdu avec la salle: %+v", history)
+	}
+}
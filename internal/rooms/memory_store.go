@@ -0,0 +1,438 @@
+package rooms
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"groupie-tracker/internal/models"
+)
+
+// MemoryStore implémente RoomStore et ScoreStore en mémoire, pour les tests
+// ou un serveur sans base de données.
+type MemoryStore struct {
+	rooms      map[string]*memoryRoom
+	scores     []memoryScore
+	nextID     int64
+	nextGameID int64
+	mutex      sync.RWMutex
+}
+
+type memoryRoom struct {
+	id        string
+	code      string
+	name      string
+	hostID    int64
+	gameType  models.GameType
+	status    models.RoomStatus
+	config    []byte
+	createdAt time.Time
+	players   []models.Player
+}
+
+type memoryScore struct {
+	id          int64
+	gameID      int64
+	roomID      string
+	roomName    string
+	userID      int64
+	pseudo      string
+	gameType    models.GameType
+	score       int
+	roundScores []int
+	playedAt    time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		rooms: make(map[string]*memoryRoom),
+	}
+}
+
+func (s *MemoryStore) SaveRoom(room *models.Room) error {
+	room.Mutex.RLock()
+	configJSON, err := json.Marshal(room.Config)
+	saved := &memoryRoom{
+		id:        room.ID,
+		code:      room.Code,
+		name:      room.Name,
+		hostID:    room.HostID,
+		gameType:  room.GameType,
+		status:    room.Status,
+		config:    configJSON,
+		createdAt: room.CreatedAt,
+	}
+	room.Mutex.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if existing, exists := s.rooms[room.ID]; exists {
+		saved.players = existing.players
+	}
+	s.rooms[room.ID] = saved
+	return nil
+}
+
+func (s *MemoryStore) SaveRoomPlayers(room *models.Room) error {
+	room.Mutex.RLock()
+	players := make([]models.Player, 0, len(room.Players))
+	for _, player := range room.Players {
+		players = append(players, models.Player{
+			UserID: player.UserID,
+			Pseudo: player.Pseudo,
+			Score:  player.Score,
+			IsHost: player.IsHost,
+		})
+	}
+	room.Mutex.RUnlock()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if saved, exists := s.rooms[room.ID]; exists {
+		saved.players = players
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteRoom(roomID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	saved, exists := s.rooms[roomID]
+	if !exists {
+		return nil
+	}
+
+	// Comme en SQLite, une salle qui a des parties enregistrées est conservée
+	for _, record := range s.scores {
+		if record.roomID == roomID {
+			saved.players = nil
+			saved.status = models.RoomStatusFinished
+			return nil
+		}
+	}
+
+	delete(s.rooms, roomID)
+	return nil
+}
+
+func (s *MemoryStore) LoadRooms() ([]*models.Room, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	loaded := make([]*models.Room, 0, len(s.rooms))
+	for _, saved := range s.rooms {
+		if saved.status == models.RoomStatusFinished {
+			continue
+		}
+
+		room := &models.Room{
+			ID:        saved.id,
+			Code:      saved.code,
+			Name:      saved.name,
+			HostID:    saved.hostID,
+			GameType:  saved.gameType,
+			Status:    saved.status,
+			Players:   make(map[int64]*models.Player),
+			CreatedAt: saved.createdAt,
+		}
+		if err := json.Unmarshal(saved.config, &room.Config); err != nil {
+			return nil, err
+		}
+		for _, player := range saved.players {
+			p := player
+			p.IsReady = p.IsHost
+			room.Players[p.UserID] = &p
+		}
+
+		loaded = append(loaded, room)
+	}
+
+	return loaded, nil
+}
+
+func (s *MemoryStore) SaveGameScores(room *models.Room, roundScores map[int64][]int) error {
+	room.Mutex.RLock()
+	defer room.Mutex.RUnlock()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	playedAt := time.Now().UTC().Truncate(time.Second)
+	s.nextGameID++
+	for userID, player := range room.Players {
+		rounds := append([]int{}, roundScores[userID]...)
+
+		s.nextID++
+		s.scores = append(s.scores, memoryScore{
+			id:          s.nextID,
+			gameID:      s.nextGameID,
+			roomID:      room.ID,
+			roomName:    room.Name,
+			userID:      userID,
+			pseudo:      player.Pseudo,
+			gameType:    room.GameType,
+			score:       player.Score,
+			roundScores: rounds,
+			playedAt:    playedAt,
+		})
+	}
+
+	return nil
+}
+
+// gamePlayers renvoie toutes les lignes d'une partie.
+func (s *MemoryStore) gamePlayers(gameID int64) []memoryScore {
+	players := []memoryScore{}
+	for _, record := range s.scores {
+		if record.gameID == gameID {
+			players = append(players, record)
+		}
+	}
+	return players
+}
+
+func (s *MemoryStore) rankInGame(record memoryScore) (rank int, players int) {
+	rank = 1
+	for _, other := range s.gamePlayers(record.gameID) {
+		if other.score > record.score {
+			rank++
+		}
+		players++
+	}
+	return rank, players
+}
+
+func (s *MemoryStore) GetUserGameHistory(userID int64, limit int) ([]GameHistoryEntry, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	records := []memoryScore{}
+	for _, record := range s.scores {
+		if record.userID == userID {
+			records = append(records, record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].playedAt.Equal(records[j].playedAt) {
+			return records[i].playedAt.After(records[j].playedAt)
+		}
+		return records[i].id > records[j].id
+	})
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+
+	history := []GameHistoryEntry{}
+	for _, record := range records {
+		rank, players := s.rankInGame(record)
+		history = append(history, GameHistoryEntry{
+			ID:          record.id,
+			GameID:      record.gameID,
+			RoomID:      record.roomID,
+			RoomName:    record.roomName,
+			GameType:    record.gameType,
+			Score:       record.score,
+			RoundScores: append([]int{}, record.roundScores...),
+			Rank:        rank,
+			Players:     players,
+			Won:         rank == 1 && record.score > 0,
+			PlayedAt:    record.playedAt.Format(time.RFC3339),
+		})
+	}
+
+	return history, nil
+}
+
+func (s *MemoryStore) GetUserGameStats(userID int64) ([]GameTypeStats, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	byType := make(map[models.GameType]*GameTypeStats)
+	for _, record := range s.scores {
+		if record.userID != userID {
+			continue
+		}
+
+		entry, exists := byType[record.gameType]
+		if !exists {
+			entry = &GameTypeStats{GameType: record.gameType, BestScore: record.score}
+			byType[record.gameType] = entry
+		}
+		entry.GamesPlayed++
+		entry.TotalScore += record.score
+		if record.score > entry.BestScore {
+			entry.BestScore = record.score
+		}
+		if rank, _ := s.rankInGame(record); rank == 1 && record.score > 0 {
+			entry.Wins++
+		}
+	}
+
+	stats := []GameTypeStats{}
+	for _, entry := range byType {
+		entry.AverageScore = float64(entry.TotalScore) / float64(entry.GamesPlayed)
+		entry.WinRate = float64(entry.Wins) * 100 / float64(entry.GamesPlayed)
+		stats = append(stats, *entry)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].GameType < stats[j].GameType })
+
+	return stats, nil
+}
+
+func (s *MemoryStore) GetGameDetails(gameID int64) (*GameDetails, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	records := s.gamePlayers(gameID)
+	if len(records) == 0 {
+		return nil, ErrGameNotFound
+	}
+	ref := records[0]
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].score != records[j].score {
+			return records[i].score > records[j].score
+		}
+		return records[i].pseudo < records[j].pseudo
+	})
+
+	details := &GameDetails{
+		ID:       gameID,
+		RoomID:   ref.roomID,
+		RoomName: ref.roomName,
+		GameType: ref.gameType,
+		PlayedAt: ref.playedAt.Format(time.RFC3339),
+		Players:  []GamePlayerScore{},
+	}
+
+	rank, previous := 0, -1
+	for i, record := range records {
+		if record.score != previous {
+			rank = i + 1
+			previous = record.score
+		}
+		if len(record.roundScores) > details.Rounds {
+			details.Rounds = len(record.roundScores)
+		}
+		details.Players = append(details.Players, GamePlayerScore{
+			UserID:      record.userID,
+			Pseudo:      record.pseudo,
+			Score:       record.score,
+			Rank:        rank,
+			RoundScores: append([]int{}, record.roundScores...),
+		})
+	}
+
+	return details, nil
+}
+
+// leaderboard reproduit en mémoire le classement calculé par leaderboardQuery.
+func (s *MemoryStore) leaderboard(filter LeaderboardFilter) []LeaderboardEntry {
+	var since time.Time
+	switch filter.Period {
+	case LeaderboardPeriodMonth:
+		since = time.Now().AddDate(0, -1, 0)
+	case LeaderboardPeriodWeek:
+		since = time.Now().AddDate(0, 0, -7)
+	}
+
+	filtered := []memoryScore{}
+	best := make(map[int64]int)
+	for _, record := range s.scores {
+		if filter.GameType != "" && record.gameType != filter.GameType {
+			continue
+		}
+		if record.playedAt.Before(since) {
+			continue
+		}
+		filtered = append(filtered, record)
+		if current, exists := best[record.gameID]; !exists || record.score > current {
+			best[record.gameID] = record.score
+		}
+	}
+
+	byUser := make(map[int64]*LeaderboardEntry)
+	for _, record := range filtered {
+		entry, exists := byUser[record.userID]
+		if !exists {
+			entry = &LeaderboardEntry{UserID: record.userID, Pseudo: record.pseudo}
+			byUser[record.userID] = entry
+		}
+		entry.TotalScore += record.score
+		entry.GamesPlayed++
+		if top := best[record.gameID]; top > 0 && record.score == top {
+			entry.Wins++
+		}
+	}
+
+	entries := make([]LeaderboardEntry, 0, len(byUser))
+	for _, entry := range byUser {
+		entry.AverageScore = float64(entry.TotalScore) / float64(entry.GamesPlayed)
+		entries = append(entries, *entry)
+	}
+
+	metric := func(entry LeaderboardEntry) float64 {
+		switch filter.Metric {
+		case LeaderboardMetricAverage:
+			return entry.AverageScore
+		case LeaderboardMetricWins:
+			return float64(entry.Wins)
+		case LeaderboardMetricGames:
+			return float64(entry.GamesPlayed)
+		}
+		return float64(entry.TotalScore)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if metric(a) != metric(b) {
+			return metric(a) > metric(b)
+		}
+		if a.TotalScore != b.TotalScore {
+			return a.TotalScore > b.TotalScore
+		}
+		return a.Pseudo < b.Pseudo
+	})
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+
+	return entries
+}
+
+func (s *MemoryStore) GetLeaderboard(filter LeaderboardFilter) ([]LeaderboardEntry, int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	entries := s.leaderboard(filter)
+	total := len(entries)
+
+	start := filter.Offset
+	if start > total {
+		start = total
+	}
+	end := total
+	if filter.Limit > 0 && start+filter.Limit < end {
+		end = start + filter.Limit
+	}
+
+	return append([]LeaderboardEntry{}, entries[start:end]...), total, nil
+}
+
+func (s *MemoryStore) GetLeaderboardRank(userID int64, filter LeaderboardFilter) (*LeaderboardEntry, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, entry := range s.leaderboard(filter) {
+		if entry.UserID == userID {
+			return &entry, nil
+		}
+	}
+	return nil, nil
+}
@@ -3,20 +3,19 @@ package rooms
 import (
 	"database/sql"
 	"encoding/json"
+	"time"
 
-	"groupie-tracker/internal/database"
 	"groupie-tracker/internal/models"
 )
 
+// PersistenceService est l'implémentation SQLite de RoomStore et ScoreStore.
 type PersistenceService struct {
-	db      *sql.DB
-	manager *Manager
+	db *sql.DB
 }
 
-func NewPersistenceService() *PersistenceService {
+func NewPersistenceService(db *sql.DB) *PersistenceService {
 	return &PersistenceService{
-		db:      database.GetDB(),
-		manager: GetManager(),
+		db: db,
 	}
 }
 
@@ -41,13 +40,19 @@ func (s *PersistenceService) SaveRoomPlayers(room *models.Room) error {
 	room.Mutex.RLock()
 	defer room.Mutex.RUnlock()
 
-	_, err := s.db.Exec("DELETE FROM room_players WHERE room_id = ?", room.ID)
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM room_players WHERE room_id = ?", room.ID)
 	if err != nil {
 		return err
 	}
 
 	for _, player := range room.Players {
-		_, err := s.db.Exec(`
+		_, err := tx.Exec(`
 			INSERT INTO room_players (room_id, user_id, score, is_host)
 			VALUES (?, ?, ?, ?)
 		`, room.ID, player.UserID, player.Score, player.IsHost)
@@ -56,35 +61,160 @@ func (s *PersistenceService) SaveRoomPlayers(room *models.Room) error {
 		}
 	}
 
-	return nil
+	return tx.Commit()
+}
+
+func (s *PersistenceService) DeleteRoom(roomID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM room_players WHERE room_id = ?", roomID); err != nil {
+		return err
+	}
+	// Une salle qui a des parties enregistrées reste en base pour l'historique
+	if _, err := tx.Exec(`
+		DELETE FROM rooms
+		WHERE id = ? AND NOT EXISTS (SELECT 1 FROM game_scores WHERE room_id = ?)
+	`, roomID, roomID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE rooms SET status = ? WHERE id = ?", models.RoomStatusFinished, roomID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *PersistenceService) LoadRooms() ([]*models.Room, error) {
+	rows, err := s.db.Query(`
+		SELECT id, code, name, host_id, game_type, status, config, created_at
+		FROM rooms
+		WHERE status != ?
+	`, models.RoomStatusFinished)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var loaded []*models.Room
+	for rows.Next() {
+		room := &models.Room{Players: make(map[int64]*models.Player)}
+		var gameType, status string
+		var configJSON sql.NullString
+
+		err := rows.Scan(&room.ID, &room.Code, &room.Name, &room.HostID, &gameType, &status, &configJSON, &room.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		room.GameType = models.GameType(gameType)
+		room.Status = models.RoomStatus(status)
+		if configJSON.Valid && configJSON.String != "" {
+			if err := json.Unmarshal([]byte(configJSON.String), &room.Config); err != nil {
+				return nil, err
+			}
+		}
+
+		loaded = append(loaded, room)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, room := range loaded {
+		if err := s.loadRoomPlayers(room); err != nil {
+			return nil, err
+		}
+	}
+
+	return loaded, nil
 }
 
+func (s *PersistenceService) loadRoomPlayers(room *models.Room) error {
+	rows, err := s.db.Query(`
+		SELECT rp.user_id, u.pseudo, rp.score, rp.is_host
+		FROM room_players rp
+		JOIN users u ON rp.user_id = u.id
+		WHERE rp.room_id = ?
+	`, room.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var player models.Player
+		if err := rows.Scan(&player.UserID, &player.Pseudo, &player.Score, &player.IsHost); err != nil {
+			return err
+		}
+		player.IsReady = player.IsHost
+		room.Players[player.UserID] = &player
+	}
+
+	return rows.Err()
+}
+
+// SaveGameScores enregistre une partie terminée : une ligne dans games, dont
+// l'id regroupe ensuite les lignes de ses joueurs dans game_scores.
 func (s *PersistenceService) SaveGameScores(room *models.Room, roundScores map[int64][]int) error {
 	room.Mutex.RLock()
 	defer room.Mutex.RUnlock()
 
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	playedAt := time.Now().UTC().Format("2006-01-02 15:04:05")
+
+	result, err := tx.Exec(`
+		INSERT INTO games (room_id, game_type, created_at)
+		VALUES (?, ?, ?)
+	`, room.ID, room.GameType, playedAt)
+	if err != nil {
+		return err
+	}
+	gameID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
 	for userID, player := range room.Players {
-		roundScoresJSON, _ := json.Marshal(roundScores[userID])
-		
-		_, err := s.db.Exec(`
-			INSERT INTO game_scores (room_id, user_id, game_type, score, round_scores)
-			VALUES (?, ?, ?, ?, ?)
-		`, room.ID, userID, room.GameType, player.Score, string(roundScoresJSON))
+		rounds := roundScores[userID]
+		if rounds == nil {
+			rounds = []int{}
+		}
+		roundScoresJSON, _ := json.Marshal(rounds)
+
+		_, err := tx.Exec(`
+			INSERT INTO game_scores (game_id, room_id, user_id, game_type, score, round_scores, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, gameID, room.ID, userID, room.GameType, player.Score, string(roundScoresJSON), playedAt)
 		if err != nil {
 			return err
 		}
 	}
 
-	return nil
+	return tx.Commit()
 }
 
+// gameRankSQL classe une ligne de game_scores parmi celles de la même partie.
+const gameRankSQL = `(SELECT COUNT(*) FROM game_scores o
+	WHERE o.game_id = gs.game_id AND o.score > gs.score) + 1`
+
 func (s *PersistenceService) GetUserGameHistory(userID int64, limit int) ([]GameHistoryEntry, error) {
 	query := `
-		SELECT gs.room_id, r.name, gs.game_type, gs.score, gs.round_scores, gs.created_at
+		SELECT gs.id, gs.game_id, gs.room_id, COALESCE(r.name, ''), gs.game_type, gs.score, gs.round_scores, gs.created_at,
+			` + gameRankSQL + ` AS position,
+			(SELECT COUNT(*) FROM game_scores o WHERE o.game_id = gs.game_id) AS players
 		FROM game_scores gs
-		JOIN rooms r ON gs.room_id = r.id
+		LEFT JOIN rooms r ON gs.room_id = r.id
 		WHERE gs.user_id = ?
-		ORDER BY gs.created_at DESC
+		ORDER BY gs.created_at DESC, gs.id DESC
 		LIMIT ?
 	`
 
@@ -94,71 +224,311 @@ func (s *PersistenceService) GetUserGameHistory(userID int64, limit int) ([]Game
 	}
 	defer rows.Close()
 
-	var history []GameHistoryEntry
+	history := []GameHistoryEntry{}
 	for rows.Next() {
 		var entry GameHistoryEntry
-		var roundScoresJSON string
-		
-		err := rows.Scan(&entry.RoomID, &entry.RoomName, &entry.GameType, &entry.Score, &roundScoresJSON, &entry.PlayedAt)
+		var roundScoresJSON sql.NullString
+
+		err := rows.Scan(&entry.ID, &entry.GameID, &entry.RoomID, &entry.RoomName, &entry.GameType, &entry.Score,
+			&roundScoresJSON, &entry.PlayedAt, &entry.Rank, &entry.Players)
 		if err != nil {
 			return nil, err
 		}
-		
-		json.Unmarshal([]byte(roundScoresJSON), &entry.RoundScores)
+
+		entry.RoundScores = []int{}
+		if roundScoresJSON.Valid {
+			json.Unmarshal([]byte(roundScoresJSON.String), &entry.RoundScores)
+		}
+		entry.Won = entry.Rank == 1 && entry.Score > 0
 		history = append(history, entry)
 	}
 
-	return history, nil
+	return history, rows.Err()
 }
 
+// GameHistoryEntry est la ligne d'un joueur dans une partie. GameID
+// identifie la partie pour GetGameDetails.
 type GameHistoryEntry struct {
+	ID          int64           `json:"id"`
+	GameID      int64           `json:"game_id"`
 	RoomID      string          `json:"room_id"`
 	RoomName    string          `json:"room_name"`
 	GameType    models.GameType `json:"game_type"`
 	Score       int             `json:"score"`
 	RoundScores []int           `json:"round_scores"`
+	Rank        int             `json:"rank"`
+	Players     int             `json:"players"`
+	Won         bool            `json:"won"`
 	PlayedAt    string          `json:"played_at"`
 }
 
-func (s *PersistenceService) GetLeaderboard(gameType models.GameType, limit int) ([]LeaderboardEntry, error) {
-	query := `
-		SELECT u.id, u.pseudo, SUM(gs.score) as total_score, COUNT(gs.id) as games_played
+func (s *PersistenceService) GetUserGameStats(userID int64) ([]GameTypeStats, error) {
+	rows, err := s.db.Query(`
+		SELECT gs.game_type, COUNT(*), SUM(gs.score), MAX(gs.score), AVG(gs.score),
+			SUM(CASE WHEN gs.score > 0 AND `+gameRankSQL+` = 1 THEN 1 ELSE 0 END)
 		FROM game_scores gs
-		JOIN users u ON gs.user_id = u.id
-		WHERE gs.game_type = ?
-		GROUP BY u.id
-		ORDER BY total_score DESC
-		LIMIT ?
-	`
-
-	rows, err := s.db.Query(query, gameType, limit)
+		WHERE gs.user_id = ?
+		GROUP BY gs.game_type
+		ORDER BY gs.game_type
+	`, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var leaderboard []LeaderboardEntry
-	rank := 1
+	stats := []GameTypeStats{}
 	for rows.Next() {
-		var entry LeaderboardEntry
-		err := rows.Scan(&entry.UserID, &entry.Pseudo, &entry.TotalScore, &entry.GamesPlayed)
+		var entry GameTypeStats
+		err := rows.Scan(&entry.GameType, &entry.GamesPlayed, &entry.TotalScore,
+			&entry.BestScore, &entry.AverageScore, &entry.Wins)
 		if err != nil {
 			return nil, err
 		}
-		entry.Rank = rank
-		rank++
-		leaderboard = append(leaderboard, entry)
+		if entry.GamesPlayed > 0 {
+			entry.WinRate = float64(entry.Wins) * 100 / float64(entry.GamesPlayed)
+		}
+		stats = append(stats, entry)
 	}
 
-	return leaderboard, nil
+	return stats, rows.Err()
 }
 
-type LeaderboardEntry struct {
-	Rank        int    `json:"rank"`
+type GameTypeStats struct {
+	GameType     models.GameType `json:"game_type"`
+	GamesPlayed  int             `json:"games_played"`
+	TotalScore   int             `json:"total_score"`
+	BestScore    int             `json:"best_score"`
+	AverageScore float64         `json:"average_score"`
+	Wins         int             `json:"wins"`
+	WinRate      float64         `json:"win_rate"`
+}
+
+// GetGameDetails renvoie tous les joueurs d'une partie à partir de son
+// game_id.
+func (s *PersistenceService) GetGameDetails(gameID int64) (*GameDetails, error) {
+	rows, err := s.db.Query(`
+		SELECT gs.room_id, COALESCE(r.name, ''), gs.game_type, gs.created_at,
+			gs.user_id, COALESCE(u.pseudo, ''), gs.score, gs.round_scores
+		FROM game_scores gs
+		LEFT JOIN rooms r ON gs.room_id = r.id
+		LEFT JOIN users u ON gs.user_id = u.id
+		WHERE gs.game_id = ?
+		ORDER BY gs.score DESC, u.pseudo ASC
+	`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var details *GameDetails
+	rank, previous := 0, -1
+	for i := 0; rows.Next(); i++ {
+		var player GamePlayerScore
+		var roundScoresJSON sql.NullString
+		if details == nil {
+			details = &GameDetails{ID: gameID, Players: []GamePlayerScore{}}
+		}
+
+		err := rows.Scan(&details.RoomID, &details.RoomName, &details.GameType, &details.PlayedAt,
+			&player.UserID, &player.Pseudo, &player.Score, &roundScoresJSON)
+		if err != nil {
+			return nil, err
+		}
+
+		if player.Score != previous {
+			rank = i + 1
+			previous = player.Score
+		}
+		player.Rank = rank
+
+		player.RoundScores = []int{}
+		if roundScoresJSON.Valid {
+			json.Unmarshal([]byte(roundScoresJSON.String), &player.RoundScores)
+		}
+		if len(player.RoundScores) > details.Rounds {
+			details.Rounds = len(player.RoundScores)
+		}
+		details.Players = append(details.Players, player)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if details == nil {
+		return nil, ErrGameNotFound
+	}
+
+	return details, nil
+}
+
+type GameDetails struct {
+	ID       int64             `json:"id"`
+	RoomID   string            `json:"room_id"`
+	RoomName string            `json:"room_name"`
+	GameType models.GameType   `json:"game_type"`
+	Rounds   int               `json:"rounds"`
+	PlayedAt string            `json:"played_at"`
+	Players  []GamePlayerScore `json:"players"`
+}
+
+type GamePlayerScore struct {
 	UserID      int64  `json:"user_id"`
 	Pseudo      string `json:"pseudo"`
-	TotalScore  int    `json:"total_score"`
-	GamesPlayed int    `json:"games_played"`
+	Score       int    `json:"score"`
+	Rank        int    `json:"rank"`
+	RoundScores []int  `json:"round_scores"`
+}
+
+const (
+	LeaderboardPeriodAll   = "all"
+	LeaderboardPeriodMonth = "month"
+	LeaderboardPeriodWeek  = "week"
+
+	LeaderboardMetricTotal   = "total"
+	LeaderboardMetricAverage = "average"
+	LeaderboardMetricWins    = "wins"
+	LeaderboardMetricGames   = "games"
+)
+
+var leaderboardOrder = map[string]string{
+	LeaderboardMetricTotal:   "total_score DESC",
+	LeaderboardMetricAverage: "average_score DESC, total_score DESC",
+	LeaderboardMetricWins:    "wins DESC, total_score DESC",
+	LeaderboardMetricGames:   "games_played DESC, total_score DESC",
+}
+
+var leaderboardPeriods = map[string]string{
+	LeaderboardPeriodMonth: "datetime('now', '-1 month')",
+	LeaderboardPeriodWeek:  "datetime('now', '-7 days')",
+}
+
+// LeaderboardFilter décrit un classement. Un GameType vide couvre tous les jeux.
+type LeaderboardFilter struct {
+	GameType models.GameType
+	Period   string
+	Metric   string
+	Limit    int
+	Offset   int
+}
+
+// leaderboardQuery construit le classement complet dans une CTE "ranked".
+// Le meilleur score de chaque partie est calculé sur ses lignes (même
+// game_id) ; la victoire revient au(x) meilleur(s) score(s) non nul(s).
+func leaderboardQuery(filter LeaderboardFilter) (string, []interface{}) {
+	where := "1 = 1"
+	var args []interface{}
+
+	if filter.GameType != "" {
+		where += " AND game_type = ?"
+		args = append(args, filter.GameType)
+	}
+	if since, ok := leaderboardPeriods[filter.Period]; ok {
+		where += " AND created_at >= " + since
+	}
+
+	order, ok := leaderboardOrder[filter.Metric]
+	if !ok {
+		order = leaderboardOrder[LeaderboardMetricTotal]
+	}
+
+	query := `
+		WITH filtered AS (
+			SELECT id, user_id, score,
+				MAX(score) OVER (PARTITION BY game_id) AS best_score
+			FROM game_scores
+			WHERE ` + where + `
+		),
+		stats AS (
+			SELECT u.id AS user_id, u.pseudo,
+				SUM(f.score) AS total_score,
+				COUNT(f.id) AS games_played,
+				AVG(f.score) AS average_score,
+				SUM(CASE WHEN f.score = f.best_score AND f.best_score > 0 THEN 1 ELSE 0 END) AS wins
+			FROM filtered f
+			JOIN users u ON f.user_id = u.id
+			GROUP BY u.id
+		),
+		ranked AS (
+			SELECT ROW_NUMBER() OVER (ORDER BY ` + order + `, pseudo ASC) AS position,
+				user_id, pseudo, total_score, games_played, average_score, wins
+			FROM stats
+		)
+	`
+
+	return query, args
+}
+
+func (s *PersistenceService) GetLeaderboard(filter LeaderboardFilter) ([]LeaderboardEntry, int, error) {
+	base, args := leaderboardQuery(filter)
+
+	var total int
+	if err := s.db.QueryRow(base+"SELECT COUNT(*) FROM ranked", args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	pageArgs := append(append([]interface{}{}, args...), filter.Limit, filter.Offset)
+	rows, err := s.db.Query(base+`
+		SELECT position, user_id, pseudo, total_score, games_played, average_score, wins
+		FROM ranked
+		ORDER BY position
+		LIMIT ? OFFSET ?
+	`, pageArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	leaderboard := []LeaderboardEntry{}
+	for rows.Next() {
+		entry, err := scanLeaderboardEntry(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		leaderboard = append(leaderboard, *entry)
+	}
+
+	return leaderboard, total, rows.Err()
+}
+
+// GetLeaderboardRank renvoie la ligne d'un joueur dans le classement, ou nil
+// s'il n'a aucune partie correspondant au filtre.
+func (s *PersistenceService) GetLeaderboardRank(userID int64, filter LeaderboardFilter) (*LeaderboardEntry, error) {
+	base, args := leaderboardQuery(filter)
+	args = append(args, userID)
+
+	rows, err := s.db.Query(base+`
+		SELECT position, user_id, pseudo, total_score, games_played, average_score, wins
+		FROM ranked
+		WHERE user_id = ?
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	return scanLeaderboardEntry(rows)
+}
+
+func scanLeaderboardEntry(rows *sql.Rows) (*LeaderboardEntry, error) {
+	var entry LeaderboardEntry
+	err := rows.Scan(&entry.Rank, &entry.UserID, &entry.Pseudo, &entry.TotalScore,
+		&entry.GamesPlayed, &entry.AverageScore, &entry.Wins)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+type LeaderboardEntry struct {
+	Rank         int     `json:"rank"`
+	UserID       int64   `json:"user_id"`
+	Pseudo       string  `json:"pseudo"`
+	TotalScore   int     `json:"total_score"`
+	GamesPlayed  int     `json:"games_played"`
+	AverageScore float64 `json:"average_score"`
+	Wins         int     `json:"wins"`
 }
 
 func (s *PersistenceService) CleanOldRooms() error {
@@ -0,0 +1,25 @@
+package rooms
+
+import (
+	"groupie-tracker/internal/models"
+)
+
+// RoomStore persiste les salles et leurs joueurs pour les restaurer au démarrage.
+type RoomStore interface {
+	SaveRoom(room *models.Room) error
+	SaveRoomPlayers(room *models.Room) error
+	DeleteRoom(roomID string) error
+	LoadRooms() ([]*models.Room, error)
+}
+
+// ScoreStore enregistre les parties terminées et sert l'historique, les
+// profils et le classement. Chaque appel à SaveGameScores crée une partie
+// distincte, même pour deux parties d'une salle enregistrées au même instant.
+type ScoreStore interface {
+	SaveGameScores(room *models.Room, roundScores map[int64][]int) error
+	GetUserGameHistory(userID int64, limit int) ([]GameHistoryEntry, error)
+	GetUserGameStats(userID int64) ([]GameTypeStats, error)
+	GetGameDetails(gameID int64) (*GameDetails, error)
+	GetLeaderboard(filter LeaderboardFilter) ([]LeaderboardEntry, int, error)
+	GetLeaderboardRank(userID int64, filter LeaderboardFilter) (*LeaderboardEntry, error)
+}
@@ -0,0 +1,379 @@
+package rooms
+
+import (
+	"database/sql"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"groupie-tracker/internal/database"
+	"groupie-tracker/internal/models"
+)
+
+// roomStores réunit les deux interfaces, implémentées ensemble par
+// MemoryStore et PersistenceService.
+type roomStores interface {
+	RoomStore
+	ScoreStore
+}
+
+var testUsers = map[int64]string{1: "Alice", 2: "Bob", 3: "Carol"}
+
+// testDB est la base SQLite partagée par les tests de contrat.
+var testDB *sql.DB
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "rooms-test")
+	if err != nil {
+		log.Fatal(err)
+	}
+	testDB, err = database.Init(filepath.Join(dir, "test.db"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	code := m.Run()
+	testDB.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// forEachStore joue le même test sur chaque implémentation. La base SQLite
+// est vidée puis reçoit testUsers, que room_players et game_scores référencent.
+func forEachStore(t *testing.T, test func(t *testing.T, store roomStores)) {
+	stores := []struct {
+		name string
+		new  func(t *testing.T) roomStores
+	}{
+		{"mémoire", func(t *testing.T) roomStores { return NewMemoryStore() }},
+		{"sqlite", func(t *testing.T) roomStores {
+			if err := database.ResetDatabase(testDB); err != nil {
+				t.Fatalf("ResetDatabase: %v", err)
+			}
+			for id, pseudo := range testUsers {
+				_, err := testDB.Exec("INSERT INTO users (id, pseudo, email, password_hash) VALUES (?, ?, ?, ?)",
+					id, pseudo, pseudo+"@example.com", "hash")
+				if err != nil {
+					t.Fatalf("création de %s: %v", pseudo, err)
+				}
+			}
+			return NewPersistenceService(testDB)
+		}},
+	}
+
+	for _, s := range stores {
+		t.Run(s.name, func(t *testing.T) {
+			test(t, s.new(t))
+		})
+	}
+}
+
+// testRoom crée une salle dont l'hôte est le premier joueur, avec les
+// scores donnés dans l'ordre des userIDs.
+func testRoom(id string, gameType models.GameType, userIDs []int64, scores []int) *models.Room {
+	room := &models.Room{
+		ID:        id,
+		Code:      "C-" + id,
+		Name:      "Salle " + id,
+		HostID:    userIDs[0],
+		GameType:  gameType,
+		Status:    models.RoomStatusWaiting,
+		Players:   make(map[int64]*models.Player),
+		Config:    models.GameConfig{NbRounds: 3, TimePerRound: 30},
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	for i, userID := range userIDs {
+		room.Players[userID] = &models.Player{
+			UserID: userID,
+			Pseudo: testUsers[userID],
+			Score:  scores[i],
+			IsHost: i == 0,
+		}
+	}
+	return room
+}
+
+func saveTestRoom(t *testing.T, store roomStores, room *models.Room) {
+	t.Helper()
+	if err := store.SaveRoom(room); err != nil {
+		t.Fatalf("SaveRoom: %v", err)
+	}
+	if err := store.SaveRoomPlayers(room); err != nil {
+		t.Fatalf("SaveRoomPlayers: %v", err)
+	}
+}
+
+func loadRoom(t *testing.T, store roomStores, roomID string) *models.Room {
+	t.Helper()
+	loaded, err := store.LoadRooms()
+	if err != nil {
+		t.Fatalf("LoadRooms: %v", err)
+	}
+	for _, room := range loaded {
+		if room.ID == roomID {
+			return room
+		}
+	}
+	return nil
+}
+
+func TestRoomStoreContract(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store roomStores) {
+		room := testRoom("r1", models.GameTypeBlindTest, []int64{1, 2}, []int{10, 5})
+		room.Players[2].IsReady = true
+		saveTestRoom(t, store, room)
+
+		loaded := loadRoom(t, store, "r1")
+		if loaded == nil {
+			t.Fatal("salle non restaurée")
+		}
+		if loaded.Code != room.Code || loaded.Name != room.Name || loaded.HostID != 1 ||
+			loaded.GameType != room.GameType || loaded.Status != room.Status || loaded.Config.NbRounds != 3 {
+			t.Errorf("salle restaurée = %+v", loaded)
+		}
+		// Seul l'hôte revient prêt : les autres doivent se déclarer à nouveau.
+		wantPlayers := map[int64]models.Player{
+			1: {UserID: 1, Pseudo: "Alice", Score: 10, IsHost: true, IsReady: true},
+			2: {UserID: 2, Pseudo: "Bob", Score: 5},
+		}
+		gotPlayers := make(map[int64]models.Player)
+		for id, player := range loaded.Players {
+			gotPlayers[id] = *player
+		}
+		if !reflect.DeepEqual(gotPlayers, wantPlayers) {
+			t.Errorf("joueurs restaurés = %+v, attendu %+v", gotPlayers, wantPlayers)
+		}
+
+		// Mise à jour : départ de Bob et changement de statut.
+		delete(room.Players, 2)
+		room.Status = models.RoomStatusPlaying
+		saveTestRoom(t, store, room)
+		loaded = loadRoom(t, store, "r1")
+		if loaded == nil || loaded.Status != models.RoomStatusPlaying || len(loaded.Players) != 1 {
+			t.Errorf("salle mise à jour = %+v", loaded)
+		}
+
+		if err := store.DeleteRoom("r1"); err != nil {
+			t.Fatalf("DeleteRoom: %v", err)
+		}
+		if loadRoom(t, store, "r1") != nil {
+			t.Error("salle supprimée restaurée")
+		}
+
+		// Une salle avec des parties enregistrées n'est plus restaurée non plus.
+		played := testRoom("r2", models.GameTypeBlindTest, []int64{1, 2}, []int{10, 5})
+		saveTestRoom(t, store, played)
+		if err := store.SaveGameScores(played, nil); err != nil {
+			t.Fatalf("SaveGameScores: %v", err)
+		}
+		if err := store.DeleteRoom("r2"); err != nil {
+			t.Fatalf("DeleteRoom: %v", err)
+		}
+		if loadRoom(t, store, "r2") != nil {
+			t.Error("salle jouée puis supprimée restaurée")
+		}
+	})
+}
+
+func TestScoreStoreContract(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store roomStores) {
+		games := []struct {
+			room   *models.Room
+			rounds map[int64][]int
+		}{
+			{
+				testRoom("g1", models.GameTypeBlindTest, []int64{1, 2, 3}, []int{30, 50, 30}),
+				map[int64][]int{1: {10, 20}, 2: {25, 25}, 3: {30, 0}},
+			},
+			{
+				testRoom("g2", models.GameTypePetitBac, []int64{1, 2}, []int{40, 10}),
+				map[int64][]int{1: {20, 10, 10}, 2: {10, 0, 0}},
+			},
+		}
+		for _, game := range games {
+			saveTestRoom(t, store, game.room)
+			if err := store.SaveGameScores(game.room, game.rounds); err != nil {
+				t.Fatalf("SaveGameScores(%s): %v", game.room.ID, err)
+			}
+		}
+
+		history, err := store.GetUserGameHistory(1, 10)
+		if err != nil {
+			t.Fatalf("GetUserGameHistory: %v", err)
+		}
+		if len(history) != 2 {
+			t.Fatalf("historique = %+v, attendu 2 parties", history)
+		}
+		byRoom := make(map[string]GameHistoryEntry)
+		for _, entry := range history {
+			byRoom[entry.RoomID] = entry
+		}
+		tests := []struct {
+			roomID  string
+			score   int
+			rank    int
+			players int
+			won     bool
+			rounds  []int
+		}{
+			{"g1", 30, 2, 3, false, []int{10, 20}},
+			{"g2", 40, 1, 2, true, []int{20, 10, 10}},
+		}
+		for _, tt := range tests {
+			entry := byRoom[tt.roomID]
+			if entry.Score != tt.score || entry.Rank != tt.rank || entry.Players != tt.players ||
+				entry.Won != tt.won || !reflect.DeepEqual(entry.RoundScores, tt.rounds) || entry.PlayedAt == "" {
+				t.Errorf("partie %s = %+v", tt.roomID, entry)
+			}
+		}
+		if limited, err := store.GetUserGameHistory(1, 1); err != nil || len(limited) != 1 {
+			t.Errorf("historique limité = %+v, %v", limited, err)
+		}
+
+		stats, err := store.GetUserGameStats(1)
+		if err != nil {
+			t.Fatalf("GetUserGameStats: %v", err)
+		}
+		wantStats := map[models.GameType]GameTypeStats{
+			models.GameTypeBlindTest: {GameType: models.GameTypeBlindTest, GamesPlayed: 1, TotalScore: 30, BestScore: 30, AverageScore: 30},
+			models.GameTypePetitBac:  {GameType: models.GameTypePetitBac, GamesPlayed: 1, TotalScore: 40, BestScore: 40, AverageScore: 40, Wins: 1, WinRate: 100},
+		}
+		if len(stats) != len(wantStats) {
+			t.Errorf("statistiques = %+v", stats)
+		}
+		for _, stat := range stats {
+			if stat != wantStats[stat.GameType] {
+				t.Errorf("statistiques %s = %+v, attendu %+v", stat.GameType, stat, wantStats[stat.GameType])
+			}
+		}
+
+		details, err := store.GetGameDetails(byRoom["g1"].GameID)
+		if err != nil {
+			t.Fatalf("GetGameDetails: %v", err)
+		}
+		if details.ID != byRoom["g1"].GameID || details.RoomID != "g1" || details.RoomName != "Salle g1" || details.GameType != models.GameTypeBlindTest || details.Rounds != 2 {
+			t.Errorf("détails = %+v", details)
+		}
+		wantRanks := []GamePlayerScore{
+			{UserID: 2, Pseudo: "Bob", Score: 50, Rank: 1, RoundScores: []int{25, 25}},
+			{UserID: 1, Pseudo: "Alice", Score: 30, Rank: 2, RoundScores: []int{10, 20}},
+			{UserID: 3, Pseudo: "Carol", Score: 30, Rank: 2, RoundScores: []int{30, 0}},
+		}
+		if !reflect.DeepEqual(details.Players, wantRanks) {
+			t.Errorf("joueurs de la partie = %+v, attendu %+v", details.Players, wantRanks)
+		}
+		if _, err := store.GetGameDetails(byRoom["g2"].GameID + 1000); err != ErrGameNotFound {
+			t.Errorf("partie inconnue: %v, attendu %v", err, ErrGameNotFound)
+		}
+	})
+}
+
+func TestScoreStoreSeparatesGames(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store roomStores) {
+		// Deux parties de la même salle enregistrées dans la même seconde.
+		room := testRoom("g1", models.GameTypeBlindTest, []int64{1, 2}, []int{30, 10})
+		saveTestRoom(t, store, room)
+		if err := store.SaveGameScores(room, map[int64][]int{1: {30}, 2: {10}}); err != nil {
+			t.Fatalf("SaveGameScores: %v", err)
+		}
+		room.Players[1].Score, room.Players[2].Score = 0, 20
+		if err := store.SaveGameScores(room, map[int64][]int{1: {0}, 2: {20}}); err != nil {
+			t.Fatalf("SaveGameScores: %v", err)
+		}
+
+		history, err := store.GetUserGameHistory(1, 10)
+		if err != nil {
+			t.Fatalf("GetUserGameHistory: %v", err)
+		}
+		if len(history) != 2 {
+			t.Fatalf("historique = %+v, attendu 2 parties", history)
+		}
+		for _, entry := range history {
+			wantRank := 1
+			if entry.Score == 0 {
+				wantRank = 2
+			}
+			if entry.Players != 2 || entry.Rank != wantRank {
+				t.Errorf("partie à %d points: %d joueurs, rang %d, attendu 2 joueurs, rang %d",
+					entry.Score, entry.Players, entry.Rank, wantRank)
+			}
+
+			details, err := store.GetGameDetails(entry.GameID)
+			if err != nil {
+				t.Fatalf("GetGameDetails: %v", err)
+			}
+			if len(details.Players) != 2 {
+				t.Errorf("partie à %d points: %d joueurs dans le détail, attendu 2", entry.Score, len(details.Players))
+			}
+		}
+
+		stats, err := store.GetUserGameStats(1)
+		if err != nil || len(stats) != 1 || stats[0].GamesPlayed != 2 || stats[0].Wins != 1 {
+			t.Errorf("statistiques = %+v, %v, attendu 2 parties et 1 victoire", stats, err)
+		}
+
+		entries, _, err := store.GetLeaderboard(LeaderboardFilter{Metric: LeaderboardMetricWins, Limit: 10})
+		if err != nil {
+			t.Fatalf("GetLeaderboard: %v", err)
+		}
+		for _, entry := range entries {
+			if entry.GamesPlayed != 2 || entry.Wins != 1 {
+				t.Errorf("classement de %s: %d parties, %d victoire(s), attendu 2 et 1", entry.Pseudo, entry.GamesPlayed, entry.Wins)
+			}
+		}
+	})
+}
+
+func TestLeaderboardContract(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store roomStores) {
+		for _, room := range []*models.Room{
+			testRoom("g1", models.GameTypeBlindTest, []int64{1, 2, 3}, []int{30, 50, 45}),
+			testRoom("g2", models.GameTypePetitBac, []int64{1, 2}, []int{40, 10}),
+		} {
+			saveTestRoom(t, store, room)
+			if err := store.SaveGameScores(room, nil); err != nil {
+				t.Fatalf("SaveGameScores(%s): %v", room.ID, err)
+			}
+		}
+
+		tests := []struct {
+			name   string
+			filter LeaderboardFilter
+			want   []int64
+			total  int
+		}{
+			{"score total", LeaderboardFilter{Metric: LeaderboardMetricTotal, Limit: 10}, []int64{1, 2, 3}, 3},
+			{"victoires", LeaderboardFilter{Metric: LeaderboardMetricWins, Limit: 10}, []int64{1, 2, 3}, 3},
+			{"moyenne", LeaderboardFilter{Metric: LeaderboardMetricAverage, Limit: 10}, []int64{3, 1, 2}, 3},
+			{"parties jouées", LeaderboardFilter{Metric: LeaderboardMetricGames, Limit: 10}, []int64{1, 2, 3}, 3},
+			{"un seul jeu", LeaderboardFilter{GameType: models.GameTypePetitBac, Limit: 10}, []int64{1, 2}, 2},
+			{"page", LeaderboardFilter{Metric: LeaderboardMetricTotal, Limit: 1, Offset: 1}, []int64{2}, 3},
+			{"période", LeaderboardFilter{Period: LeaderboardPeriodWeek, Limit: 10}, []int64{1, 2, 3}, 3},
+		}
+		for _, tt := range tests {
+			entries, total, err := store.GetLeaderboard(tt.filter)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			got := []int64{}
+			for _, entry := range entries {
+				got = append(got, entry.UserID)
+			}
+			if !reflect.DeepEqual(got, tt.want) || total != tt.total {
+				t.Errorf("%s: %v (%d au total), attendu %v (%d)", tt.name, got, total, tt.want, tt.total)
+			}
+		}
+
+		entry, err := store.GetLeaderboardRank(2, LeaderboardFilter{Metric: LeaderboardMetricTotal})
+		want := &LeaderboardEntry{Rank: 2, UserID: 2, Pseudo: "Bob", TotalScore: 60, GamesPlayed: 2, AverageScore: 30, Wins: 1}
+		if err != nil || !reflect.DeepEqual(entry, want) {
+			t.Errorf("GetLeaderboardRank = %+v, %v, attendu %+v", entry, err, want)
+		}
+		if entry, err := store.GetLeaderboardRank(3, LeaderboardFilter{GameType: models.GameTypePetitBac}); err != nil || entry != nil {
+			t.Errorf("joueur sans partie classé: %+v, %v", entry, err)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package spotify
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCacheTTL est la durée pendant laquelle une réponse Deezer est
+	// réutilisée sans nouvel appel.
+	DefaultCacheTTL = 10 * time.Minute
+
+	// maxStaleAge borne l'âge d'une réponse servie quand Deezer ne répond
+	// plus : mieux vaut un classement de la veille qu'une partie impossible.
+	maxStaleAge = 24 * time.Hour
+
+	maxMemoryEntries = 500
+)
+
+type CacheEntry struct {
+	Body     []byte
+	StoredAt time.Time
+}
+
+// CacheStore persiste les réponses Deezer pour les retrouver après un
+// redémarrage. GetCached renvoie nil, nil si la clé est absente.
+type CacheStore interface {
+	GetCached(key string) (*CacheEntry, error)
+	SaveCached(key string, entry *CacheEntry) error
+	DeleteCachedBefore(before time.Time) error
+}
+
+// responseCache garde les réponses en mémoire devant un CacheStore
+// optionnel. Les clés sont les URL appelées.
+type responseCache struct {
+	ttl     time.Duration
+	entries map[string]*CacheEntry
+	store   CacheStore
+	mutex   sync.RWMutex
+}
+
+func newResponseCache(ttl time.Duration, store CacheStore) *responseCache {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+
+	if store != nil {
+		if err := store.DeleteCachedBefore(time.Now().Add(-maxStaleAge)); err != nil {
+			log.Printf("[Deezer] Erreur purge du cache: %v", err)
+		}
+	}
+
+	return &responseCache{
+		ttl:     ttl,
+		entries: make(map[string]*CacheEntry),
+		store:   store,
+	}
+}
+
+func (c *responseCache) get(key string) (*CacheEntry, bool) {
+	c.mutex.RLock()
+	entry, exists := c.entries[key]
+	c.mutex.RUnlock()
+	if exists {
+		return entry, true
+	}
+
+	if c.store == nil {
+		return nil, false
+	}
+
+	entry, err := c.store.GetCached(key)
+	if err != nil {
+		log.Printf("[Deezer] Erreur lecture cache: %v", err)
+		return nil, false
+	}
+	if entry == nil {
+		return nil, false
+	}
+
+	c.mutex.Lock()
+	c.entries[key] = entry
+	c.mutex.Unlock()
+	return entry, true
+}
+
+func (c *responseCache) set(key string, body []byte) {
+	entry := &CacheEntry{Body: body, StoredAt: time.Now()}
+
+	c.mutex.Lock()
+	if len(c.entries) >= maxMemoryEntries {
+		c.evictLocked()
+	}
+	c.entries[key] = entry
+	c.mutex.Unlock()
+
+	if c.store != nil {
+		if err := c.store.SaveCached(key, entry); err != nil {
+			log.Printf("[Deezer] Erreur écriture cache: %v", err)
+		}
+	}
+}
+
+// evictLocked retire les entrées expirées, ou tout le cache mémoire si
+// aucune ne l'est encore ; le CacheStore garde les copies persistées.
+func (c *responseCache) evictLocked() {
+	for key, entry := range c.entries {
+		if !c.fresh(entry) {
+			delete(c.entries, key)
+		}
+	}
+	if len(c.entries) >= maxMemoryEntries {
+		c.entries = make(map[string]*CacheEntry)
+	}
+}
+
+func (c *responseCache) fresh(entry *CacheEntry) bool {
+	return time.Since(entry.StoredAt) < c.ttl
+}
+
+func (c *responseCache) usableStale(entry *CacheEntry) bool {
+	return time.Since(entry.StoredAt) < maxStaleAge
+}
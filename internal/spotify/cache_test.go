@@ -0,0 +1,212 @@
+package spotify
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryCacheStore struct {
+	entries map[string]*CacheEntry
+	mutex   sync.Mutex
+}
+
+func newMemoryCacheStore() *memoryCacheStore {
+	return &memoryCacheStore{entries: make(map[string]*CacheEntry)}
+}
+
+func (s *memoryCacheStore) GetCached(key string) (*CacheEntry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.entries[key], nil
+}
+
+func (s *memoryCacheStore) SaveCached(key string, entry *CacheEntry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.entries[key] = entry
+	return nil
+}
+
+func (s *memoryCacheStore) DeleteCachedBefore(before time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for key, entry := range s.entries {
+		if entry.StoredAt.Before(before) {
+			delete(s.entries, key)
+		}
+	}
+	return nil
+}
+
+// fakeDeezer répond body avec status et compte les appels.
+type fakeDeezer struct {
+	server *httptest.Server
+	status int
+	body   string
+	calls  int
+	mutex  sync.Mutex
+}
+
+func newFakeDeezer(t *testing.T) *fakeDeezer {
+	t.Helper()
+	fake := &fakeDeezer{status: http.StatusOK, body: `{"data": [{"id": 1}]}`}
+	fake.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fake.mutex.Lock()
+		defer fake.mutex.Unlock()
+		fake.calls++
+		w.WriteHeader(fake.status)
+		w.Write([]byte(fake.body))
+	}))
+	t.Cleanup(fake.server.Close)
+	return fake
+}
+
+func (f *fakeDeezer) respond(status int, body string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.status, f.body = status, body
+}
+
+func (f *fakeDeezer) callCount() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.calls
+}
+
+func testClient(store CacheStore) *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: time.Second},
+		cache:      newResponseCache(time.Minute, store),
+		breaker:    newCircuitBreaker(breakerThreshold, breakerCooldown),
+		backoff:    time.Millisecond,
+	}
+}
+
+type idList struct {
+	Data []struct {
+		ID int `json:"id"`
+	} `json:"data"`
+}
+
+func TestGetJSONUsesFreshCache(t *testing.T) {
+	fake := newFakeDeezer(t)
+	client := testClient(nil)
+
+	for i := 0; i < 3; i++ {
+		var out idList
+		if _, err := client.getJSON(fake.server.URL+"/chart", &out); err != nil || len(out.Data) != 1 {
+			t.Fatalf("appel %d: %v, %v", i, out, err)
+		}
+	}
+	if calls := fake.callCount(); calls != 1 {
+		t.Errorf("%d appels à Deezer, attendu 1", calls)
+	}
+
+	client.cache.entries[fake.server.URL+"/chart"].StoredAt = time.Now().Add(-2 * time.Minute)
+	var out idList
+	if _, err := client.getJSON(fake.server.URL+"/chart", &out); err != nil {
+		t.Fatalf("après expiration: %v", err)
+	}
+	if calls := fake.callCount(); calls != 2 {
+		t.Errorf("réponse expirée non rafraîchie: %d appels", calls)
+	}
+}
+
+func TestGetJSONUpstreamErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		cachedAge time.Duration // 0 : aucune réponse en cache
+		wantErr   error
+		wantStale bool
+	}{
+		{"HTTP 500 sans cache", http.StatusInternalServerError, "", 0, ErrUnavailable, false},
+		{"HTTP 500 avec cache", http.StatusInternalServerError, "", time.Hour, nil, true},
+		{"réponse illisible", http.StatusOK, "<html>", 0, ErrUnavailable, false},
+		{"quota dépassé avec cache", http.StatusOK, `{"error": {"type": "Exception", "message": "Quota limit exceeded", "code": 4}}`, time.Hour, nil, true},
+		{"service occupé sans cache", http.StatusOK, `{"error": {"type": "Exception", "message": "busy", "code": 700}}`, 0, &APIError{}, false},
+		{"ressource absente malgré le cache", http.StatusOK, `{"error": {"type": "DataException", "message": "no data", "code": 800}}`, time.Hour, &APIError{}, false},
+		{"cache trop ancien", http.StatusBadGateway, "", maxStaleAge + time.Hour, ErrUnavailable, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeDeezer(t)
+			fake.respond(tt.status, tt.body)
+			apiURL := fake.server.URL + "/chart"
+
+			client := testClient(nil)
+			storedAt := time.Now().Add(-tt.cachedAge)
+			if tt.cachedAge > 0 {
+				client.cache.entries[apiURL] = &CacheEntry{Body: []byte(`{"data": [{"id": 7}]}`), StoredAt: storedAt}
+			}
+
+			var out idList
+			receivedAt, err := client.getJSON(apiURL, &out)
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("getJSON: %v", err)
+				}
+			case *APIError:
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("getJSON = %v, attendu une erreur Deezer", err)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Fatalf("getJSON = %v, attendu %v", err, want)
+				}
+			}
+			if stale := len(out.Data) == 1 && out.Data[0].ID == 7; stale != tt.wantStale {
+				t.Errorf("réponse en cache servie: %v, attendu %v", stale, tt.wantStale)
+			}
+			if tt.wantStale && !receivedAt.Equal(storedAt) {
+				t.Errorf("date de réception %v, attendu celle du cache %v", receivedAt, storedAt)
+			}
+		})
+	}
+}
+
+func TestGetJSONUnreachable(t *testing.T) {
+	fake := newFakeDeezer(t)
+	apiURL := fake.server.URL + "/chart"
+	fake.server.Close()
+
+	var out idList
+	if _, err := testClient(nil).getJSON(apiURL, &out); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("serveur injoignable: %v, attendu %v", err, ErrUnavailable)
+	}
+}
+
+func TestResponseCacheStore(t *testing.T) {
+	store := newMemoryCacheStore()
+	store.entries["ancienne"] = &CacheEntry{Body: []byte("{}"), StoredAt: time.Now().Add(-maxStaleAge - time.Hour)}
+
+	fake := newFakeDeezer(t)
+	apiURL := fake.server.URL + "/chart"
+	var out idList
+	if _, err := testClient(store).getJSON(apiURL, &out); err != nil {
+		t.Fatalf("getJSON: %v", err)
+	}
+	if _, kept := store.entries["ancienne"]; kept {
+		t.Error("entrée de plus de 24h gardée au démarrage")
+	}
+	if store.entries[apiURL] == nil {
+		t.Fatal("réponse non persistée")
+	}
+
+	// Un nouveau client, comme après un redémarrage, relit le stockage.
+	restarted := testClient(store)
+	if _, err := restarted.getJSON(apiURL, &out); err != nil {
+		t.Fatalf("après redémarrage: %v", err)
+	}
+	if calls := fake.callCount(); calls != 1 {
+		t.Errorf("%d appels à Deezer, attendu 1 grâce au stockage", calls)
+	}
+}
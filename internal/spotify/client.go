@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"math/rand/v2"
 	"net/http"
@@ -18,36 +19,91 @@ import (
 var (
 	ErrNoToken  = errors.New("pas de token valide")
 	ErrNoTracks = errors.New("aucune piste trouvée")
+
+	ErrUnknownGenre = errors.New("genre inconnu")
+	ErrUnavailable  = errors.New("API Deezer indisponible")
+	ErrCircuitOpen  = fmt.Errorf("%w: trop d'échecs récents, nouvel essai plus tard", ErrUnavailable)
+)
+
+// Codes d'erreur renvoyés par Deezer dans {"error": {...}} avec un statut 200.
+const (
+	deezerErrQuota       = 4
+	deezerErrServiceBusy = 700
+	deezerErrNotFound    = 800
 )
 
+const maxResponseSize = 4 << 20
+
+// Un appel en échec est retenté maxAttempts fois avec un délai doublé à
+// chaque essai. breakerThreshold échecs consécutifs ouvrent le circuit.
+const (
+	maxAttempts      = 3
+	baseBackoff      = 250 * time.Millisecond
+	breakerThreshold = 3
+	breakerCooldown  = 30 * time.Second
+)
+
+// HTTPError est une réponse Deezer avec un statut autre que 200.
+type HTTPError struct {
+	StatusCode int
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%v: HTTP %d", ErrUnavailable, e.StatusCode)
+}
+
+func (e *HTTPError) Unwrap() error {
+	return ErrUnavailable
+}
+
+// APIError est l'objet d'erreur JSON de Deezer.
+type APIError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("erreur Deezer %d (%s): %s", e.Code, e.Type, e.Message)
+}
+
+// Temporary indique une erreur passagère (quota, surcharge) pour laquelle une
+// réponse en cache, même périmée, reste préférable.
+func (e *APIError) Temporary() bool {
+	return e.Code == deezerErrQuota || e.Code == deezerErrServiceBusy
+}
+
+// Config règle le client. CacheStore et PoolStore sont optionnels : sans
+// eux, le cache et la réserve de pistes ne vivent qu'en mémoire.
 type Config struct {
 	ClientID     string
 	ClientSecret string
+	CacheTTL     time.Duration
+	CacheStore   CacheStore
+	PoolStore    PoolStore
 }
 
 type Client struct {
 	httpClient *http.Client
+	cache      *responseCache
+	breaker    *circuitBreaker
+	backoff    time.Duration
+	pool       *trackPool
 	mutex      *sync.RWMutex
 }
 
-var (
-	clientInstance *Client
-	clientOnce     sync.Once
-)
-
+// NewClient crée un client Deezer. main en construit un seul et le passe aux
+// sources de musique qui l'utilisent.
 func NewClient(config Config) *Client {
-	clientOnce.Do(func() {
-		clientInstance = &Client{
-			httpClient: &http.Client{
-				Timeout: 15 * time.Second,
-			},
-		}
-	})
-	return clientInstance
-}
-
-func GetClient() *Client {
-	return clientInstance
+	return &Client{
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+		cache:   newResponseCache(config.CacheTTL, config.CacheStore),
+		breaker: newCircuitBreaker(breakerThreshold, breakerCooldown),
+		backoff: baseBackoff,
+		pool:    newTrackPool(config.PoolStore),
+	}
 }
 
 func (c *Client) Authenticate() error {
@@ -68,155 +124,194 @@ type DeezerTrack struct {
 	} `json:"album"`
 }
 
-func (c *Client) GetChartTracks(limit int) ([]*models.SpotifyTrack, error) {
-	apiURL := fmt.Sprintf("https://api.deezer.com/chart/0/tracks?limit=%d", limit)
+// getJSON décode la réponse de apiURL dans out et renvoie sa date de
+// réception. Une réponse en cache encore fraîche évite l'appel ; si Deezer
+// échoue, une réponse périmée de moins de 24h est servie à la place.
+func (c *Client) getJSON(apiURL string, out interface{}) (time.Time, error) {
+	cached, hasCached := c.cache.get(apiURL)
+	if hasCached && c.cache.fresh(cached) {
+		return cached.StoredAt, json.Unmarshal(cached.Body, out)
+	}
 
-	resp, err := c.httpClient.Get(apiURL)
+	body, err := c.fetchWithRetry(apiURL)
 	if err != nil {
-		return nil, err
+		var apiErr *APIError
+		transient := !errors.As(err, &apiErr) || apiErr.Temporary()
+		if transient && hasCached && c.cache.usableStale(cached) {
+			log.Printf("[Deezer] %v, réponse en cache du %s utilisée", err, cached.StoredAt.Format("02/01 15:04"))
+			return cached.StoredAt, json.Unmarshal(cached.Body, out)
+		}
+		return time.Time{}, err
 	}
-	defer resp.Body.Close()
 
-	var result struct {
-		Data []DeezerTrack `json:"data"`
-	}
+	c.cache.set(apiURL, body)
+	return time.Now(), json.Unmarshal(body, out)
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
-	}
+// fetchWithRetry retente les erreurs passagères (réseau, 5xx, 429, quota)
+// avec un backoff exponentiel, tant que le circuit reste fermé. Une erreur
+// définitive de Deezer (playlist inexistante...) est renvoyée aussitôt.
+func (c *Client) fetchWithRetry(apiURL string) ([]byte, error) {
+	var err error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			backoff := c.backoff << (attempt - 1)
+			backoff += rand.N(backoff / 2)
+			time.Sleep(backoff)
+		}
 
-	var tracks []*models.SpotifyTrack
-	for _, item := range result.Data {
-		if item.Preview == "" {
-			continue
+		if !c.breaker.allow() {
+			return nil, ErrCircuitOpen
 		}
 
-		tracks = append(tracks, &models.SpotifyTrack{
-			ID:         fmt.Sprintf("%d", item.ID),
-			Name:       item.Title,
-			Artist:     item.Artist.Name,
-			Album:      item.Album.Title,
-			PreviewURL: item.Preview,
-			ImageURL:   item.Album.Cover,
-		})
-	}
+		var body []byte
+		body, err = c.fetch(apiURL)
+		if err == nil {
+			c.breaker.success()
+			return body, nil
+		}
+		if !retryable(err) {
+			// Deezer a répondu : l'API fonctionne, la requête est en cause.
+			c.breaker.success()
+			return nil, err
+		}
 
-	log.Printf("[Deezer] Chart: %d pistes avec preview", len(tracks))
-	return tracks, nil
+		c.breaker.failure()
+		log.Printf("[Deezer] Essai %d/%d échoué: %v", attempt+1, maxAttempts, err)
+	}
+	return nil, err
 }
 
-func (c *Client) SearchTracks(query string, limit int) ([]*models.SpotifyTrack, error) {
-	apiURL := fmt.Sprintf("https://api.deezer.com/search?q=%s&limit=%d", url.QueryEscape(query), limit)
+func retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= 500
+	}
+	return true
+}
 
+func (c *Client) fetch(apiURL string) ([]byte, error) {
 	resp, err := c.httpClient.Get(apiURL)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
 	}
 	defer resp.Body.Close()
 
-	var result struct {
-		Data []DeezerTrack `json:"data"`
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+		return nil, &HTTPError{StatusCode: resp.StatusCode}
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
 	}
 
-	var tracks []*models.SpotifyTrack
-	for _, item := range result.Data {
-		if item.Preview == "" {
-			continue
-		}
-
-		tracks = append(tracks, &models.SpotifyTrack{
-			ID:         fmt.Sprintf("%d", item.ID),
-			Name:       item.Title,
-			Artist:     item.Artist.Name,
-			Album:      item.Album.Title,
-			PreviewURL: item.Preview,
-			ImageURL:   item.Album.Cover,
-		})
+	var envelope struct {
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: réponse illisible: %v", ErrUnavailable, err)
+	}
+	if envelope.Error != nil {
+		return nil, envelope.Error
 	}
 
-	log.Printf("[Deezer] Recherche '%s': %d pistes avec preview", query, len(tracks))
-	return tracks, nil
+	return body, nil
 }
 
-func (c *Client) GetPlaylistTracks(playlistID string, limit int) ([]*models.SpotifyTrack, error) {
-	apiURL := fmt.Sprintf("https://api.deezer.com/playlist/%s/tracks?limit=%d", playlistID, limit)
+func (c *Client) GetChartTracks(limit int) ([]*models.SpotifyTrack, error) {
+	apiURL := fmt.Sprintf("https://api.deezer.com/chart/0/tracks?limit=%d", limit)
 
-	resp, err := c.httpClient.Get(apiURL)
+	tracks, err := c.fetchTracks(apiURL)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	var result struct {
-		Data []DeezerTrack `json:"data"`
-	}
+	log.Printf("[Deezer] Chart: %d pistes avec preview", len(tracks))
+	return tracks, nil
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+func (c *Client) SearchTracks(query string, limit int) ([]*models.SpotifyTrack, error) {
+	apiURL := fmt.Sprintf("https://api.deezer.com/search?q=%s&limit=%d", url.QueryEscape(query), limit)
+
+	tracks, err := c.fetchTracks(apiURL)
+	if err != nil {
 		return nil, err
 	}
 
-	var tracks []*models.SpotifyTrack
-	for _, item := range result.Data {
-		if item.Preview == "" {
-			continue
-		}
+	log.Printf("[Deezer] Recherche '%s': %d pistes avec preview", query, len(tracks))
+	return tracks, nil
+}
 
-		tracks = append(tracks, &models.SpotifyTrack{
-			ID:         fmt.Sprintf("%d", item.ID),
-			Name:       item.Title,
-			Artist:     item.Artist.Name,
-			Album:      item.Album.Title,
-			PreviewURL: item.Preview,
-			ImageURL:   item.Album.Cover,
-		})
+func (c *Client) GetPlaylistTracks(playlistID string, limit int) ([]*models.SpotifyTrack, error) {
+	apiURL := fmt.Sprintf("https://api.deezer.com/playlist/%s/tracks?limit=%d", playlistID, limit)
+
+	tracks, err := c.fetchTracks(apiURL)
+	if err != nil {
+		return nil, err
 	}
 
 	log.Printf("[Deezer] Playlist %s: %d pistes avec preview", playlistID, len(tracks))
 	return tracks, nil
 }
 
+// GetRandomTracksForBlindTest pioche jusqu'à count pistes du genre demandé
+// (genre vide : classement global). Les sources du genre sont interrogées
+// dans l'ordre jusqu'à réunir assez d'extraits, puis complétées par la
+// réserve des pistes déjà vues si Deezer ne répond pas ; l'appelant décide
+// quoi faire s'il en manque encore.
 func (c *Client) GetRandomTracksForBlindTest(genre string, count int) ([]*models.SpotifyTrack, error) {
+	g, ok := FindGenre(genre)
+	if !ok {
+		return nil, ErrUnknownGenre
+	}
+
+	seen := make(map[string]bool)
 	var allTracks []*models.SpotifyTrack
+	collect := func(tracks []*models.SpotifyTrack) {
+		for _, track := range tracks {
+			key := strings.ToLower(track.Name + track.Artist)
+			if !seen[key] {
+				seen[key] = true
+				allTracks = append(allTracks, track)
+			}
+		}
+	}
 
-	chartTracks, err := c.GetChartTracks(50)
-	if err != nil {
-		log.Printf("[Deezer] Erreur chart: %v", err)
-	} else {
-		allTracks = append(allTracks, chartTracks...)
+	var lastErr error
+	for _, source := range c.genreSources(g) {
+		if len(allTracks) >= count*2 {
+			break
+		}
+		tracks, err := source.fetch()
+		if err != nil {
+			log.Printf("[Deezer] Erreur %s (%s): %v", source.name, g.Name, err)
+			lastErr = err
+			continue
+		}
+		c.pool.remember(g.Slug, tracks)
+		collect(tracks)
 	}
 
 	if len(allTracks) < count {
-		searchQueries := []string{"hit 2024", "pop", "top"}
-		for _, query := range searchQueries {
-			searchTracks, err := c.SearchTracks(query, 30)
-			if err != nil {
-				continue
-			}
-			allTracks = append(allTracks, searchTracks...)
-			if len(allTracks) >= count*2 {
-				break
-			}
+		before := len(allTracks)
+		collect(c.pool.recall(g.Slug))
+		if len(allTracks) > before {
+			log.Printf("[Deezer] %d pistes %s reprises de la réserve", len(allTracks)-before, g.Name)
 		}
 	}
 
 	if len(allTracks) == 0 {
-		return nil, ErrNoTracks
-	}
-
-	seen := make(map[string]bool)
-	uniqueTracks := make([]*models.SpotifyTrack, 0)
-	for _, track := range allTracks {
-		key := strings.ToLower(track.Name + track.Artist)
-		if !seen[key] {
-			seen[key] = true
-			uniqueTracks = append(uniqueTracks, track)
+		if lastErr != nil {
+			return nil, lastErr
 		}
+		return nil, ErrNoTracks
 	}
-	allTracks = uniqueTracks
 
 	rand.Shuffle(len(allTracks), func(i, j int) {
 		allTracks[i], allTracks[j] = allTracks[j], allTracks[i]
@@ -226,10 +321,6 @@ func (c *Client) GetRandomTracksForBlindTest(genre string, count int) ([]*models
 		count = len(allTracks)
 	}
 
-	log.Printf("[Deezer] Retourne %d pistes pour le blind test", count)
+	log.Printf("[Deezer] Retourne %d pistes %s pour le blind test", count, g.Name)
 	return allTracks[:count], nil
 }
-
-func GetAvailableGenres() []string {
-	return []string{"Top Global"}
-}
\ No newline at end of file
@@ -0,0 +1,109 @@
+package spotify
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyDeezer échoue avec status pour les failures premiers appels, puis
+// répond normalement.
+func flakyDeezer(t *testing.T, failures int32, status int, body string) (string, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+			return
+		}
+		w.Write([]byte(`{"data": []}`))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL, &calls
+}
+
+func TestFetchWithRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int32
+		status    int
+		body      string
+		wantErr   bool
+		wantCalls int32
+	}{
+		{"succès immédiat", 0, http.StatusOK, "", false, 1},
+		{"503 passagère", 1, http.StatusServiceUnavailable, "", false, 2},
+		{"429 puis quota", 2, http.StatusTooManyRequests, "", false, 3},
+		{"quota Deezer", 1, http.StatusOK, `{"error": {"code": 4, "message": "Quota limit exceeded"}}`, false, 2},
+		{"playlist inexistante", 1, http.StatusOK, `{"error": {"code": 800, "message": "no data"}}`, true, 1},
+		{"404 définitive", 1, http.StatusNotFound, "", true, 1},
+		{"panne durable", maxAttempts, http.StatusBadGateway, "", true, maxAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiURL, calls := flakyDeezer(t, tt.failures, tt.status, tt.body)
+			client := testClient(nil)
+
+			_, err := client.fetchWithRetry(apiURL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fetchWithRetry = %v, erreur attendue: %v", err, tt.wantErr)
+			}
+			if n := calls.Load(); n != tt.wantCalls {
+				t.Errorf("%d appels, attendu %d", n, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestFetchWithRetryOpensCircuit(t *testing.T) {
+	apiURL, calls := flakyDeezer(t, 100, http.StatusInternalServerError, "")
+	client := testClient(nil)
+
+	if _, err := client.fetchWithRetry(apiURL); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("premier appel: %v", err)
+	}
+	before := calls.Load()
+	if _, err := client.fetchWithRetry(apiURL); !errors.Is(err, ErrCircuitOpen) || !errors.Is(err, ErrUnavailable) {
+		t.Errorf("circuit ouvert: %v, attendu %v", err, ErrCircuitOpen)
+	}
+	if calls.Load() != before {
+		t.Error("Deezer appelé malgré le circuit ouvert")
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	breaker := newCircuitBreaker(2, 20*time.Millisecond)
+
+	breaker.failure()
+	if !breaker.allow() {
+		t.Fatal("circuit ouvert avant le seuil")
+	}
+	breaker.failure()
+	if breaker.allow() {
+		t.Fatal("circuit fermé après le seuil")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if !breaker.allow() {
+		t.Fatal("pas d'essai après le délai")
+	}
+	if breaker.allow() {
+		t.Error("second essai autorisé pendant le demi-ouvert")
+	}
+	breaker.failure()
+	if breaker.allow() {
+		t.Fatal("essai raté sans réouverture")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	breaker.allow()
+	breaker.success()
+	if !breaker.allow() || !breaker.allow() {
+		t.Error("circuit non refermé après un essai réussi")
+	}
+}
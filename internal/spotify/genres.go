@@ -0,0 +1,174 @@
+package spotify
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"groupie-tracker/internal/models"
+)
+
+// Genre associe un genre proposé à la création de salle à son identifiant
+// Deezer. Le même identifiant sert pour /chart/{id}, /editorial/{id} et
+// /genre/{id}/radios ; 0 correspond au classement global.
+type Genre struct {
+	Slug     string `json:"slug"`
+	Name     string `json:"name"`
+	DeezerID int    `json:"deezer_id"`
+}
+
+var Genres = []Genre{
+	{Slug: "top", Name: "Top Global", DeezerID: 0},
+	{Slug: "pop", Name: "Pop", DeezerID: 132},
+	{Slug: "rap", Name: "Rap/Hip Hop", DeezerID: 116},
+	{Slug: "rock", Name: "Rock", DeezerID: 152},
+	{Slug: "dance", Name: "Dance", DeezerID: 113},
+	{Slug: "electro", Name: "Electro", DeezerID: 106},
+	{Slug: "rnb", Name: "R&B", DeezerID: 165},
+	{Slug: "alternative", Name: "Alternative", DeezerID: 85},
+	{Slug: "chanson", Name: "Chanson française", DeezerID: 52},
+	{Slug: "metal", Name: "Metal", DeezerID: 464},
+	{Slug: "soul", Name: "Soul & Funk", DeezerID: 169},
+	{Slug: "reggae", Name: "Reggae", DeezerID: 144},
+	{Slug: "jazz", Name: "Jazz", DeezerID: 129},
+	{Slug: "latino", Name: "Latino", DeezerID: 197},
+	{Slug: "films", Name: "Films/Jeux vidéo", DeezerID: 173},
+}
+
+// maxGenreRadios limite le nombre de radios interrogées pour un genre.
+const maxGenreRadios = 3
+
+func GetAvailableGenres() []Genre {
+	return Genres
+}
+
+// FindGenre accepte le slug ou le nom affiché, sans tenir compte de la casse.
+// Un genre vide correspond au classement global.
+func FindGenre(name string) (Genre, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Genres[0], true
+	}
+	for _, genre := range Genres {
+		if strings.EqualFold(genre.Slug, name) || strings.EqualFold(genre.Name, name) {
+			return genre, true
+		}
+	}
+	return Genre{}, false
+}
+
+type trackSource struct {
+	name  string
+	fetch func() ([]*models.SpotifyTrack, error)
+}
+
+// genreSources liste, par ordre de préférence, les endpoints Deezer d'un
+// genre : classement, sélection éditoriale puis radios thématiques.
+func (c *Client) genreSources(g Genre) []trackSource {
+	return []trackSource{
+		{name: "chart", fetch: func() ([]*models.SpotifyTrack, error) {
+			return c.fetchTracks(fmt.Sprintf("https://api.deezer.com/chart/%d/tracks?limit=100", g.DeezerID))
+		}},
+		{name: "editorial", fetch: func() ([]*models.SpotifyTrack, error) {
+			return c.GetEditorialTracks(g.DeezerID)
+		}},
+		{name: "radios", fetch: func() ([]*models.SpotifyTrack, error) {
+			return c.GetGenreRadioTracks(g.DeezerID, maxGenreRadios)
+		}},
+	}
+}
+
+func (c *Client) GetEditorialTracks(editorialID int) ([]*models.SpotifyTrack, error) {
+	apiURL := fmt.Sprintf("https://api.deezer.com/editorial/%d/charts?limit=100", editorialID)
+
+	var result struct {
+		Tracks struct {
+			Data []DeezerTrack `json:"data"`
+		} `json:"tracks"`
+	}
+
+	fetchedAt, err := c.getJSON(apiURL, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	tracks := withPreview(result.Tracks.Data, fetchedAt)
+	log.Printf("[Deezer] Éditorial %d: %d pistes avec preview", editorialID, len(tracks))
+	return tracks, nil
+}
+
+func (c *Client) GetGenreRadioTracks(genreID int, maxRadios int) ([]*models.SpotifyTrack, error) {
+	apiURL := fmt.Sprintf("https://api.deezer.com/genre/%d/radios", genreID)
+
+	var result struct {
+		Data []struct {
+			ID int `json:"id"`
+		} `json:"data"`
+	}
+
+	if _, err := c.getJSON(apiURL, &result); err != nil {
+		return nil, err
+	}
+
+	var tracks []*models.SpotifyTrack
+	for i, radio := range result.Data {
+		if i >= maxRadios {
+			break
+		}
+		radioTracks, err := c.fetchTracks(fmt.Sprintf("https://api.deezer.com/radio/%d/tracks?limit=50", radio.ID))
+		if err != nil {
+			log.Printf("[Deezer] Erreur radio %d: %v", radio.ID, err)
+			continue
+		}
+		tracks = append(tracks, radioTracks...)
+	}
+
+	log.Printf("[Deezer] Radios du genre %d: %d pistes avec preview", genreID, len(tracks))
+	return tracks, nil
+}
+
+// fetchTracks lit une réponse Deezer de la forme {"data": [pistes]}.
+func (c *Client) fetchTracks(apiURL string) ([]*models.SpotifyTrack, error) {
+	var result struct {
+		Data []DeezerTrack `json:"data"`
+	}
+
+	fetchedAt, err := c.getJSON(apiURL, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return withPreview(result.Data, fetchedAt), nil
+}
+
+// withPreview garde les pistes dont l'extrait est encore lisible. Une
+// réponse servie depuis le cache peut porter des URL déjà expirées.
+func withPreview(items []DeezerTrack, fetchedAt time.Time) []*models.SpotifyTrack {
+	var tracks []*models.SpotifyTrack
+	expired := 0
+	for _, item := range items {
+		if item.Preview == "" {
+			continue
+		}
+
+		track := &models.SpotifyTrack{
+			ID:         fmt.Sprintf("%d", item.ID),
+			Name:       item.Title,
+			Artist:     item.Artist.Name,
+			Album:      item.Album.Title,
+			PreviewURL: item.Preview,
+			ImageURL:   item.Album.Cover,
+			FetchedAt:  fetchedAt,
+		}
+		if !previewUsable(track) {
+			expired++
+			continue
+		}
+		tracks = append(tracks, track)
+	}
+	if expired > 0 {
+		log.Printf("[Deezer] %d extraits expirés ignorés", expired)
+	}
+	return tracks
+}
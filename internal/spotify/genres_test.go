@@ -0,0 +1,27 @@
+package spotify
+
+import "testing"
+
+func TestFindGenre(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantSlug string
+		wantOK   bool
+	}{
+		{"", "top", true},
+		{"  ", "top", true},
+		{"rock", "rock", true},
+		{"ROCK", "rock", true},
+		{"Rap/Hip Hop", "rap", true},
+		{"chanson française", "chanson", true},
+		{" metal ", "metal", true},
+		{"polka", "", false},
+	}
+
+	for _, tt := range tests {
+		genre, ok := FindGenre(tt.name)
+		if ok != tt.wantOK || genre.Slug != tt.wantSlug {
+			t.Errorf("FindGenre(%q) = %q, %v, attendu %q, %v", tt.name, genre.Slug, ok, tt.wantSlug, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package spotify
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"groupie-tracker/internal/models"
+)
+
+var (
+	ErrInvalidPlaylist  = errors.New("lien de playlist Deezer invalide")
+	ErrPlaylistNotFound = errors.New("playlist Deezer introuvable ou privée")
+)
+
+var (
+	playlistIDPattern  = regexp.MustCompile(`^\d+$`)
+	playlistURLPattern = regexp.MustCompile(`deezer\.com/(?:[a-z]{2}(?:-[a-z]{2})?/)?playlist/(\d+)`)
+)
+
+// Playlist décrit une playlist Deezer et ses pistes ayant un extrait.
+// TrackCount compte toutes les pistes, len(Tracks) seulement les jouables.
+type Playlist struct {
+	ID         string
+	Title      string
+	TrackCount int
+	Tracks     []*models.SpotifyTrack
+}
+
+// ParsePlaylistID accepte un identifiant ("1109890291") ou un lien
+// ("https://www.deezer.com/fr/playlist/1109890291?utm_source=...").
+func ParsePlaylistID(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if playlistIDPattern.MatchString(input) {
+		return input, nil
+	}
+	if match := playlistURLPattern.FindStringSubmatch(input); match != nil {
+		return match[1], nil
+	}
+	return "", ErrInvalidPlaylist
+}
+
+func (c *Client) GetPlaylist(playlistID string) (*Playlist, error) {
+	apiURL := fmt.Sprintf("https://api.deezer.com/playlist/%s", playlistID)
+
+	var result struct {
+		ID       int64  `json:"id"`
+		Title    string `json:"title"`
+		NbTracks int    `json:"nb_tracks"`
+	}
+
+	_, err := c.getJSON(apiURL, &result)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Code == deezerErrNotFound {
+		return nil, ErrPlaylistNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if result.ID == 0 {
+		return nil, ErrPlaylistNotFound
+	}
+
+	limit := result.NbTracks
+	if limit <= 0 {
+		limit = 100
+	}
+	tracks, err := c.GetPlaylistTracks(playlistID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[Deezer] Playlist %s (%s): %d/%d pistes jouables", playlistID, result.Title, len(tracks), result.NbTracks)
+	return &Playlist{
+		ID:         playlistID,
+		Title:      result.Title,
+		TrackCount: result.NbTracks,
+		Tracks:     tracks,
+	}, nil
+}
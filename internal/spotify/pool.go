@@ -0,0 +1,168 @@
+package spotify
+
+import (
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"groupie-tracker/internal/models"
+)
+
+const (
+	// maxPoolTracks borne la réserve gardée pour chaque genre ; au-delà, les
+	// pistes reçues le plus tôt laissent leur place.
+	maxPoolTracks = 500
+
+	// previewLifetime est la validité prêtée à un extrait dont l'URL ne porte
+	// pas sa date d'expiration.
+	previewLifetime = time.Hour
+
+	// previewMargin est le temps qu'un extrait doit encore rester lisible
+	// pour être proposé : il faut tenir jusqu'à la fin de la manche.
+	previewMargin = 5 * time.Minute
+)
+
+// PoolStore persiste la réserve de pistes pour qu'une partie puisse démarrer
+// après un redémarrage même si Deezer est injoignable. Les pistes gardent
+// leur FetchedAt : les URL d'extrait sont signées et expirent.
+type PoolStore interface {
+	SavePoolTracks(genre string, tracks []*models.SpotifyTrack) error
+	LoadPoolTracks(genre string) ([]*models.SpotifyTrack, error)
+	DeletePoolTracksBefore(before time.Time) error
+}
+
+// trackPool garde, par genre, les pistes avec extrait déjà reçues de Deezer.
+// Elle sert de secours quand l'API ne répond plus.
+type trackPool struct {
+	genres map[string]map[string]*models.SpotifyTrack
+	loaded map[string]bool
+	store  PoolStore
+	mutex  sync.Mutex
+}
+
+func newTrackPool(store PoolStore) *trackPool {
+	if store != nil {
+		if err := store.DeletePoolTracksBefore(time.Now().Add(-maxStaleAge)); err != nil {
+			log.Printf("[Deezer] Erreur purge réserve: %v", err)
+		}
+	}
+
+	return &trackPool{
+		genres: make(map[string]map[string]*models.SpotifyTrack),
+		loaded: make(map[string]bool),
+		store:  store,
+	}
+}
+
+func (p *trackPool) remember(genre string, tracks []*models.SpotifyTrack) {
+	if len(tracks) == 0 {
+		return
+	}
+
+	p.mutex.Lock()
+	pool := p.genreLocked(genre)
+	for _, track := range tracks {
+		p.addLocked(pool, track)
+	}
+	p.mutex.Unlock()
+
+	if p.store != nil {
+		if err := p.store.SavePoolTracks(genre, tracks); err != nil {
+			log.Printf("[Deezer] Erreur sauvegarde réserve: %v", err)
+		}
+	}
+}
+
+// recall renvoie les pistes du genre dont l'extrait est encore lisible ; les
+// autres sont oubliées.
+func (p *trackPool) recall(genre string) []*models.SpotifyTrack {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	pool := p.genreLocked(genre)
+
+	if p.store != nil && !p.loaded[genre] {
+		p.loaded[genre] = true
+		stored, err := p.store.LoadPoolTracks(genre)
+		if err != nil {
+			log.Printf("[Deezer] Erreur lecture réserve: %v", err)
+		}
+		for _, track := range stored {
+			p.addLocked(pool, track)
+		}
+	}
+
+	expired := 0
+	tracks := make([]*models.SpotifyTrack, 0, len(pool))
+	for id, track := range pool {
+		if !previewUsable(track) {
+			delete(pool, id)
+			expired++
+			continue
+		}
+		tracks = append(tracks, track)
+	}
+	if expired > 0 {
+		log.Printf("[Deezer] Réserve %s: %d extraits expirés ignorés", genre, expired)
+	}
+	return tracks
+}
+
+// genreLocked renvoie la réserve du genre. L'appelant tient p.mutex.
+func (p *trackPool) genreLocked(genre string) map[string]*models.SpotifyTrack {
+	pool, exists := p.genres[genre]
+	if !exists {
+		pool = make(map[string]*models.SpotifyTrack)
+		p.genres[genre] = pool
+	}
+	return pool
+}
+
+// addLocked garde la réception la plus récente d'une piste et, réserve
+// pleine, évince la plus ancienne. L'appelant tient p.mutex.
+func (p *trackPool) addLocked(pool map[string]*models.SpotifyTrack, track *models.SpotifyTrack) {
+	if known, exists := pool[track.ID]; exists {
+		if track.FetchedAt.After(known.FetchedAt) {
+			pool[track.ID] = track
+		}
+		return
+	}
+
+	if len(pool) >= maxPoolTracks {
+		oldest := ""
+		for id, candidate := range pool {
+			if oldest == "" || candidate.FetchedAt.Before(pool[oldest].FetchedAt) {
+				oldest = id
+			}
+		}
+		if !track.FetchedAt.After(pool[oldest].FetchedAt) {
+			return
+		}
+		delete(pool, oldest)
+	}
+	pool[track.ID] = track
+}
+
+// previewUsable indique que l'extrait restera lisible au moins previewMargin.
+func previewUsable(track *models.SpotifyTrack) bool {
+	return time.Until(previewExpiry(track.PreviewURL, track.FetchedAt)) > previewMargin
+}
+
+// previewExpiry lit l'expiration de la signature Deezer, portée par le
+// paramètre hdnea=exp=<unix>~acl=...~hmac=... ; à défaut, l'extrait est tenu
+// pour valable previewLifetime après sa réception.
+func previewExpiry(previewURL string, fetchedAt time.Time) time.Time {
+	if parsed, err := url.Parse(previewURL); err == nil {
+		for _, part := range strings.Split(parsed.Query().Get("hdnea"), "~") {
+			if value, ok := strings.CutPrefix(part, "exp="); ok {
+				if exp, err := strconv.ParseInt(value, 10, 64); err == nil {
+					return time.Unix(exp, 0)
+				}
+			}
+		}
+	}
+	return fetchedAt.Add(previewLifetime)
+}
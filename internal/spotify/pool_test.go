@@ -0,0 +1,85 @@
+package spotify
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"groupie-tracker/internal/models"
+)
+
+func signedPreview(exp time.Time) string {
+	return fmt.Sprintf("https://cdnt-preview.dzcdn.net/api/1/1/a/b/c/0/abc.mp3?hdnea=exp=%d~acl=/api/1/1/a/b/c/0/abc.mp3*~data=user_id=0,application_id=42~hmac=deadbeef", exp.Unix())
+}
+
+func TestPreviewExpiry(t *testing.T) {
+	fetchedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	exp := fetchedAt.Add(20 * time.Minute)
+
+	tests := []struct {
+		name string
+		url  string
+		want time.Time
+	}{
+		{"signature deezer", signedPreview(exp), exp},
+		{"sans signature", "https://cdns-preview-d.dzcdn.net/stream/c-abc-1.mp3", fetchedAt.Add(previewLifetime)},
+		{"signature illisible", "https://cdnt-preview.dzcdn.net/abc.mp3?hdnea=exp=demain~hmac=x", fetchedAt.Add(previewLifetime)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := previewExpiry(tt.url, fetchedAt); !got.Equal(tt.want) {
+				t.Errorf("previewExpiry = %v, attendu %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackPoolSkipsExpiredPreviews(t *testing.T) {
+	now := time.Now()
+	pool := newTrackPool(nil)
+	pool.remember("pop", []*models.SpotifyTrack{
+		{ID: "1", PreviewURL: signedPreview(now.Add(time.Hour)), FetchedAt: now},
+		{ID: "2", PreviewURL: signedPreview(now.Add(-time.Minute)), FetchedAt: now.Add(-time.Hour)},
+		{ID: "3", PreviewURL: signedPreview(now.Add(previewMargin / 2)), FetchedAt: now},
+		{ID: "4", PreviewURL: "https://cdns-preview-d.dzcdn.net/stream/c-abc-1.mp3", FetchedAt: now.Add(-2 * previewLifetime)},
+	})
+
+	tracks := pool.recall("pop")
+	if len(tracks) != 1 || tracks[0].ID != "1" {
+		t.Fatalf("recall = %v, attendu la seule piste 1", tracks)
+	}
+	if len(pool.genres["pop"]) != 1 {
+		t.Errorf("les pistes expirées restent en mémoire: %d", len(pool.genres["pop"]))
+	}
+}
+
+func TestTrackPoolEvictsOldest(t *testing.T) {
+	now := time.Now()
+	pool := newTrackPool(nil)
+
+	var tracks []*models.SpotifyTrack
+	for i := 0; i < maxPoolTracks; i++ {
+		tracks = append(tracks, &models.SpotifyTrack{
+			ID:         fmt.Sprintf("old-%d", i),
+			PreviewURL: signedPreview(now.Add(time.Hour)),
+			FetchedAt:  now.Add(-time.Duration(maxPoolTracks-i) * time.Second),
+		})
+	}
+	pool.remember("rock", tracks)
+
+	pool.remember("rock", []*models.SpotifyTrack{
+		{ID: "new", PreviewURL: signedPreview(now.Add(time.Hour)), FetchedAt: now},
+	})
+
+	genre := pool.genres["rock"]
+	if len(genre) != maxPoolTracks {
+		t.Fatalf("taille de la réserve = %d, attendu %d", len(genre), maxPoolTracks)
+	}
+	if _, ok := genre["new"]; !ok {
+		t.Error("la piste la plus récente n'a pas été gardée")
+	}
+	if _, ok := genre["old-0"]; ok {
+		t.Error("la piste la plus ancienne n'a pas été évincée")
+	}
+}
@@ -0,0 +1,157 @@
+package spotify
+
+import (
+	"database/sql"
+	"time"
+
+	"groupie-tracker/internal/models"
+)
+
+// SQLiteCache implémente CacheStore sur la table deezer_cache, PoolStore
+// sur la table deezer_track_pool et TokenStore sur la table spotify_tokens.
+type SQLiteCache struct {
+	db *sql.DB
+}
+
+func NewSQLiteCache(db *sql.DB) *SQLiteCache {
+	return &SQLiteCache{db: db}
+}
+
+func (s *SQLiteCache) GetCached(key string) (*CacheEntry, error) {
+	var entry CacheEntry
+	var storedAt int64
+	err := s.db.QueryRow("SELECT body, stored_at FROM deezer_cache WHERE cache_key = ?", key).Scan(&entry.Body, &storedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	entry.StoredAt = time.Unix(storedAt, 0)
+	return &entry, nil
+}
+
+func (s *SQLiteCache) SaveCached(key string, entry *CacheEntry) error {
+	_, err := s.db.Exec(
+		"INSERT OR REPLACE INTO deezer_cache (cache_key, body, stored_at) VALUES (?, ?, ?)",
+		key, entry.Body, entry.StoredAt.Unix(),
+	)
+	return err
+}
+
+func (s *SQLiteCache) DeleteCachedBefore(before time.Time) error {
+	_, err := s.db.Exec("DELETE FROM deezer_cache WHERE stored_at < ?", before.Unix())
+	return err
+}
+
+// SavePoolTracks enregistre les pistes avec leur date de réception dans
+// seen_at, sans écraser une réception plus récente, puis ne garde que les
+// maxPoolTracks plus récentes du genre.
+func (s *SQLiteCache) SavePoolTracks(genre string, tracks []*models.SpotifyTrack) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, track := range tracks {
+		_, err := tx.Exec(`
+			INSERT INTO deezer_track_pool (genre, track_id, title, artist, album, preview_url, image_url, seen_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (genre, track_id) DO UPDATE SET
+				title = excluded.title,
+				artist = excluded.artist,
+				album = excluded.album,
+				preview_url = excluded.preview_url,
+				image_url = excluded.image_url,
+				seen_at = excluded.seen_at
+			WHERE excluded.seen_at >= deezer_track_pool.seen_at
+		`, genre, track.ID, track.Name, track.Artist, track.Album, track.PreviewURL, track.ImageURL, track.FetchedAt.Unix())
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM deezer_track_pool
+		WHERE genre = ? AND track_id NOT IN (
+			SELECT track_id FROM deezer_track_pool
+			WHERE genre = ?
+			ORDER BY seen_at DESC
+			LIMIT ?
+		)
+	`, genre, genre, maxPoolTracks)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteCache) LoadPoolTracks(genre string) ([]*models.SpotifyTrack, error) {
+	rows, err := s.db.Query(`
+		SELECT track_id, title, artist, album, preview_url, image_url, seen_at
+		FROM deezer_track_pool
+		WHERE genre = ?
+		ORDER BY seen_at DESC
+		LIMIT ?
+	`, genre, maxPoolTracks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tracks []*models.SpotifyTrack
+	for rows.Next() {
+		var track models.SpotifyTrack
+		var seenAt int64
+		if err := rows.Scan(&track.ID, &track.Name, &track.Artist, &track.Album, &track.PreviewURL, &track.ImageURL, &seenAt); err != nil {
+			return nil, err
+		}
+		track.FetchedAt = time.Unix(seenAt, 0)
+		tracks = append(tracks, &track)
+	}
+	return tracks, rows.Err()
+}
+
+func (s *SQLiteCache) DeletePoolTracksBefore(before time.Time) error {
+	_, err := s.db.Exec("DELETE FROM deezer_track_pool WHERE seen_at < ?", before.Unix())
+	return err
+}
+
+func (s *SQLiteCache) LoadToken() (*Token, error) {
+	var token Token
+	var expiresAt int64
+	err := s.db.QueryRow(`
+		SELECT access_token, expires_at FROM spotify_tokens
+		ORDER BY id DESC LIMIT 1
+	`).Scan(&token.AccessToken, &expiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	token.ExpiresAt = time.Unix(expiresAt, 0)
+	return &token, nil
+}
+
+// SaveToken remplace le token précédent : seul le plus récent sert.
+func (s *SQLiteCache) SaveToken(token *Token) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM spotify_tokens"); err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		"INSERT INTO spotify_tokens (access_token, expires_at) VALUES (?, ?)",
+		token.AccessToken, token.ExpiresAt.Unix(),
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,558 @@
+package spotify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"groupie-tracker/internal/models"
+)
+
+var (
+	ErrMissingCredentials = errors.New("SPOTIFY_CLIENT_ID et SPOTIFY_CLIENT_SECRET requis")
+	ErrInvalidCredentials = errors.New("identifiants Spotify refusés")
+	ErrSpotifyUnavailable = errors.New("API Spotify indisponible")
+	ErrSpotifyNotFound    = errors.New("ressource Spotify introuvable ou privée")
+	ErrInvalidSpotifyLink = errors.New("lien de playlist Spotify invalide")
+)
+
+// Adresses de production, remplaçables pour pointer vers un serveur local.
+const (
+	DefaultSpotifyAccountsURL = "https://accounts.spotify.com"
+	DefaultSpotifyAPIURL      = "https://api.spotify.com/v1"
+	DefaultSpotifyEmbedURL    = "https://open.spotify.com/embed"
+	DefaultSpotifyMarket      = "FR"
+)
+
+const (
+	// tokenMargin renouvelle le token un peu avant son expiration.
+	tokenMargin        = time.Minute
+	maxRetryAfter      = 5 * time.Second
+	maxSpotifyPlaylist = 500
+	spotifySearchLimit = 50
+	spotifySearchPages = 3
+	embedWorkers       = 8
+)
+
+var (
+	spotifyIDPattern       = regexp.MustCompile(`^[0-9A-Za-z]{22}$`)
+	spotifyPlaylistPattern = regexp.MustCompile(`(?:open\.spotify\.com/(?:intl-[a-z]{2}/)?playlist/|spotify:playlist:)([0-9A-Za-z]{22})`)
+	embedPreviewPattern    = regexp.MustCompile(`"audioPreview":\{"url":"([^"]+)"`)
+)
+
+// spotifyGenres traduit les genres du catalogue en filtre de recherche
+// Spotify. "top" n'a pas de genre : on cherche les sorties récentes.
+var spotifyGenres = map[string]string{
+	"pop":         `genre:"pop"`,
+	"rap":         `genre:"hip hop"`,
+	"rock":        `genre:"rock"`,
+	"dance":       `genre:"dance"`,
+	"electro":     `genre:"electro"`,
+	"rnb":         `genre:"r&b"`,
+	"alternative": `genre:"alternative"`,
+	"chanson":     `genre:"chanson"`,
+	"metal":       `genre:"metal"`,
+	"soul":        `genre:"soul"`,
+	"reggae":      `genre:"reggae"`,
+	"jazz":        `genre:"jazz"`,
+	"latino":      `genre:"latin"`,
+	"films":       `genre:"soundtrack"`,
+}
+
+// Token est un token d'accès client-credentials.
+type Token struct {
+	AccessToken string
+	ExpiresAt   time.Time
+}
+
+func (t *Token) valid() bool {
+	return t != nil && t.AccessToken != "" && time.Until(t.ExpiresAt) > tokenMargin
+}
+
+// TokenStore persiste le token entre deux redémarrages pour ne pas en
+// redemander un à chaque lancement.
+type TokenStore interface {
+	LoadToken() (*Token, error)
+	SaveToken(token *Token) error
+}
+
+// WebAPIConfig règle le client Spotify. Les URL vides prennent les adresses
+// de production ; HTTPClient et TokenStore sont optionnels.
+type WebAPIConfig struct {
+	ClientID     string
+	ClientSecret string
+	AccountsURL  string
+	APIURL       string
+	EmbedURL     string
+	Market       string
+	TokenStore   TokenStore
+	HTTPClient   *http.Client
+}
+
+// WebAPIClient interroge l'API Web Spotify avec le flux client-credentials :
+// playlists publiques, pistes et recherche par genre. Les extraits absents de
+// l'API (preview_url nul) sont cherchés sur la page embed de la piste.
+type WebAPIClient struct {
+	config     WebAPIConfig
+	httpClient *http.Client
+	token      *Token
+	tokenMutex sync.Mutex
+	previews   map[string]string
+	mutex      sync.Mutex
+}
+
+func NewWebAPIClient(config WebAPIConfig) (*WebAPIClient, error) {
+	if config.ClientID == "" || config.ClientSecret == "" {
+		return nil, ErrMissingCredentials
+	}
+	if config.AccountsURL == "" {
+		config.AccountsURL = DefaultSpotifyAccountsURL
+	}
+	if config.APIURL == "" {
+		config.APIURL = DefaultSpotifyAPIURL
+	}
+	if config.EmbedURL == "" {
+		config.EmbedURL = DefaultSpotifyEmbedURL
+	}
+	if config.Market == "" {
+		config.Market = DefaultSpotifyMarket
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{
+			Timeout: 15 * time.Second,
+		}
+	}
+
+	return &WebAPIClient{
+		config:     config,
+		httpClient: httpClient,
+		previews:   make(map[string]string),
+	}, nil
+}
+
+// Authenticate obtient un token (ou reprend celui en base) pour vérifier les
+// identifiants au démarrage.
+func (c *WebAPIClient) Authenticate() error {
+	_, err := c.accessToken(false)
+	return err
+}
+
+// accessToken renvoie le token en mémoire, sinon celui du TokenStore, sinon
+// en demande un nouveau. renew force une nouvelle demande (token révoqué).
+func (c *WebAPIClient) accessToken(renew bool) (string, error) {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+
+	if !renew && c.token.valid() {
+		return c.token.AccessToken, nil
+	}
+
+	if !renew && c.token == nil && c.config.TokenStore != nil {
+		stored, err := c.config.TokenStore.LoadToken()
+		if err != nil {
+			log.Printf("[Spotify] Erreur lecture token: %v", err)
+		}
+		if stored.valid() {
+			c.token = stored
+			return stored.AccessToken, nil
+		}
+	}
+
+	token, err := c.requestToken()
+	if err != nil {
+		return "", err
+	}
+	c.token = token
+
+	if c.config.TokenStore != nil {
+		if err := c.config.TokenStore.SaveToken(token); err != nil {
+			log.Printf("[Spotify] Erreur sauvegarde token: %v", err)
+		}
+	}
+
+	log.Printf("[Spotify] Nouveau token, expire à %s", token.ExpiresAt.Format("15:04"))
+	return token.AccessToken, nil
+}
+
+func (c *WebAPIClient) requestToken() (*Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	req, err := http.NewRequest(http.MethodPost, c.config.AccountsURL+"/api/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(c.config.ClientID, c.config.ClientSecret)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSpotifyUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		return nil, ErrInvalidCredentials
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: token HTTP %d", ErrSpotifyUnavailable, resp.StatusCode)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("%w: token illisible: %v", ErrSpotifyUnavailable, err)
+	}
+	if result.AccessToken == "" {
+		return nil, fmt.Errorf("%w: token vide", ErrSpotifyUnavailable)
+	}
+
+	return &Token{
+		AccessToken: result.AccessToken,
+		ExpiresAt:   time.Now().Add(time.Duration(result.ExpiresIn) * time.Second),
+	}, nil
+}
+
+// getJSON appelle l'API avec le token courant. Un 401 renouvelle le token une
+// fois ; 429 et 5xx sont retentés en respectant Retry-After.
+func (c *WebAPIClient) getJSON(path string, query url.Values, out interface{}) error {
+	apiURL := c.config.APIURL + path
+	if len(query) > 0 {
+		apiURL += "?" + query.Encode()
+	}
+
+	renewed := false
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		token, err := c.accessToken(false)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("%w: %v", ErrSpotifyUnavailable, err)
+			time.Sleep(baseBackoff << attempt)
+			continue
+		}
+
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusOK && err == nil:
+			return json.Unmarshal(body, out)
+		case resp.StatusCode == http.StatusUnauthorized && !renewed:
+			renewed = true
+			if _, err := c.accessToken(true); err != nil {
+				return err
+			}
+			attempt--
+			continue
+		case resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusForbidden:
+			return ErrSpotifyNotFound
+		case resp.StatusCode == http.StatusTooManyRequests:
+			lastErr = fmt.Errorf("%w: HTTP 429", ErrSpotifyUnavailable)
+			time.Sleep(retryAfter(resp.Header.Get("Retry-After"), baseBackoff<<attempt))
+		case resp.StatusCode >= 500:
+			lastErr = fmt.Errorf("%w: HTTP %d", ErrSpotifyUnavailable, resp.StatusCode)
+			time.Sleep(baseBackoff << attempt)
+		case err != nil:
+			lastErr = fmt.Errorf("%w: %v", ErrSpotifyUnavailable, err)
+		default:
+			return fmt.Errorf("%w: HTTP %d", ErrSpotifyUnavailable, resp.StatusCode)
+		}
+		log.Printf("[Spotify] Essai %d/%d échoué: %v", attempt+1, maxAttempts, lastErr)
+	}
+	return lastErr
+}
+
+func retryAfter(header string, fallback time.Duration) time.Duration {
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds < 0 {
+		return fallback
+	}
+	wait := time.Duration(seconds) * time.Second
+	if wait > maxRetryAfter {
+		wait = maxRetryAfter
+	}
+	return wait
+}
+
+type webAPITrack struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	PreviewURL string `json:"preview_url"`
+	Artists    []struct {
+		Name string `json:"name"`
+	} `json:"artists"`
+	Album struct {
+		Name   string `json:"name"`
+		Images []struct {
+			URL string `json:"url"`
+		} `json:"images"`
+	} `json:"album"`
+}
+
+func (t *webAPITrack) toModel() *models.SpotifyTrack {
+	track := &models.SpotifyTrack{
+		ID:         t.ID,
+		Name:       t.Name,
+		Album:      t.Album.Name,
+		PreviewURL: t.PreviewURL,
+	}
+	if len(t.Artists) > 0 {
+		track.Artist = t.Artists[0].Name
+	}
+	if len(t.Album.Images) > 0 {
+		track.ImageURL = t.Album.Images[0].URL
+	}
+	return track
+}
+
+// withPreviews complète les extraits manquants depuis la page embed et ne
+// garde que les pistes jouables.
+func (c *WebAPIClient) withPreviews(items []webAPITrack) []*models.SpotifyTrack {
+	tracks := make([]*models.SpotifyTrack, 0, len(items))
+	for i := range items {
+		if items[i].ID != "" {
+			tracks = append(tracks, items[i].toModel())
+		}
+	}
+
+	jobs := make(chan *models.SpotifyTrack)
+	var wg sync.WaitGroup
+	for w := 0; w < embedWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for track := range jobs {
+				track.PreviewURL = c.embedPreview(track.ID)
+			}
+		}()
+	}
+	for _, track := range tracks {
+		if track.PreviewURL == "" {
+			jobs <- track
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	playable := tracks[:0]
+	for _, track := range tracks {
+		if track.PreviewURL != "" {
+			playable = append(playable, track)
+		}
+	}
+	return playable
+}
+
+// embedPreview lit l'extrait publié dans la page embed d'une piste. Le
+// résultat, même vide, est gardé pour ne pas recharger la page.
+func (c *WebAPIClient) embedPreview(trackID string) string {
+	c.mutex.Lock()
+	preview, known := c.previews[trackID]
+	c.mutex.Unlock()
+	if known {
+		return preview
+	}
+
+	resp, err := c.httpClient.Get(c.config.EmbedURL + "/track/" + trackID)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+		if err == nil {
+			if match := embedPreviewPattern.FindSubmatch(body); match != nil {
+				preview = string(match[1])
+			}
+		}
+	}
+
+	c.mutex.Lock()
+	c.previews[trackID] = preview
+	c.mutex.Unlock()
+	return preview
+}
+
+func (c *WebAPIClient) GetTrack(trackID string) (*models.SpotifyTrack, error) {
+	var item webAPITrack
+	query := url.Values{"market": {c.config.Market}}
+	if err := c.getJSON("/tracks/"+url.PathEscape(trackID), query, &item); err != nil {
+		return nil, err
+	}
+
+	tracks := c.withPreviews([]webAPITrack{item})
+	if len(tracks) == 0 {
+		return nil, ErrNoTracks
+	}
+	return tracks[0], nil
+}
+
+// ParseSpotifyPlaylistID accepte un identifiant, un lien open.spotify.com ou
+// une URI spotify:playlist:.
+func ParseSpotifyPlaylistID(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if spotifyIDPattern.MatchString(input) {
+		return input, nil
+	}
+	if match := spotifyPlaylistPattern.FindStringSubmatch(input); match != nil {
+		return match[1], nil
+	}
+	return "", ErrInvalidSpotifyLink
+}
+
+// GetPlaylist lit une playlist publique page par page, dans la limite de
+// 500 pistes.
+func (c *WebAPIClient) GetPlaylist(playlistID string) (*Playlist, error) {
+	var meta struct {
+		Name   string `json:"name"`
+		Tracks struct {
+			Total int `json:"total"`
+		} `json:"tracks"`
+	}
+	query := url.Values{
+		"market": {c.config.Market},
+		"fields": {"name,tracks.total"},
+	}
+	if err := c.getJSON("/playlists/"+url.PathEscape(playlistID), query, &meta); err != nil {
+		return nil, err
+	}
+
+	var items []webAPITrack
+	for offset := 0; offset < meta.Tracks.Total && offset < maxSpotifyPlaylist; offset += 100 {
+		var page struct {
+			Items []struct {
+				Track *webAPITrack `json:"track"`
+			} `json:"items"`
+			Next string `json:"next"`
+		}
+		query := url.Values{
+			"market": {c.config.Market},
+			"limit":  {"100"},
+			"offset": {strconv.Itoa(offset)},
+		}
+		if err := c.getJSON("/playlists/"+url.PathEscape(playlistID)+"/tracks", query, &page); err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			if item.Track != nil {
+				items = append(items, *item.Track)
+			}
+		}
+		if page.Next == "" {
+			break
+		}
+	}
+
+	tracks := c.withPreviews(items)
+	log.Printf("[Spotify] Playlist %s (%s): %d/%d pistes jouables", playlistID, meta.Name, len(tracks), meta.Tracks.Total)
+	return &Playlist{
+		ID:         playlistID,
+		Title:      meta.Name,
+		TrackCount: meta.Tracks.Total,
+		Tracks:     tracks,
+	}, nil
+}
+
+// SpotifyGenres renvoie les genres du catalogue cherchables sur Spotify.
+func SpotifyGenres() []Genre {
+	genres := make([]Genre, 0, len(Genres))
+	for _, g := range Genres {
+		if _, ok := spotifyGenres[g.Slug]; ok || g.Slug == "top" {
+			genres = append(genres, g)
+		}
+	}
+	return genres
+}
+
+func spotifyGenreQuery(g Genre) string {
+	if query, ok := spotifyGenres[g.Slug]; ok {
+		return query
+	}
+	year := time.Now().Year()
+	return fmt.Sprintf("year:%d-%d", year-1, year)
+}
+
+// GetRandomTracksForBlindTest cherche des pistes du genre sur quelques pages
+// de résultats prises au hasard et renvoie jusqu'à count pistes jouables.
+func (c *WebAPIClient) GetRandomTracksForBlindTest(genre string, count int) ([]*models.SpotifyTrack, error) {
+	g, ok := FindGenre(genre)
+	if !ok {
+		return nil, ErrUnknownGenre
+	}
+	if _, ok := spotifyGenres[g.Slug]; !ok && g.Slug != "top" {
+		return nil, ErrUnknownGenre
+	}
+
+	seen := make(map[string]bool)
+	var allTracks []*models.SpotifyTrack
+	var lastErr error
+	for page := 0; page < spotifySearchPages && len(allTracks) < count*2; page++ {
+		var result struct {
+			Tracks struct {
+				Items []webAPITrack `json:"items"`
+			} `json:"tracks"`
+		}
+		query := url.Values{
+			"q":      {spotifyGenreQuery(g)},
+			"type":   {"track"},
+			"market": {c.config.Market},
+			"limit":  {strconv.Itoa(spotifySearchLimit)},
+			"offset": {strconv.Itoa(rand.N(4) * spotifySearchLimit)},
+		}
+		if err := c.getJSON("/search", query, &result); err != nil {
+			log.Printf("[Spotify] Erreur recherche (%s): %v", g.Name, err)
+			lastErr = err
+			continue
+		}
+
+		for _, track := range c.withPreviews(result.Tracks.Items) {
+			key := strings.ToLower(track.Name + track.Artist)
+			if !seen[key] {
+				seen[key] = true
+				allTracks = append(allTracks, track)
+			}
+		}
+	}
+
+	if len(allTracks) == 0 {
+		if lastErr != nil {
+			return nil, lastErr
+		}
+		return nil, ErrNoTracks
+	}
+
+	rand.Shuffle(len(allTracks), func(i, j int) {
+		allTracks[i], allTracks[j] = allTracks[j], allTracks[i]
+	})
+
+	if count > len(allTracks) {
+		count = len(allTracks)
+	}
+
+	log.Printf("[Spotify] Retourne %d pistes %s pour le blind test", count, g.Name)
+	return allTracks[:count], nil
+}
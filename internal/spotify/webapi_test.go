@@ -0,0 +1,424 @@
+package spotify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// embedWithPreview reprend la forme de la page open.spotify.com/embed/track :
+// l'extrait est dans le JSON __NEXT_DATA__.
+const embedWithPreview = `<!DOCTYPE html><html><head><title>Spotify</title></head><body>
+<script id="__NEXT_DATA__" type="application/json">{"props":{"pageProps":{"state":{"data":{"entity":{"type":"track","name":"Intro","uri":"spotify:track:%s","audioPreview":{"url":"https://p.scdn.co/mp3-preview/%s","format":"MP3_96"},"isPlayable":true}}}}}}</script>
+</body></html>`
+
+const embedWithoutPreview = `<!DOCTYPE html><html><head><title>Spotify</title></head><body>
+<script id="__NEXT_DATA__" type="application/json">{"props":{"pageProps":{"state":{"data":{"entity":{"type":"track","name":"Intro","audioPreview":null,"isPlayable":false}}}}}}</script>
+</body></html>`
+
+type memoryTokenStore struct {
+	token *Token
+	saves int
+	mutex sync.Mutex
+}
+
+func (s *memoryTokenStore) LoadToken() (*Token, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.token, nil
+}
+
+func (s *memoryTokenStore) SaveToken(token *Token) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.token = token
+	s.saves++
+	return nil
+}
+
+// fakeSpotify simule les trois serveurs Spotify : /api/token (accounts),
+// /v1 (API Web) et /embed (pages embed).
+type fakeSpotify struct {
+	server        *httptest.Server
+	tokenRequests int
+	revoked       map[string]bool
+	apiPaths      []string
+	embedRequests map[string]int
+	embedPreviews map[string]bool
+	tracks        map[string]string
+	playlistSize  int
+	mutex         sync.Mutex
+}
+
+func newFakeSpotify(t *testing.T) *fakeSpotify {
+	t.Helper()
+	fake := &fakeSpotify{
+		revoked:       make(map[string]bool),
+		embedRequests: make(map[string]int),
+		embedPreviews: make(map[string]bool),
+		tracks:        make(map[string]string),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/token", fake.handleToken)
+	mux.HandleFunc("/v1/", fake.handleAPI)
+	mux.HandleFunc("/embed/track/", fake.handleEmbed)
+	fake.server = httptest.NewServer(mux)
+	t.Cleanup(fake.server.Close)
+	return fake
+}
+
+func (f *fakeSpotify) client(t *testing.T, store TokenStore) *WebAPIClient {
+	t.Helper()
+	client, err := NewWebAPIClient(WebAPIConfig{
+		ClientID:     "id",
+		ClientSecret: "secret",
+		AccountsURL:  f.server.URL,
+		APIURL:       f.server.URL + "/v1",
+		EmbedURL:     f.server.URL + "/embed",
+		TokenStore:   store,
+	})
+	if err != nil {
+		t.Fatalf("NewWebAPIClient: %v", err)
+	}
+	return client
+}
+
+func (f *fakeSpotify) handleToken(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if r.Method != http.MethodPost || !ok || id != "id" || secret != "secret" ||
+		r.FormValue("grant_type") != "client_credentials" {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusBadRequest)
+		return
+	}
+
+	f.mutex.Lock()
+	f.tokenRequests++
+	token := fmt.Sprintf("token-%d", f.tokenRequests)
+	f.mutex.Unlock()
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+func (f *fakeSpotify) handleAPI(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	revoked := f.revoked[token]
+	f.apiPaths = append(f.apiPaths, r.URL.Path+"?"+r.URL.Query().Get("offset"))
+	f.mutex.Unlock()
+
+	if token == "" || revoked {
+		http.Error(w, `{"error":{"status":401,"message":"The access token expired"}}`, http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1")
+	switch {
+	case strings.HasPrefix(path, "/tracks/"):
+		id := strings.TrimPrefix(path, "/tracks/")
+		preview, exists := f.tracks[id]
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(apiTrack(id, preview))
+	case path == "/playlists/list":
+		fmt.Fprintf(w, `{"name":"Soirée","tracks":{"total":%d}}`, f.playlistSize)
+	case path == "/playlists/list/tracks":
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		var items []interface{}
+		for i := offset; i < offset+limit && i < f.playlistSize; i++ {
+			if i == 0 {
+				// Piste locale ou supprimée : Spotify renvoie "track": null.
+				items = append(items, map[string]interface{}{"track": nil})
+				continue
+			}
+			items = append(items, map[string]interface{}{
+				"track": apiTrack(fmt.Sprintf("track%d", i), fmt.Sprintf("https://p.scdn.co/mp3-preview/%d", i)),
+			})
+		}
+		next := ""
+		if offset+limit < f.playlistSize {
+			next = fmt.Sprintf("%s/v1/playlists/list/tracks?offset=%d", f.server.URL, offset+limit)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items, "next": next})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeSpotify) handleEmbed(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/embed/track/")
+
+	f.mutex.Lock()
+	f.embedRequests[id]++
+	hasPreview := f.embedPreviews[id]
+	f.mutex.Unlock()
+
+	if hasPreview {
+		fmt.Fprintf(w, embedWithPreview, id, id)
+		return
+	}
+	fmt.Fprint(w, embedWithoutPreview)
+}
+
+func apiTrack(id, preview string) map[string]interface{} {
+	track := map[string]interface{}{
+		"id":      id,
+		"name":    "Titre " + id,
+		"artists": []map[string]string{{"name": "Artiste " + id}},
+		"album": map[string]interface{}{
+			"name":   "Album " + id,
+			"images": []map[string]string{{"url": "https://i.scdn.co/image/" + id}},
+		},
+		"preview_url": nil,
+	}
+	if preview != "" {
+		track["preview_url"] = preview
+	}
+	return track
+}
+
+func TestWebAPITokenIsCachedInStore(t *testing.T) {
+	fake := newFakeSpotify(t)
+	fake.tracks["abc"] = "https://p.scdn.co/mp3-preview/abc"
+	store := &memoryTokenStore{}
+
+	client := fake.client(t, store)
+	if err := client.Authenticate(); err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if _, err := client.GetTrack("abc"); err != nil {
+		t.Fatalf("GetTrack: %v", err)
+	}
+	if fake.tokenRequests != 1 {
+		t.Errorf("%d demandes de token, attendu 1", fake.tokenRequests)
+	}
+	if store.saves != 1 || store.token.AccessToken != "token-1" {
+		t.Fatalf("token non enregistré: %+v", store.token)
+	}
+	if remaining := time.Until(store.token.ExpiresAt); remaining < 59*time.Minute || remaining > time.Hour {
+		t.Errorf("expiration inattendue: dans %v", remaining)
+	}
+
+	// Un nouveau client (redémarrage) reprend le token enregistré.
+	restarted := fake.client(t, store)
+	if _, err := restarted.GetTrack("abc"); err != nil {
+		t.Fatalf("GetTrack après redémarrage: %v", err)
+	}
+	if fake.tokenRequests != 1 {
+		t.Errorf("token redemandé malgré le TokenStore: %d demandes", fake.tokenRequests)
+	}
+
+	// Un token enregistré sur le point d'expirer est remplacé.
+	store.token = &Token{AccessToken: "token-1", ExpiresAt: time.Now().Add(tokenMargin / 2)}
+	if err := fake.client(t, store).Authenticate(); err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if fake.tokenRequests != 2 || store.token.AccessToken != "token-2" {
+		t.Errorf("token expiré non renouvelé: %d demandes, %+v", fake.tokenRequests, store.token)
+	}
+}
+
+func TestWebAPIInvalidCredentials(t *testing.T) {
+	fake := newFakeSpotify(t)
+
+	client, err := NewWebAPIClient(WebAPIConfig{
+		ClientID:     "id",
+		ClientSecret: "mauvais",
+		AccountsURL:  fake.server.URL,
+		APIURL:       fake.server.URL + "/v1",
+	})
+	if err != nil {
+		t.Fatalf("NewWebAPIClient: %v", err)
+	}
+	if err := client.Authenticate(); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate = %v, attendu %v", err, ErrInvalidCredentials)
+	}
+
+	if _, err := NewWebAPIClient(WebAPIConfig{ClientID: "id"}); !errors.Is(err, ErrMissingCredentials) {
+		t.Errorf("NewWebAPIClient sans secret = %v, attendu %v", err, ErrMissingCredentials)
+	}
+}
+
+func TestWebAPIRenewsTokenAfter401(t *testing.T) {
+	fake := newFakeSpotify(t)
+	fake.tracks["abc"] = "https://p.scdn.co/mp3-preview/abc"
+	fake.revoked["revoked"] = true
+	store := &memoryTokenStore{token: &Token{AccessToken: "revoked", ExpiresAt: time.Now().Add(time.Hour)}}
+
+	track, err := fake.client(t, store).GetTrack("abc")
+	if err != nil {
+		t.Fatalf("GetTrack: %v", err)
+	}
+	if track.ID != "abc" {
+		t.Errorf("piste = %+v", track)
+	}
+	if fake.tokenRequests != 1 {
+		t.Errorf("%d demandes de token, attendu 1", fake.tokenRequests)
+	}
+	if store.token.AccessToken != "token-1" {
+		t.Errorf("token renouvelé non enregistré: %+v", store.token)
+	}
+	if len(fake.apiPaths) != 2 {
+		t.Errorf("appels API = %v, attendu l'appel refusé puis sa reprise", fake.apiPaths)
+	}
+}
+
+func TestWebAPIStopsAfterSecond401(t *testing.T) {
+	fake := newFakeSpotify(t)
+	fake.tracks["abc"] = "https://p.scdn.co/mp3-preview/abc"
+	fake.revoked["token-1"] = true
+	fake.revoked["token-2"] = true
+
+	_, err := fake.client(t, nil).GetTrack("abc")
+	if !errors.Is(err, ErrSpotifyUnavailable) {
+		t.Errorf("GetTrack = %v, attendu %v", err, ErrSpotifyUnavailable)
+	}
+	if fake.tokenRequests != 2 {
+		t.Errorf("%d demandes de token, attendu 2 (initial puis un seul renouvellement)", fake.tokenRequests)
+	}
+}
+
+func TestWebAPIPlaylistPaging(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		wantPages []string
+		wantCount int
+	}{
+		{"une page", 40, []string{"/v1/playlists/list?", "/v1/playlists/list/tracks?0"}, 39},
+		{"deux pages", 150, []string{"/v1/playlists/list?", "/v1/playlists/list/tracks?0", "/v1/playlists/list/tracks?100"}, 149},
+		{"limite de 500", 650, []string{
+			"/v1/playlists/list?",
+			"/v1/playlists/list/tracks?0",
+			"/v1/playlists/list/tracks?100",
+			"/v1/playlists/list/tracks?200",
+			"/v1/playlists/list/tracks?300",
+			"/v1/playlists/list/tracks?400",
+		}, 499},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeSpotify(t)
+			fake.playlistSize = tt.size
+
+			playlist, err := fake.client(t, nil).GetPlaylist("list")
+			if err != nil {
+				t.Fatalf("GetPlaylist: %v", err)
+			}
+			if playlist.Title != "Soirée" || playlist.TrackCount != tt.size {
+				t.Errorf("playlist = %q, %d pistes", playlist.Title, playlist.TrackCount)
+			}
+			if len(playlist.Tracks) != tt.wantCount {
+				t.Errorf("%d pistes jouables, attendu %d", len(playlist.Tracks), tt.wantCount)
+			}
+			if fmt.Sprint(fake.apiPaths) != fmt.Sprint(tt.wantPages) {
+				t.Errorf("appels = %v, attendu %v", fake.apiPaths, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestWebAPIGetTrack(t *testing.T) {
+	tests := []struct {
+		name        string
+		id          string
+		apiPreview  string
+		embed       bool
+		wantPreview string
+		wantErr     error
+		wantEmbed   int
+	}{
+		{"extrait dans l'API", "api", "https://p.scdn.co/mp3-preview/api", false, "https://p.scdn.co/mp3-preview/api", nil, 0},
+		{"extrait de la page embed", "embed", "", true, "https://p.scdn.co/mp3-preview/embed", nil, 1},
+		{"aucun extrait", "silent", "", false, "", ErrNoTracks, 1},
+		{"piste inconnue", "missing", "", false, "", ErrSpotifyNotFound, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeSpotify(t)
+			if tt.id != "missing" {
+				fake.tracks[tt.id] = tt.apiPreview
+			}
+			fake.embedPreviews[tt.id] = tt.embed
+
+			track, err := fake.client(t, nil).GetTrack(tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetTrack = %v, attendu %v", err, tt.wantErr)
+			}
+			if fake.embedRequests[tt.id] != tt.wantEmbed {
+				t.Errorf("%d pages embed lues, attendu %d", fake.embedRequests[tt.id], tt.wantEmbed)
+			}
+			if err != nil {
+				return
+			}
+			if track.PreviewURL != tt.wantPreview {
+				t.Errorf("extrait = %q, attendu %q", track.PreviewURL, tt.wantPreview)
+			}
+			if track.Name != "Titre "+tt.id || track.Artist != "Artiste "+tt.id ||
+				track.Album != "Album "+tt.id || track.ImageURL != "https://i.scdn.co/image/"+tt.id {
+				t.Errorf("piste mal convertie: %+v", track)
+			}
+		})
+	}
+}
+
+func TestEmbedPreview(t *testing.T) {
+	fake := newFakeSpotify(t)
+	fake.embedPreviews["with"] = true
+	client := fake.client(t, nil)
+
+	if got := client.embedPreview("with"); got != "https://p.scdn.co/mp3-preview/with" {
+		t.Errorf("embedPreview = %q", got)
+	}
+	if got := client.embedPreview("without"); got != "" {
+		t.Errorf("embedPreview sans extrait = %q, attendu vide", got)
+	}
+
+	// Le résultat, même vide, n'est pas redemandé.
+	client.embedPreview("with")
+	client.embedPreview("without")
+	if fake.embedRequests["with"] != 1 || fake.embedRequests["without"] != 1 {
+		t.Errorf("pages embed relues: %v", fake.embedRequests)
+	}
+}
+
+func TestEmbedPreviewPattern(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want string
+	}{
+		{"extrait", fmt.Sprintf(embedWithPreview, "id", "abc"), "https://p.scdn.co/mp3-preview/abc"},
+		{"extrait nul", embedWithoutPreview, ""},
+		{"page sans données", "<html><body>Page introuvable</body></html>", ""},
+		{"champs dans un autre ordre", `{"audioPreview":{"format":"MP3_96","url":"https://p.scdn.co/mp3-preview/abc"}}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if match := embedPreviewPattern.FindStringSubmatch(tt.page); match != nil {
+				got = match[1]
+			}
+			if got != tt.want {
+				t.Errorf("extrait = %q, attendu %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package stats
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"groupie-tracker/internal/auth"
+	"groupie-tracker/internal/games"
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/rooms"
+)
+
+const (
+	DefaultPerPage = 20
+	MaxPerPage     = 100
+)
+
+type Handler struct {
+	templateDir    string
+	scores         rooms.ScoreStore
+	users          *auth.Service
+	sessionManager *auth.SessionManager
+}
+
+func NewHandler(templateDir string, scores rooms.ScoreStore, users *auth.Service, sessionManager *auth.SessionManager) *Handler {
+	return &Handler{
+		templateDir:    templateDir,
+		scores:         scores,
+		users:          users,
+		sessionManager: sessionManager,
+	}
+}
+
+type LeaderboardResponse struct {
+	Entries    []rooms.LeaderboardEntry `json:"entries"`
+	Me         *rooms.LeaderboardEntry  `json:"me"`
+	MeOnPage   bool                     `json:"me_on_page"`
+	GameType   models.GameType          `json:"game_type"`
+	Period     string                   `json:"period"`
+	Metric     string                   `json:"metric"`
+	Page       int                      `json:"page"`
+	PerPage    int                      `json:"per_page"`
+	Total      int                      `json:"total"`
+	TotalPages int                      `json:"total_pages"`
+}
+
+func (h *Handler) HandleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	user, _ := h.sessionManager.GetUserFromRequest(r)
+
+	board, err := h.buildLeaderboard(r, user)
+	if err != nil {
+		log.Printf("[STATS] Erreur classement: %v", err)
+		http.Error(w, "Erreur interne", http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Title":    "Classement",
+		"User":     user,
+		"Board":    board,
+		"Games":    games.GetRegistry().All(),
+		"HasPrev":  board.Page > 1,
+		"HasNext":  board.Page < board.TotalPages,
+		"PrevPage": board.Page - 1,
+		"NextPage": board.Page + 1,
+	}
+
+	tmpl, err := template.ParseFiles(filepath.Join(h.templateDir, "leaderboard.html"))
+	if err != nil {
+		log.Printf("[STATS] Erreur chargement template leaderboard.html: %v", err)
+		http.Error(w, "Erreur interne", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.Execute(w, data); err != nil {
+		log.Printf("[STATS] Erreur exécution template: %v", err)
+		http.Error(w, "Erreur interne", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) HandleLeaderboardAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, _ := h.sessionManager.GetUserFromRequest(r)
+
+	board, err := h.buildLeaderboard(r, user)
+	if err != nil {
+		log.Printf("[STATS] Erreur classement: %v", err)
+		http.Error(w, "Erreur interne", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"leaderboard": board,
+	})
+}
+
+// buildLeaderboard lit les filtres de la requête (game_type, period, metric,
+// page, per_page). Les valeurs inconnues retombent sur les valeurs par défaut.
+func (h *Handler) buildLeaderboard(r *http.Request, user *models.User) (*LeaderboardResponse, error) {
+	query := r.URL.Query()
+
+	gameType := models.GameType(query.Get("game_type"))
+	if _, err := games.GetRegistry().Get(gameType); err != nil {
+		gameType = ""
+	}
+
+	period := query.Get("period")
+	switch period {
+	case rooms.LeaderboardPeriodMonth, rooms.LeaderboardPeriodWeek:
+	default:
+		period = rooms.LeaderboardPeriodAll
+	}
+
+	metric := query.Get("metric")
+	switch metric {
+	case rooms.LeaderboardMetricAverage, rooms.LeaderboardMetricWins, rooms.LeaderboardMetricGames:
+	default:
+		metric = rooms.LeaderboardMetricTotal
+	}
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = DefaultPerPage
+	}
+	if perPage > MaxPerPage {
+		perPage = MaxPerPage
+	}
+
+	filter := rooms.LeaderboardFilter{
+		GameType: gameType,
+		Period:   period,
+		Metric:   metric,
+		Limit:    perPage,
+		Offset:   (page - 1) * perPage,
+	}
+
+	entries, total, err := h.scores.GetLeaderboard(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	board := &LeaderboardResponse{
+		Entries:    entries,
+		GameType:   gameType,
+		Period:     period,
+		Metric:     metric,
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: (total + perPage - 1) / perPage,
+	}
+
+	if user != nil {
+		board.Me, err = h.scores.GetLeaderboardRank(user.ID, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.UserID == user.ID {
+				board.MeOnPage = true
+				break
+			}
+		}
+	}
+
+	return board, nil
+}
+
+const (
+	DefaultHistoryLimit = 20
+	MaxHistoryLimit     = 100
+)
+
+type Profile struct {
+	UserID      int64                    `json:"user_id"`
+	Pseudo      string                   `json:"pseudo"`
+	MemberSince time.Time                `json:"member_since"`
+	GamesPlayed int                      `json:"games_played"`
+	Wins        int                      `json:"wins"`
+	WinRate     float64                  `json:"win_rate"`
+	BestScore   int                      `json:"best_score"`
+	Stats       []rooms.GameTypeStats    `json:"stats"`
+	History     []rooms.GameHistoryEntry `json:"history"`
+}
+
+func (h *Handler) HandleProfile(w http.ResponseWriter, r *http.Request) {
+	user, _ := h.sessionManager.GetUserFromRequest(r)
+
+	pseudo := strings.TrimPrefix(r.URL.Path, "/profile/")
+	if pseudo == "" {
+		if user == nil {
+			http.Redirect(w, r, "/login?redirect=/profile/", http.StatusSeeOther)
+			return
+		}
+		http.Redirect(w, r, "/profile/"+url.PathEscape(user.Pseudo), http.StatusSeeOther)
+		return
+	}
+
+	profile, err := h.buildProfile(pseudo, DefaultHistoryLimit)
+	if err == auth.ErrUserNotFound {
+		http.Error(w, "Joueur introuvable", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[STATS] Erreur profil %s: %v", pseudo, err)
+		http.Error(w, "Erreur interne", http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Title":   "Profil de " + profile.Pseudo,
+		"User":    user,
+		"Profile": profile,
+		"IsMe":    user != nil && user.ID == profile.UserID,
+	}
+
+	tmpl, err := template.ParseFiles(filepath.Join(h.templateDir, "profile.html"))
+	if err != nil {
+		log.Printf("[STATS] Erreur chargement template profile.html: %v", err)
+		http.Error(w, "Erreur interne", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.Execute(w, data); err != nil {
+		log.Printf("[STATS] Erreur exécution template: %v", err)
+		http.Error(w, "Erreur interne", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) HandleProfileAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pseudo := strings.TrimPrefix(r.URL.Path, "/api/profile/")
+	if pseudo == "" {
+		http.Error(w, "Pseudo manquant", http.StatusBadRequest)
+		return
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = DefaultHistoryLimit
+	}
+	if limit > MaxHistoryLimit {
+		limit = MaxHistoryLimit
+	}
+
+	profile, err := h.buildProfile(pseudo, limit)
+	if err == auth.ErrUserNotFound {
+		http.Error(w, "Joueur introuvable", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[STATS] Erreur profil %s: %v", pseudo, err)
+		http.Error(w, "Erreur interne", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"profile": profile,
+	})
+}
+
+// HandleGameDetailsAPI sert le détail manche par manche d'une partie :
+// GET /api/games/{id}, où id est le game_id d'une entrée de l'historique.
+func (h *Handler) HandleGameDetailsAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+		return
+	}
+
+	gameID, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/api/games/"), 10, 64)
+	if err != nil {
+		http.Error(w, "Identifiant de partie invalide", http.StatusBadRequest)
+		return
+	}
+
+	details, err := h.scores.GetGameDetails(gameID)
+	if err == rooms.ErrGameNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[STATS] Erreur détail partie %d: %v", gameID, err)
+		http.Error(w, "Erreur interne", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"game":    details,
+	})
+}
+
+func (h *Handler) buildProfile(pseudo string, limit int) (*Profile, error) {
+	user, err := h.users.GetUserByPseudo(pseudo)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := h.scores.GetUserGameStats(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := h.scores.GetUserGameHistory(user.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &Profile{
+		UserID:      user.ID,
+		Pseudo:      user.Pseudo,
+		MemberSince: user.CreatedAt,
+		Stats:       stats,
+		History:     history,
+	}
+
+	for _, entry := range stats {
+		profile.GamesPlayed += entry.GamesPlayed
+		profile.Wins += entry.Wins
+		if entry.BestScore > profile.BestScore {
+			profile.BestScore = entry.BestScore
+		}
+	}
+	if profile.GamesPlayed > 0 {
+		profile.WinRate = float64(profile.Wins) * 100 / float64(profile.GamesPlayed)
+	}
+
+	return profile, nil
+}
@@ -0,0 +1,217 @@
+package stats
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"groupie-tracker/internal/auth"
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/rooms"
+)
+
+const testTemplateDir = "../../web/templates"
+
+// newTestHandler crée Alice et Bob puis enregistre deux parties : Alice
+// gagne la première (50 contre 20) et perd la seconde (10 contre 40).
+func newTestHandler(t *testing.T) (*Handler, *auth.SessionManager, *models.User) {
+	t.Helper()
+	authStore := auth.NewMemoryStore()
+	alice, err := authStore.CreateUser("Alice", "alice@example.com", "hash")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	bob, err := authStore.CreateUser("Bob", "bob@example.com", "hash")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	scores := rooms.NewMemoryStore()
+	for i, result := range [][2]int{{50, 20}, {10, 40}} {
+		room := &models.Room{
+			ID:       "salle-" + strconv.Itoa(i),
+			Name:     "Salle " + strconv.Itoa(i),
+			GameType: models.GameTypeBlindTest,
+			Players: map[int64]*models.Player{
+				alice.ID: {UserID: alice.ID, Pseudo: alice.Pseudo, Score: result[0]},
+				bob.ID:   {UserID: bob.ID, Pseudo: bob.Pseudo, Score: result[1]},
+			},
+		}
+		rounds := map[int64][]int{alice.ID: {result[0]}, bob.ID: {result[1]}}
+		if err := scores.SaveGameScores(room, rounds); err != nil {
+			t.Fatalf("SaveGameScores: %v", err)
+		}
+	}
+
+	users := auth.NewService(authStore)
+	sessions := auth.NewSessionManager(authStore, users)
+	return NewHandler(testTemplateDir, scores, users, sessions), sessions, alice
+}
+
+// loggedIn ajoute à la requête le cookie d'une session ouverte pour user.
+func loggedIn(t *testing.T, sessions *auth.SessionManager, r *http.Request, user *models.User) *http.Request {
+	t.Helper()
+	session, err := sessions.CreateSession(user.ID)
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	recorder := httptest.NewRecorder()
+	sessions.SetSessionCookie(recorder, session)
+	for _, cookie := range recorder.Result().Cookies() {
+		r.AddCookie(cookie)
+	}
+	return r
+}
+
+func TestHandleProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		loggedIn bool
+		status   int
+		location string
+		contains string
+	}{
+		{"profil", "/profile/Alice", false, http.StatusOK, "", "Alice"},
+		{"joueur inconnu", "/profile/Carol", false, http.StatusNotFound, "", ""},
+		{"mon profil, anonyme", "/profile/", false, http.StatusSeeOther, "/login?redirect=/profile/", ""},
+		{"mon profil, connecté", "/profile/", true, http.StatusSeeOther, "/profile/Alice", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, sessions, alice := newTestHandler(t)
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.loggedIn {
+				r = loggedIn(t, sessions, r, alice)
+			}
+			w := httptest.NewRecorder()
+			h.HandleProfile(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("statut %d, attendu %d: %s", w.Code, tt.status, w.Body.String())
+			}
+			if location := w.Header().Get("Location"); location != tt.location {
+				t.Errorf("redirection vers %q, attendu %q", location, tt.location)
+			}
+			if !strings.Contains(w.Body.String(), tt.contains) {
+				t.Errorf("page sans %q", tt.contains)
+			}
+		})
+	}
+}
+
+type profileResponse struct {
+	Success bool    `json:"success"`
+	Profile Profile `json:"profile"`
+}
+
+func TestHandleProfileAPI(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		status  int
+		history int
+	}{
+		{"profil", http.MethodGet, "/api/profile/Alice", http.StatusOK, 2},
+		{"historique limité", http.MethodGet, "/api/profile/Alice?limit=1", http.StatusOK, 1},
+		{"limite illisible", http.MethodGet, "/api/profile/Alice?limit=abc", http.StatusOK, 2},
+		{"joueur inconnu", http.MethodGet, "/api/profile/Carol", http.StatusNotFound, 0},
+		{"pseudo manquant", http.MethodGet, "/api/profile/", http.StatusBadRequest, 0},
+		{"méthode refusée", http.MethodPost, "/api/profile/Alice", http.StatusMethodNotAllowed, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, _ := newTestHandler(t)
+			w := httptest.NewRecorder()
+			h.HandleProfileAPI(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("statut %d, attendu %d: %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var response profileResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("réponse illisible: %v", err)
+			}
+			profile := response.Profile
+			if !response.Success || profile.Pseudo != "Alice" || profile.GamesPlayed != 2 || profile.Wins != 1 ||
+				profile.BestScore != 50 || profile.WinRate != 50 {
+				t.Errorf("profil = %+v", profile)
+			}
+			if len(profile.History) != tt.history {
+				t.Errorf("%d parties dans l'historique, attendu %d", len(profile.History), tt.history)
+			}
+			for _, entry := range profile.History {
+				if entry.GameID == 0 || entry.Players != 2 {
+					t.Errorf("entrée d'historique = %+v", entry)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleGameDetailsAPI(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+
+	// Le détail s'ouvre avec le game_id d'une entrée de l'historique.
+	w := httptest.NewRecorder()
+	h.HandleProfileAPI(w, httptest.NewRequest(http.MethodGet, "/api/profile/Alice", nil))
+	var profile profileResponse
+	if err := json.NewDecoder(w.Body).Decode(&profile); err != nil || len(profile.Profile.History) == 0 {
+		t.Fatalf("historique illisible: %v", err)
+	}
+	lost := profile.Profile.History[0]
+	if lost.Score != 10 {
+		t.Fatalf("dernière partie = %+v, attendu celle à 10 points", lost)
+	}
+	gamePath := "/api/games/" + strconv.FormatInt(lost.GameID, 10)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+	}{
+		{"partie de l'historique", http.MethodGet, gamePath, http.StatusOK},
+		{"partie inconnue", http.MethodGet, "/api/games/999", http.StatusNotFound},
+		{"identifiant illisible", http.MethodGet, "/api/games/abc", http.StatusBadRequest},
+		{"méthode refusée", http.MethodPost, gamePath, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.HandleGameDetailsAPI(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("statut %d, attendu %d: %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Game rooms.GameDetails `json:"game"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("réponse illisible: %v", err)
+			}
+			game := response.Game
+			if game.ID != lost.GameID || game.RoomID != lost.RoomID || game.Rounds != 1 || len(game.Players) != 2 {
+				t.Fatalf("partie = %+v", game)
+			}
+			if winner := game.Players[0]; winner.Pseudo != "Bob" || winner.Score != 40 || winner.Rank != 1 {
+				t.Errorf("vainqueur = %+v, attendu Bob à 40 points", winner)
+			}
+		})
+	}
+}
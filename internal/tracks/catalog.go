@@ -0,0 +1,85 @@
+package tracks
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"groupie-tracker/internal/models"
+)
+
+// CatalogEntry est une piste du catalogue JSON (TRACKS_CATALOG) :
+//
+//	[{"title": "...", "artist": "...", "preview_url": "https://...", "genre": "Rock"}]
+type CatalogEntry struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Artist     string `json:"artist"`
+	Album      string `json:"album"`
+	PreviewURL string `json:"preview_url"`
+	ImageURL   string `json:"image_url"`
+	Genre      string `json:"genre"`
+}
+
+// CatalogProvider sert une liste figée de pistes chargée au démarrage.
+type CatalogProvider struct {
+	tracks []*models.SpotifyTrack
+	genres map[string]string
+}
+
+func NewCatalogProvider(path string) (*CatalogProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []CatalogEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("catalogue %s: %w", path, err)
+	}
+
+	provider := &CatalogProvider{genres: make(map[string]string)}
+	for i, entry := range entries {
+		if entry.Title == "" || entry.Artist == "" || entry.PreviewURL == "" {
+			log.Printf("[Tracks] Catalogue: entrée %d ignorée (titre, artiste ou preview manquant)", i)
+			continue
+		}
+		id := entry.ID
+		if id == "" {
+			id = fmt.Sprintf("catalog-%d", i)
+		}
+		provider.tracks = append(provider.tracks, &models.SpotifyTrack{
+			ID:         id,
+			Name:       entry.Title,
+			Artist:     entry.Artist,
+			Album:      entry.Album,
+			PreviewURL: entry.PreviewURL,
+			ImageURL:   entry.ImageURL,
+		})
+		provider.genres[id] = entry.Genre
+	}
+
+	if len(provider.tracks) == 0 {
+		return nil, ErrNoTracks
+	}
+
+	log.Printf("[Tracks] Catalogue %s: %d pistes", path, len(provider.tracks))
+	return provider, nil
+}
+
+func (p *CatalogProvider) Name() string {
+	return "catalog"
+}
+
+func (p *CatalogProvider) DisplayName() string {
+	return "Catalogue"
+}
+
+func (p *CatalogProvider) Genres() []Genre {
+	return distinctGenres(p.genres)
+}
+
+func (p *CatalogProvider) GetTracks(genre string, count int) ([]*models.SpotifyTrack, error) {
+	return pickTracks(p.tracks, p.genres, genre, count)
+}
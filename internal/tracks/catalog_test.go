@@ -0,0 +1,80 @@
+package tracks
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeCatalog(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewCatalogProvider(t *testing.T) {
+	path := writeCatalog(t, `[
+		{"id": "bohemian", "title": "Bohemian Rhapsody", "artist": "Queen", "preview_url": "https://cdn.test/1.mp3", "genre": "Rock"},
+		{"title": "Formidable", "artist": "Stromae", "album": "Racine carrée", "preview_url": "https://cdn.test/2.mp3", "genre": "Pop"},
+		{"title": "Sans artiste", "preview_url": "https://cdn.test/3.mp3"},
+		{"title": "Sans extrait", "artist": "Inconnu"}
+	]`)
+
+	provider, err := NewCatalogProvider(path)
+	if err != nil {
+		t.Fatalf("NewCatalogProvider: %v", err)
+	}
+
+	tests := []struct {
+		id     string
+		title  string
+		artist string
+		album  string
+		genre  string
+	}{
+		{"bohemian", "Bohemian Rhapsody", "Queen", "", "Rock"},
+		{"catalog-1", "Formidable", "Stromae", "Racine carrée", "Pop"},
+	}
+
+	if len(provider.tracks) != len(tests) {
+		t.Fatalf("%d pistes, attendu %d", len(provider.tracks), len(tests))
+	}
+	for i, tt := range tests {
+		track := provider.tracks[i]
+		if track.ID != tt.id || track.Name != tt.title || track.Artist != tt.artist || track.Album != tt.album {
+			t.Errorf("piste %d = %+v", i, *track)
+		}
+		if provider.genres[tt.id] != tt.genre {
+			t.Errorf("genre de %s = %q, attendu %q", tt.id, provider.genres[tt.id], tt.genre)
+		}
+	}
+}
+
+func TestNewCatalogProviderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{"fichier absent", filepath.Join(t.TempDir(), "absent.json"), os.ErrNotExist},
+		{"JSON invalide", writeCatalog(t, `{"title": "pas une liste"}`), nil},
+		{"aucune entrée valide", writeCatalog(t, `[{"title": "Seul"}]`), ErrNoTracks},
+		{"liste vide", writeCatalog(t, `[]`), ErrNoTracks},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCatalogProvider(tt.path)
+			if err == nil {
+				t.Fatal("catalogue accepté")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("erreur %v, attendu %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package tracks
+
+import (
+	"errors"
+	"log"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/spotify"
+)
+
+// DeezerProvider interroge l'API publique Deezer à chaque partie. Si Deezer
+// ne fournit pas assez d'extraits, même avec son cache et sa réserve, la
+// source de secours (TRACKS_FALLBACK, à défaut le dossier local ou le
+// catalogue) prend le relais.
+type DeezerProvider struct {
+	client   *spotify.Client
+	fallback TrackProvider
+}
+
+func NewDeezerProvider(client *spotify.Client) *DeezerProvider {
+	return &DeezerProvider{client: client}
+}
+
+func (p *DeezerProvider) SetFallback(fallback TrackProvider) {
+	p.fallback = fallback
+}
+
+func (p *DeezerProvider) Name() string {
+	return "deezer"
+}
+
+func (p *DeezerProvider) DisplayName() string {
+	return "Deezer"
+}
+
+func (p *DeezerProvider) Genres() []Genre {
+	genres := make([]Genre, 0, len(spotify.Genres))
+	for _, g := range spotify.GetAvailableGenres() {
+		genres = append(genres, Genre{ID: g.Slug, Name: g.Name})
+	}
+	return genres
+}
+
+func (p *DeezerProvider) GetTracks(genre string, count int) ([]*models.SpotifyTrack, error) {
+	found, err := p.getTracks(genre, count)
+	if err == nil || errors.Is(err, ErrUnknownGenre) || p.fallback == nil {
+		return found, err
+	}
+
+	// La source de secours ne connaît pas forcément le genre : elle joue
+	// alors toute sa bibliothèque.
+	fallbackGenre := genre
+	if !HasGenre(p.fallback, genre) {
+		fallbackGenre = ""
+	}
+
+	log.Printf("[Tracks] Deezer insuffisant (%v), source de secours: %s", err, p.fallback.Name())
+	tracks, fallbackErr := p.fallback.GetTracks(fallbackGenre, count)
+	if fallbackErr != nil || len(tracks) < count {
+		return nil, err
+	}
+	return tracks, nil
+}
+
+func (p *DeezerProvider) getTracks(genre string, count int) ([]*models.SpotifyTrack, error) {
+	if p.client == nil {
+		return nil, spotify.ErrNoToken
+	}
+
+	found, err := p.client.GetRandomTracksForBlindTest(genre, count)
+	if errors.Is(err, spotify.ErrUnknownGenre) {
+		return nil, ErrUnknownGenre
+	}
+	if errors.Is(err, spotify.ErrNoTracks) {
+		return nil, notEnoughTracks(genre, 0, count)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(found) < count {
+		return nil, notEnoughTracks(genre, len(found), count)
+	}
+	return found, nil
+}
+
+func (p *DeezerProvider) ParsePlaylistID(input string) (string, error) {
+	return spotify.ParsePlaylistID(input)
+}
+
+func (p *DeezerProvider) LookupPlaylist(playlistID string) (*Playlist, error) {
+	if p.client == nil {
+		return nil, spotify.ErrNoToken
+	}
+
+	playlist, err := p.client.GetPlaylist(playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Playlist{
+		ID:       playlist.ID,
+		Title:    playlist.Title,
+		Total:    playlist.TrackCount,
+		Playable: len(playlist.Tracks),
+	}, nil
+}
+
+func (p *DeezerProvider) GetPlaylistTracks(playlistID string) ([]*models.SpotifyTrack, error) {
+	if p.client == nil {
+		return nil, spotify.ErrNoToken
+	}
+
+	playlist, err := p.client.GetPlaylist(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	return playlist.Tracks, nil
+}
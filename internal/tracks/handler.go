@@ -0,0 +1,44 @@
+package tracks
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type Handler struct {
+	registry *Registry
+}
+
+func NewHandler(registry *Registry) *Handler {
+	return &Handler{registry: registry}
+}
+
+// HandleGenres sert GET /api/genres?source=deezer : les genres proposés par
+// une source et si elle accepte une playlist, pour le formulaire de création.
+func (h *Handler) HandleGenres(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+		return
+	}
+
+	provider, err := h.registry.Resolve(r.URL.Query().Get("source"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	_, playlists := provider.(PlaylistProvider)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"source":    provider.Name(),
+		"genres":    provider.Genres(),
+		"playlists": playlists,
+	})
+}
@@ -0,0 +1,134 @@
+package tracks
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"groupie-tracker/internal/models"
+)
+
+// LocalSourcePrefix précède l'identifiant des pistes du dossier local
+// (local:{id}). Ce n'est pas une URL : le fichier n'a pas de route publique et
+// n'est lu que par le proxy d'extraits, derrière le jeton de la manche.
+const LocalSourcePrefix = "local:"
+
+var audioExtensions = map[string]bool{
+	".mp3":  true,
+	".flac": true,
+	".ogg":  true,
+	".m4a":  true,
+	".wav":  true,
+}
+
+// LocalProvider lit un dossier de fichiers audio (TRACKS_DIR) pour jouer
+// sans Internet. Titre, artiste, album et genre viennent des tags ID3 ou
+// FLAC, à défaut du nom "Artiste - Titre.mp3" et du sous-dossier.
+type LocalProvider struct {
+	tracks []*models.SpotifyTrack
+	genres map[string]string
+	paths  map[string]string
+}
+
+func NewLocalProvider(dir string) (*LocalProvider, error) {
+	provider := &LocalProvider{
+		genres: make(map[string]string),
+		paths:  make(map[string]string),
+	}
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !audioExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		provider.add(path, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(provider.tracks) == 0 {
+		return nil, ErrNoTracks
+	}
+
+	log.Printf("[Tracks] Dossier %s: %d pistes", dir, len(provider.tracks))
+	return provider, nil
+}
+
+func (p *LocalProvider) add(path, rel string) {
+	tags := readTags(path)
+
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if tags.Title == "" || tags.Artist == "" {
+		if artist, title, ok := strings.Cut(base, " - "); ok {
+			if tags.Artist == "" {
+				tags.Artist = strings.TrimSpace(artist)
+			}
+			if tags.Title == "" {
+				tags.Title = strings.TrimSpace(title)
+			}
+		}
+	}
+	if tags.Title == "" {
+		tags.Title = base
+	}
+	if tags.Genre == "" {
+		if dir := filepath.Dir(rel); dir != "." {
+			tags.Genre = strings.Split(filepath.ToSlash(dir), "/")[0]
+		}
+	}
+	if tags.Artist == "" {
+		log.Printf("[Tracks] %s ignoré: artiste inconnu", rel)
+		return
+	}
+
+	sum := sha1.Sum([]byte(filepath.ToSlash(rel)))
+	id := hex.EncodeToString(sum[:8])
+
+	p.tracks = append(p.tracks, &models.SpotifyTrack{
+		ID:         id,
+		Name:       tags.Title,
+		Artist:     tags.Artist,
+		Album:      tags.Album,
+		PreviewURL: LocalSourcePrefix + id,
+	})
+	p.genres[id] = tags.Genre
+	p.paths[id] = path
+}
+
+func (p *LocalProvider) Name() string {
+	return "local"
+}
+
+func (p *LocalProvider) DisplayName() string {
+	return "Dossier local"
+}
+
+func (p *LocalProvider) Genres() []Genre {
+	return distinctGenres(p.genres)
+}
+
+func (p *LocalProvider) GetTracks(genre string, count int) ([]*models.SpotifyTrack, error) {
+	return pickTracks(p.tracks, p.genres, genre, count)
+}
+
+// Open ouvre le fichier d'une source local:{id}.
+func (p *LocalProvider) Open(source string) (*os.File, error) {
+	path, exists := p.paths[strings.TrimPrefix(source, LocalSourcePrefix)]
+	if !exists {
+		return nil, os.ErrNotExist
+	}
+	return os.Open(path)
+}
@@ -0,0 +1,88 @@
+package tracks
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"groupie-tracker/internal/models"
+)
+
+func writeTrack(t *testing.T, dir, rel string, data []byte) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewLocalProvider(t *testing.T) {
+	dir := t.TempDir()
+	tagged := id3Tag(3, 0,
+		id3Frame(3, "TIT2", 0, []byte("Bohemian Rhapsody")),
+		id3Frame(3, "TPE1", 0, []byte("Queen")),
+		id3Frame(3, "TCON", 0, []byte("Classic Rock")),
+	)
+	writeTrack(t, dir, "Rock/Queen - Bohemian Rhapsody.mp3", nil)
+	writeTrack(t, dir, "Rock/piste01.mp3", tagged)
+	writeTrack(t, dir, "Pop/Francais/Stromae - Papaoutai.FLAC", nil)
+	writeTrack(t, dir, "Daft Punk - Get Lucky.ogg", nil)
+	writeTrack(t, dir, "sans artiste.mp3", nil)
+	writeTrack(t, dir, "Queen - Pochette.jpg", nil)
+
+	provider, err := NewLocalProvider(dir)
+	if err != nil {
+		t.Fatalf("NewLocalProvider: %v", err)
+	}
+
+	tests := []struct {
+		rel    string
+		title  string
+		artist string
+		genre  string
+	}{
+		{"Rock/Queen - Bohemian Rhapsody.mp3", "Bohemian Rhapsody", "Queen", "Rock"},
+		{"Rock/piste01.mp3", "Bohemian Rhapsody", "Queen", "Classic Rock"},
+		{"Pop/Francais/Stromae - Papaoutai.FLAC", "Papaoutai", "Stromae", "Pop"},
+		{"Daft Punk - Get Lucky.ogg", "Get Lucky", "Daft Punk", ""},
+	}
+
+	byPath := make(map[string]*models.SpotifyTrack)
+	for _, track := range provider.tracks {
+		byPath[provider.paths[track.ID]] = track
+	}
+	if len(byPath) != len(tests) {
+		t.Fatalf("%d pistes, attendu %d", len(byPath), len(tests))
+	}
+	for _, tt := range tests {
+		track := byPath[filepath.Join(dir, filepath.FromSlash(tt.rel))]
+		if track == nil {
+			t.Errorf("%s absent du dossier chargé", tt.rel)
+			continue
+		}
+		if track.Name != tt.title || track.Artist != tt.artist || provider.genres[track.ID] != tt.genre {
+			t.Errorf("%s: %q / %q / %q, attendu %q / %q / %q", tt.rel,
+				track.Name, track.Artist, provider.genres[track.ID], tt.title, tt.artist, tt.genre)
+		}
+		if track.PreviewURL != LocalSourcePrefix+track.ID {
+			t.Errorf("%s: preview %q", tt.rel, track.PreviewURL)
+		}
+	}
+}
+
+func TestNewLocalProviderEmpty(t *testing.T) {
+	dir := t.TempDir()
+	writeTrack(t, dir, "notes.txt", []byte("pas de musique"))
+	writeTrack(t, dir, "sans artiste.mp3", nil)
+
+	if _, err := NewLocalProvider(dir); !errors.Is(err, ErrNoTracks) {
+		t.Errorf("dossier sans piste: %v, attendu %v", err, ErrNoTracks)
+	}
+	if _, err := NewLocalProvider(filepath.Join(dir, "absent")); err == nil {
+		t.Error("dossier absent accepté")
+	}
+}
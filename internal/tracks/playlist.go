@@ -0,0 +1,101 @@
+package tracks
+
+import (
+	"errors"
+	"fmt"
+
+	"groupie-tracker/internal/models"
+)
+
+var ErrPlaylistUnsupported = errors.New("cette source ne gère pas les playlists")
+
+// MinPlaylistTracks est le nombre minimum d'extraits jouables pour accepter
+// une playlist à la création de salle.
+const MinPlaylistTracks = 5
+
+// Playlist résume une playlist validée : Playable compte les pistes qui ont
+// un extrait, Total toutes les pistes.
+type Playlist struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Total    int    `json:"total"`
+	Playable int    `json:"playable"`
+}
+
+// PlaylistProvider est implémenté par les sources capables de lire une
+// playlist choisie par l'hôte. ParsePlaylistID vérifie localement un lien ou
+// un identifiant et renvoie l'identifiant normalisé ; les deux autres
+// méthodes appellent l'API avec cet identifiant.
+type PlaylistProvider interface {
+	TrackProvider
+	ParsePlaylistID(input string) (string, error)
+	LookupPlaylist(playlistID string) (*Playlist, error)
+	GetPlaylistTracks(playlistID string) ([]*models.SpotifyTrack, error)
+}
+
+// ParsePlaylist vérifie le format d'un lien ou d'un identifiant de playlist
+// sans appeler l'API.
+func ParsePlaylist(provider TrackProvider, input string) (string, error) {
+	source, ok := provider.(PlaylistProvider)
+	if !ok {
+		return "", ErrPlaylistUnsupported
+	}
+	return source.ParsePlaylistID(input)
+}
+
+// ValidatePlaylist lit une playlist quand l'hôte la choisit et vérifie
+// qu'elle a assez d'extraits.
+func ValidatePlaylist(provider TrackProvider, input string) (*Playlist, error) {
+	playlistID, err := ParsePlaylist(provider, input)
+	if err != nil {
+		return nil, err
+	}
+
+	playlist, err := provider.(PlaylistProvider).LookupPlaylist(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	if playlist.Playable < MinPlaylistTracks {
+		return nil, fmt.Errorf("%w dans la playlist %s (%d/%d)", ErrNotEnoughTracks, playlist.Title, playlist.Playable, MinPlaylistTracks)
+	}
+	return playlist, nil
+}
+
+// FromPlaylist lit les pistes de la playlist une seule fois, au lancement de
+// la partie, et les adapte en TrackProvider : les manches et les leurres sont
+// tirés de cette copie sans autre appel à l'API.
+func FromPlaylist(provider TrackProvider, playlistID string) (TrackProvider, error) {
+	source, ok := provider.(PlaylistProvider)
+	if !ok {
+		return nil, ErrPlaylistUnsupported
+	}
+	tracks, err := source.GetPlaylistTracks(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	return &playlistProvider{source: source, playlistID: playlistID, tracks: tracks}, nil
+}
+
+type playlistProvider struct {
+	source     PlaylistProvider
+	playlistID string
+	tracks     []*models.SpotifyTrack
+}
+
+func (p *playlistProvider) Name() string {
+	return p.source.Name()
+}
+
+func (p *playlistProvider) DisplayName() string {
+	return p.source.DisplayName() + " (playlist " + p.playlistID + ")"
+}
+
+func (p *playlistProvider) Genres() []Genre {
+	return nil
+}
+
+// GetTracks ignore le genre : une playlist plus courte que la partie la
+// raccourcit au lieu de la refuser.
+func (p *playlistProvider) GetTracks(genre string, count int) ([]*models.SpotifyTrack, error) {
+	return pickTracks(p.tracks, nil, "", count)
+}
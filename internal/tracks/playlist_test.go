@@ -0,0 +1,93 @@
+package tracks
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"groupie-tracker/internal/models"
+)
+
+var errBadPlaylistID = errors.New("identifiant de playlist invalide")
+
+var testPlaylistID = regexp.MustCompile(`^[0-9]+$`)
+
+// fakePlaylists compte les appels à l'API pour vérifier qu'une playlist
+// n'est lue qu'une fois.
+type fakePlaylists struct {
+	tracks  []*models.SpotifyTrack
+	lookups int
+	fetches int
+}
+
+func (f *fakePlaylists) Name() string        { return "fake" }
+func (f *fakePlaylists) DisplayName() string { return "Fake" }
+func (f *fakePlaylists) Genres() []Genre     { return nil }
+func (f *fakePlaylists) GetTracks(string, int) ([]*models.SpotifyTrack, error) {
+	return nil, ErrNoTracks
+}
+
+func (f *fakePlaylists) ParsePlaylistID(input string) (string, error) {
+	if !testPlaylistID.MatchString(input) {
+		return "", errBadPlaylistID
+	}
+	return input, nil
+}
+
+func (f *fakePlaylists) LookupPlaylist(playlistID string) (*Playlist, error) {
+	f.lookups++
+	return &Playlist{ID: playlistID, Title: "Playlist " + playlistID, Total: len(f.tracks), Playable: len(f.tracks)}, nil
+}
+
+func (f *fakePlaylists) GetPlaylistTracks(playlistID string) ([]*models.SpotifyTrack, error) {
+	f.fetches++
+	return f.tracks, nil
+}
+
+func TestValidatePlaylist(t *testing.T) {
+	all, _ := testLibrary()
+
+	tests := []struct {
+		name     string
+		provider TrackProvider
+		input    string
+		wantErr  error
+		lookups  int
+	}{
+		{"playlist jouable", &fakePlaylists{tracks: all}, "42", nil, 1},
+		{"format refusé sans appel", &fakePlaylists{tracks: all}, "pas-un-lien", errBadPlaylistID, 0},
+		{"trop peu d'extraits", &fakePlaylists{tracks: all[:2]}, "42", ErrNotEnoughTracks, 1},
+		{"source sans playlists", &CatalogProvider{}, "42", ErrPlaylistUnsupported, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidatePlaylist(tt.provider, tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValidatePlaylist = %v, attendu %v", err, tt.wantErr)
+			}
+			if fake, ok := tt.provider.(*fakePlaylists); ok && fake.lookups != tt.lookups {
+				t.Errorf("%d lecture(s) de la playlist, attendu %d", fake.lookups, tt.lookups)
+			}
+		})
+	}
+}
+
+func TestFromPlaylistFetchesOnce(t *testing.T) {
+	all, _ := testLibrary()
+	source := &fakePlaylists{tracks: all}
+
+	provider, err := FromPlaylist(source, "42")
+	if err != nil {
+		t.Fatalf("FromPlaylist: %v", err)
+	}
+	// Manches puis réservoir de leurres, comme au lancement d'une partie.
+	for _, count := range []int{3, 9} {
+		if _, err := provider.GetTracks("Rock", count); err != nil {
+			t.Fatalf("GetTracks(%d): %v", count, err)
+		}
+	}
+	if source.fetches != 1 {
+		t.Errorf("%d téléchargement(s) de la playlist, attendu 1", source.fetches)
+	}
+}
@@ -0,0 +1,230 @@
+package tracks
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync"
+
+	"groupie-tracker/internal/models"
+)
+
+var (
+	ErrUnknownProvider = errors.New("source de musique inconnue")
+	ErrNoTracks        = errors.New("aucune piste disponible")
+	ErrUnknownGenre    = errors.New("genre indisponible pour cette source")
+	ErrNotEnoughTracks = errors.New("pas assez d'extraits")
+)
+
+// DefaultProvider est la source utilisée quand ni le serveur ni la salle
+// n'en choisissent une autre.
+const DefaultProvider = "deezer"
+
+// TrackProvider fournit les pistes d'une partie de Blind Test. Le jeu ne
+// connaît que cette interface : Deezer, un dossier local ou un catalogue JSON
+// renvoient tous des pistes avec un PreviewURL lisible par le navigateur.
+// GetTracks limite la sélection au genre demandé (genre vide : tout) et
+// renvoie ErrNotEnoughTracks si ce genre ne fournit pas count extraits.
+type TrackProvider interface {
+	Name() string
+	DisplayName() string
+	Genres() []Genre
+	GetTracks(genre string, count int) ([]*models.SpotifyTrack, error)
+}
+
+// Genre est un choix proposé par le formulaire de création de salle.
+type Genre struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func notEnoughTracks(genre string, found, count int) error {
+	if genre == "" {
+		genre = "tous genres"
+	}
+	return fmt.Errorf("%w pour %s (%d/%d)", ErrNotEnoughTracks, genre, found, count)
+}
+
+// HasGenre indique si la source propose ce genre (vide : toujours vrai).
+func HasGenre(provider TrackProvider, genre string) bool {
+	if genre == "" {
+		return true
+	}
+	for _, g := range provider.Genres() {
+		if strings.EqualFold(g.ID, genre) || strings.EqualFold(g.Name, genre) {
+			return true
+		}
+	}
+	return false
+}
+
+type Registry struct {
+	providers   map[string]TrackProvider
+	order       []string
+	defaultName string
+	mutex       sync.RWMutex
+}
+
+var (
+	registryInstance *Registry
+	registryOnce     sync.Once
+)
+
+func GetRegistry() *Registry {
+	registryOnce.Do(func() {
+		registryInstance = &Registry{
+			providers:   make(map[string]TrackProvider),
+			defaultName: DefaultProvider,
+		}
+	})
+	return registryInstance
+}
+
+func (r *Registry) Register(provider TrackProvider) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	name := provider.Name()
+	if _, exists := r.providers[name]; !exists {
+		r.order = append(r.order, name)
+	}
+	r.providers[name] = provider
+
+	log.Printf("[Tracks] Source enregistrée: %s", name)
+}
+
+func (r *Registry) Get(name string) (TrackProvider, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	provider, exists := r.providers[name]
+	if !exists {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+// SetDefault choisit la source du serveur (variable TRACK_PROVIDER).
+func (r *Registry) SetDefault(name string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.providers[name]; !exists {
+		return ErrUnknownProvider
+	}
+	r.defaultName = name
+	return nil
+}
+
+func (r *Registry) DefaultName() string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.defaultName
+}
+
+// Resolve renvoie la source choisie par la salle, ou celle du serveur si la
+// salle n'en précise pas.
+func (r *Registry) Resolve(name string) (TrackProvider, error) {
+	if name == "" {
+		name = r.DefaultName()
+	}
+	return r.Get(name)
+}
+
+// offlineSources sont essayées dans l'ordre quand aucune source de secours
+// n'est nommée : elles ne dépendent pas du réseau.
+var offlineSources = []string{"local", "catalog"}
+
+// Fallback renvoie la source de secours de primary : celle nommée par
+// TRACKS_FALLBACK, sinon la première source hors ligne enregistrée. Une
+// source n'est jamais son propre secours.
+func (r *Registry) Fallback(name string, primary TrackProvider) (TrackProvider, error) {
+	if name != "" {
+		provider, err := r.Get(name)
+		if err != nil || provider == primary {
+			return nil, ErrUnknownProvider
+		}
+		return provider, nil
+	}
+
+	for _, candidate := range offlineSources {
+		if provider, err := r.Get(candidate); err == nil && provider != primary {
+			return provider, nil
+		}
+	}
+	return nil, ErrNoTracks
+}
+
+func (r *Registry) All() []TrackProvider {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	providers := make([]TrackProvider, 0, len(r.order))
+	for _, name := range r.order {
+		providers = append(providers, r.providers[name])
+	}
+	return providers
+}
+
+// pickTracks filtre par genre, retire les doublons et renvoie count pistes
+// au hasard. Sans genre, une bibliothèque trop petite raccourcit la partie
+// au lieu de la refuser.
+func pickTracks(all []*models.SpotifyTrack, genres map[string]string, genre string, count int) ([]*models.SpotifyTrack, error) {
+	candidates := all
+	if genre != "" {
+		candidates = make([]*models.SpotifyTrack, 0, len(all))
+		for _, track := range all {
+			if strings.EqualFold(genres[track.ID], genre) {
+				candidates = append(candidates, track)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	unique := make([]*models.SpotifyTrack, 0, len(candidates))
+	for _, track := range candidates {
+		key := strings.ToLower(track.Name + track.Artist)
+		if !seen[key] {
+			seen[key] = true
+			copied := *track
+			unique = append(unique, &copied)
+		}
+	}
+
+	if genre != "" && len(unique) < count {
+		return nil, notEnoughTracks(genre, len(unique), count)
+	}
+	if len(unique) == 0 {
+		return nil, ErrNoTracks
+	}
+
+	rand.Shuffle(len(unique), func(i, j int) {
+		unique[i], unique[j] = unique[j], unique[i]
+	})
+
+	if count > len(unique) {
+		count = len(unique)
+	}
+	return unique[:count], nil
+}
+
+// distinctGenres liste les genres présents dans une bibliothèque, triés.
+func distinctGenres(genres map[string]string) []Genre {
+	seen := make(map[string]bool)
+	list := make([]Genre, 0)
+	for _, name := range genres {
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		list = append(list, Genre{ID: name, Name: name})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name)
+	})
+	return list
+}
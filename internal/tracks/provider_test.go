@@ -0,0 +1,214 @@
+package tracks
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"groupie-tracker/internal/models"
+)
+
+func libraryTrack(id, title, artist string) *models.SpotifyTrack {
+	return &models.SpotifyTrack{ID: id, Name: title, Artist: artist, PreviewURL: "https://cdn.test/" + id + ".mp3"}
+}
+
+func testLibrary() ([]*models.SpotifyTrack, map[string]string) {
+	all := []*models.SpotifyTrack{
+		libraryTrack("1", "Bohemian Rhapsody", "Queen"),
+		libraryTrack("2", "Back in Black", "AC/DC"),
+		libraryTrack("3", "Bohemian Rhapsody", "Queen"),
+		libraryTrack("4", "Formidable", "Stromae"),
+		libraryTrack("5", "Papaoutai", "Stromae"),
+		libraryTrack("6", "Get Lucky", "Daft Punk"),
+	}
+	genres := map[string]string{"1": "Rock", "2": "rock", "3": "Rock", "4": "Pop", "5": "Pop", "6": ""}
+	return all, genres
+}
+
+func TestPickTracks(t *testing.T) {
+	all, genres := testLibrary()
+
+	tests := []struct {
+		name      string
+		genre     string
+		count     int
+		wantCount int
+		wantErr   error
+		allowed   map[string]bool
+	}{
+		{"genre sans tenir compte de la casse", "ROCK", 2, 2, nil, map[string]bool{"1": true, "2": true, "3": true}},
+		{"doublons retirés avant le compte", "rock", 3, 0, ErrNotEnoughTracks, nil},
+		{"genre absent", "Jazz", 1, 0, ErrNotEnoughTracks, nil},
+		{"sans genre, partie raccourcie", "", 10, 5, nil, nil},
+		{"sans genre, tirage partiel", "", 2, 2, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			picked, err := pickTracks(all, genres, tt.genre, tt.count)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("pickTracks = %v, attendu %v", err, tt.wantErr)
+			}
+			if len(picked) != tt.wantCount {
+				t.Fatalf("%d pistes, attendu %d", len(picked), tt.wantCount)
+			}
+			seen := make(map[string]bool)
+			for _, track := range picked {
+				if tt.allowed != nil && !tt.allowed[track.ID] {
+					t.Errorf("piste %s hors du genre %s", track.ID, tt.genre)
+				}
+				key := track.Name + track.Artist
+				if seen[key] {
+					t.Errorf("piste %s tirée deux fois", key)
+				}
+				seen[key] = true
+			}
+		})
+	}
+
+	if _, err := pickTracks(nil, nil, "", 5); !errors.Is(err, ErrNoTracks) {
+		t.Errorf("bibliothèque vide: %v, attendu %v", err, ErrNoTracks)
+	}
+
+	picked, _ := pickTracks(all, genres, "Pop", 2)
+	picked[0].Name = "modifié"
+	for _, track := range all {
+		if track.Name == "modifié" {
+			t.Error("pickTracks renvoie les pistes de la bibliothèque au lieu de copies")
+		}
+	}
+}
+
+func TestDistinctGenres(t *testing.T) {
+	_, genres := testLibrary()
+
+	got := distinctGenres(genres)
+	if len(got) != 2 || got[0].Name != "Pop" || !HasGenre(genreList(got), "rock") {
+		t.Errorf("distinctGenres = %v", got)
+	}
+}
+
+// genreList est une source sans piste qui ne propose que des genres.
+type genreList []Genre
+
+func (g genreList) Name() string                                          { return "genres" }
+func (g genreList) DisplayName() string                                   { return "Genres" }
+func (g genreList) Genres() []Genre                                       { return g }
+func (g genreList) GetTracks(string, int) ([]*models.SpotifyTrack, error) { return nil, ErrNoTracks }
+
+func TestHasGenre(t *testing.T) {
+	provider := genreList{{ID: "rap", Name: "Rap/Hip Hop"}, {ID: "chanson", Name: "Chanson française"}}
+
+	tests := []struct {
+		genre string
+		want  bool
+	}{
+		{"", true},
+		{"rap", true},
+		{"RAP", true},
+		{"Chanson française", true},
+		{"chanson FRANÇAISE", true},
+		{"jazz", false},
+	}
+
+	for _, tt := range tests {
+		if got := HasGenre(provider, tt.genre); got != tt.want {
+			t.Errorf("HasGenre(%q) = %v, attendu %v", tt.genre, got, tt.want)
+		}
+	}
+}
+
+func TestHandleGenres(t *testing.T) {
+	registry := &Registry{
+		providers:   map[string]TrackProvider{"genres": genreList{{ID: "rock", Name: "Rock"}}},
+		order:       []string{"genres"},
+		defaultName: "genres",
+	}
+	handler := NewHandler(registry)
+
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantGenres int
+	}{
+		{"", http.StatusOK, 1},
+		{"?source=genres", http.StatusOK, 1},
+		{"?source=inconnue", http.StatusNotFound, 0},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.HandleGenres(rec, httptest.NewRequest(http.MethodGet, "/api/genres"+tt.query, nil))
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%q: statut %d, attendu %d", tt.query, rec.Code, tt.wantStatus)
+			continue
+		}
+		var body struct {
+			Genres []Genre `json:"genres"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || len(body.Genres) != tt.wantGenres {
+			t.Errorf("%q: genres %v, %v", tt.query, body.Genres, err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler.HandleGenres(rec, httptest.NewRequest(http.MethodPost, "/api/genres", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: statut %d", rec.Code)
+	}
+}
+
+func TestRegistryFallback(t *testing.T) {
+	deezer := NewDeezerProvider(nil)
+	local := &LocalProvider{}
+	catalog := &CatalogProvider{}
+
+	tests := []struct {
+		name      string
+		providers []TrackProvider
+		fallback  string
+		want      TrackProvider
+		wantErr   error
+	}{
+		{"dossier local par défaut", []TrackProvider{deezer, catalog, local}, "", local, nil},
+		{"catalogue sans dossier local", []TrackProvider{deezer, catalog}, "", catalog, nil},
+		{"aucune source hors ligne", []TrackProvider{deezer}, "", nil, ErrNoTracks},
+		{"source nommée", []TrackProvider{deezer, catalog, local}, "catalog", catalog, nil},
+		{"source nommée absente", []TrackProvider{deezer, local}, "catalog", nil, ErrUnknownProvider},
+		{"secours de soi-même", []TrackProvider{deezer, local}, "deezer", nil, ErrUnknownProvider},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := &Registry{providers: make(map[string]TrackProvider)}
+			for _, provider := range tt.providers {
+				registry.Register(provider)
+			}
+			got, err := registry.Fallback(tt.fallback, deezer)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("Fallback = %v, %v, attendu %v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDeezerFallbackOffline(t *testing.T) {
+	all, genres := testLibrary()
+	deezer := NewDeezerProvider(nil)
+	deezer.SetFallback(&LocalProvider{tracks: all, genres: genres})
+
+	// Sans client Deezer joignable, la partie tire ses pistes du secours,
+	// dans le genre demandé s'il le connaît.
+	found, err := deezer.GetTracks("rock", 2)
+	if err != nil || len(found) != 2 {
+		t.Fatalf("GetTracks = %d pistes, %v", len(found), err)
+	}
+	for _, track := range found {
+		if !strings.EqualFold(genres[track.ID], "rock") {
+			t.Errorf("piste %s hors du genre rock", track.ID)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package tracks
+
+import (
+	"errors"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/spotify"
+)
+
+// SpotifyProvider interroge l'API Web Spotify avec les identifiants
+// SPOTIFY_CLIENT_ID / SPOTIFY_CLIENT_SECRET. Elle n'est enregistrée que si
+// ces identifiants sont configurés.
+type SpotifyProvider struct {
+	client *spotify.WebAPIClient
+}
+
+func NewSpotifyProvider(client *spotify.WebAPIClient) *SpotifyProvider {
+	return &SpotifyProvider{client: client}
+}
+
+func (p *SpotifyProvider) Name() string {
+	return "spotify"
+}
+
+func (p *SpotifyProvider) DisplayName() string {
+	return "Spotify"
+}
+
+func (p *SpotifyProvider) Genres() []Genre {
+	available := spotify.SpotifyGenres()
+	genres := make([]Genre, 0, len(available))
+	for _, g := range available {
+		genres = append(genres, Genre{ID: g.Slug, Name: g.Name})
+	}
+	return genres
+}
+
+func (p *SpotifyProvider) GetTracks(genre string, count int) ([]*models.SpotifyTrack, error) {
+	found, err := p.client.GetRandomTracksForBlindTest(genre, count)
+	if errors.Is(err, spotify.ErrUnknownGenre) {
+		return nil, ErrUnknownGenre
+	}
+	if errors.Is(err, spotify.ErrNoTracks) {
+		return nil, notEnoughTracks(genre, 0, count)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(found) < count {
+		return nil, notEnoughTracks(genre, len(found), count)
+	}
+	return found, nil
+}
+
+func (p *SpotifyProvider) ParsePlaylistID(input string) (string, error) {
+	return spotify.ParseSpotifyPlaylistID(input)
+}
+
+func (p *SpotifyProvider) LookupPlaylist(playlistID string) (*Playlist, error) {
+	playlist, err := p.client.GetPlaylist(playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Playlist{
+		ID:       playlist.ID,
+		Title:    playlist.Title,
+		Total:    playlist.TrackCount,
+		Playable: len(playlist.Tracks),
+	}, nil
+}
+
+func (p *SpotifyProvider) GetPlaylistTracks(playlistID string) ([]*models.SpotifyTrack, error) {
+	playlist, err := p.client.GetPlaylist(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	return playlist.Tracks, nil
+}
@@ -0,0 +1,271 @@
+package tracks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf16"
+)
+
+type trackTags struct {
+	Title  string
+	Artist string
+	Album  string
+	Genre  string
+}
+
+// readTags lit les tags ID3v2, ID3v1 (fin de fichier) ou les commentaires
+// Vorbis d'un FLAC. Un fichier sans tags lisibles renvoie des champs vides.
+func readTags(path string) trackTags {
+	var tags trackTags
+
+	file, err := os.Open(path)
+	if err != nil {
+		return tags
+	}
+	defer file.Close()
+
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return tags
+	}
+
+	switch {
+	case bytes.Equal(header[:3], []byte("ID3")):
+		tags = readID3v2(file, header)
+	case bytes.Equal(header[:4], []byte("fLaC")):
+		if _, err := file.Seek(4, io.SeekStart); err == nil {
+			tags = readFLAC(file)
+		}
+	}
+
+	if tags.Title == "" || tags.Artist == "" {
+		fallback := readID3v1(file)
+		tags.fill(fallback)
+	}
+
+	return tags
+}
+
+func (t *trackTags) fill(other trackTags) {
+	if t.Title == "" {
+		t.Title = other.Title
+	}
+	if t.Artist == "" {
+		t.Artist = other.Artist
+	}
+	if t.Album == "" {
+		t.Album = other.Album
+	}
+	if t.Genre == "" {
+		t.Genre = other.Genre
+	}
+}
+
+func (t *trackTags) set(key, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	switch key {
+	case "TIT2", "TT2", "TITLE":
+		t.Title = value
+	case "TPE1", "TP1", "ARTIST":
+		t.Artist = value
+	case "TALB", "TAL", "ALBUM":
+		t.Album = value
+	case "TCON", "TCO", "GENRE":
+		t.Genre = cleanGenre(value)
+	}
+}
+
+// cleanGenre retire les références numériques ID3 du type "(17)Rock".
+func cleanGenre(value string) string {
+	for strings.HasPrefix(value, "(") {
+		end := strings.Index(value, ")")
+		if end < 0 {
+			break
+		}
+		value = value[end+1:]
+	}
+	return strings.TrimSpace(value)
+}
+
+func syncsafe(b []byte) int {
+	return int(b[0])<<21 | int(b[1])<<14 | int(b[2])<<7 | int(b[3])
+}
+
+func readID3v2(r io.Reader, header []byte) trackTags {
+	var tags trackTags
+
+	version := header[3]
+	flags := header[5]
+	size := syncsafe(header[6:10])
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return tags
+	}
+
+	pos := 0
+	if flags&0x40 != 0 && len(data) >= 4 {
+		if version == 4 {
+			pos = syncsafe(data[:4])
+		} else {
+			pos = int(binary.BigEndian.Uint32(data[:4])) + 4
+		}
+	}
+
+	idLen, headerLen := 4, 10
+	if version == 2 {
+		idLen, headerLen = 3, 6
+	}
+
+	for pos+headerLen <= len(data) {
+		id := string(data[pos : pos+idLen])
+		if id[0] == 0 {
+			break
+		}
+
+		var frameSize int
+		switch version {
+		case 2:
+			frameSize = int(data[pos+3])<<16 | int(data[pos+4])<<8 | int(data[pos+5])
+		case 4:
+			frameSize = syncsafe(data[pos+4 : pos+8])
+		default:
+			frameSize = int(binary.BigEndian.Uint32(data[pos+4 : pos+8]))
+		}
+
+		pos += headerLen
+		if frameSize <= 0 || pos+frameSize > len(data) {
+			break
+		}
+		if id[0] == 'T' {
+			tags.set(id, decodeID3Text(data[pos:pos+frameSize]))
+		}
+		pos += frameSize
+	}
+
+	return tags
+}
+
+func decodeID3Text(frame []byte) string {
+	if len(frame) < 2 {
+		return ""
+	}
+
+	encoding, text := frame[0], frame[1:]
+	switch encoding {
+	case 1, 2:
+		bigEndian := encoding == 2
+		if len(text) >= 2 && text[0] == 0xFF && text[1] == 0xFE {
+			bigEndian, text = false, text[2:]
+		} else if len(text) >= 2 && text[0] == 0xFE && text[1] == 0xFF {
+			bigEndian, text = true, text[2:]
+		}
+		units := make([]uint16, 0, len(text)/2)
+		for i := 0; i+1 < len(text); i += 2 {
+			if bigEndian {
+				units = append(units, uint16(text[i])<<8|uint16(text[i+1]))
+			} else {
+				units = append(units, uint16(text[i+1])<<8|uint16(text[i]))
+			}
+		}
+		return strings.TrimRight(string(utf16.Decode(units)), "\x00")
+	case 3:
+		return strings.TrimRight(string(text), "\x00")
+	default:
+		return latin1(text)
+	}
+}
+
+func latin1(b []byte) string {
+	b = bytes.TrimRight(b, "\x00 ")
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+func readID3v1(r io.ReadSeeker) trackTags {
+	var tags trackTags
+
+	if _, err := r.Seek(-128, io.SeekEnd); err != nil {
+		return tags
+	}
+	block := make([]byte, 128)
+	if _, err := io.ReadFull(r, block); err != nil || !bytes.Equal(block[:3], []byte("TAG")) {
+		return tags
+	}
+
+	tags.Title = strings.TrimSpace(latin1(block[3:33]))
+	tags.Artist = strings.TrimSpace(latin1(block[33:63]))
+	tags.Album = strings.TrimSpace(latin1(block[63:93]))
+	return tags
+}
+
+// readFLAC parcourt les blocs de métadonnées jusqu'au VORBIS_COMMENT (type 4).
+func readFLAC(r io.Reader) trackTags {
+	var tags trackTags
+
+	blockHeader := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, blockHeader); err != nil {
+			return tags
+		}
+		last := blockHeader[0]&0x80 != 0
+		blockType := blockHeader[0] & 0x7F
+		length := int(blockHeader[1])<<16 | int(blockHeader[2])<<8 | int(blockHeader[3])
+
+		block := make([]byte, length)
+		if _, err := io.ReadFull(r, block); err != nil {
+			return tags
+		}
+		if blockType == 4 {
+			return parseVorbisComments(block)
+		}
+		if last {
+			return tags
+		}
+	}
+}
+
+func parseVorbisComments(block []byte) trackTags {
+	var tags trackTags
+
+	readLen := func(pos int) (int, bool) {
+		if pos+4 > len(block) {
+			return 0, false
+		}
+		return int(binary.LittleEndian.Uint32(block[pos : pos+4])), true
+	}
+
+	vendorLen, ok := readLen(0)
+	if !ok {
+		return tags
+	}
+	pos := 4 + vendorLen
+	count, ok := readLen(pos)
+	if !ok {
+		return tags
+	}
+	pos += 4
+
+	for i := 0; i < count; i++ {
+		length, ok := readLen(pos)
+		if !ok || pos+4+length > len(block) {
+			return tags
+		}
+		comment := string(block[pos+4 : pos+4+length])
+		pos += 4 + length
+
+		if key, value, found := strings.Cut(comment, "="); found {
+			tags.set(strings.ToUpper(key), value)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,152 @@
+package tracks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func syncsafeBytes(n int) []byte {
+	return []byte{byte(n >> 21 & 0x7F), byte(n >> 14 & 0x7F), byte(n >> 7 & 0x7F), byte(n & 0x7F)}
+}
+
+// id3Frame encode un cadre de texte pour la version majeure donnée.
+func id3Frame(version byte, id string, encoding byte, text []byte) []byte {
+	payload := append([]byte{encoding}, text...)
+	var frame []byte
+	switch version {
+	case 2:
+		n := len(payload)
+		frame = append([]byte(id), byte(n>>16), byte(n>>8), byte(n))
+	case 4:
+		frame = append(append([]byte(id), syncsafeBytes(len(payload))...), 0, 0)
+	default:
+		frame = binary.BigEndian.AppendUint32([]byte(id), uint32(len(payload)))
+		frame = append(frame, 0, 0)
+	}
+	return append(frame, payload...)
+}
+
+func id3Tag(version, flags byte, body ...[]byte) []byte {
+	data := bytes.Join(body, nil)
+	tag := append([]byte{'I', 'D', '3', version, 0, flags}, syncsafeBytes(len(data))...)
+	return append(tag, data...)
+}
+
+func id3v1Tag(title, artist, album string) []byte {
+	block := make([]byte, 128)
+	copy(block, "TAG")
+	copy(block[3:33], title)
+	copy(block[33:63], artist)
+	copy(block[63:93], album)
+	return block
+}
+
+func flacFile(comments ...string) []byte {
+	data := []byte("fLaC")
+	// STREAMINFO (type 0) avant les commentaires, comme dans un vrai fichier.
+	data = append(data, 0, 0, 0, 34)
+	data = append(data, make([]byte, 34)...)
+
+	vorbis := binary.LittleEndian.AppendUint32(nil, 6)
+	vorbis = append(vorbis, "vendor"...)
+	vorbis = binary.LittleEndian.AppendUint32(vorbis, uint32(len(comments)))
+	for _, comment := range comments {
+		vorbis = binary.LittleEndian.AppendUint32(vorbis, uint32(len(comment)))
+		vorbis = append(vorbis, comment...)
+	}
+	n := len(vorbis)
+	data = append(data, 0x80|4, byte(n>>16), byte(n>>8), byte(n))
+	return append(data, vorbis...)
+}
+
+func utf16LE(s string) []byte {
+	out := []byte{0xFF, 0xFE}
+	for _, r := range s {
+		out = append(out, byte(r), byte(r>>8))
+	}
+	return out
+}
+
+func TestReadTags(t *testing.T) {
+	audio := make([]byte, 64)
+
+	tests := []struct {
+		name string
+		data []byte
+		want trackTags
+	}{
+		{"ID3v2.3 latin-1", id3Tag(3, 0,
+			id3Frame(3, "TIT2", 0, []byte("Caf\xe9 de Flore")),
+			id3Frame(3, "TPE1", 0, []byte("Doriand")),
+			id3Frame(3, "TALB", 0, []byte("Contact")),
+			id3Frame(3, "TCON", 0, []byte("(17)Rock")),
+			audio,
+		), trackTags{Title: "Café de Flore", Artist: "Doriand", Album: "Contact", Genre: "Rock"}},
+		{"ID3v2.4 UTF-8 et tailles syncsafe", id3Tag(4, 0,
+			id3Frame(4, "TIT2", 3, []byte("Ça plane pour moi\x00")),
+			id3Frame(4, "TPE1", 3, bytes.Repeat([]byte("é"), 100)),
+		), trackTags{Title: "Ça plane pour moi", Artist: string(bytes.Repeat([]byte("é"), 100))}},
+		{"ID3v2.2 identifiants courts", id3Tag(2, 0,
+			id3Frame(2, "TT2", 0, []byte("Alors on danse")),
+			id3Frame(2, "TP1", 0, []byte("Stromae")),
+			id3Frame(2, "TCO", 0, []byte("Pop")),
+		), trackTags{Title: "Alors on danse", Artist: "Stromae", Genre: "Pop"}},
+		{"ID3v2.3 UTF-16 avec BOM", id3Tag(3, 0,
+			id3Frame(3, "TIT2", 1, append(utf16LE("Déjà vu"), 0, 0)),
+			id3Frame(3, "TPE1", 1, utf16LE("Beyoncé")),
+		), trackTags{Title: "Déjà vu", Artist: "Beyoncé"}},
+		{"ID3v2.3 en-tête étendu", id3Tag(3, 0x40,
+			[]byte{0, 0, 0, 6, 0, 0, 0, 0, 0, 0},
+			id3Frame(3, "TIT2", 0, []byte("Titre")),
+			id3Frame(3, "TPE1", 0, []byte("Artiste")),
+		), trackTags{Title: "Titre", Artist: "Artiste"}},
+		{"cadre tronqué", id3Tag(3, 0,
+			id3Frame(3, "TIT2", 0, []byte("Titre")),
+			[]byte("TPE1\x00\x00\x10\x00\x00\x00Art"),
+		), trackTags{Title: "Titre"}},
+		{"ID3v2 complété par ID3v1", append(id3Tag(3, 0,
+			id3Frame(3, "TIT2", 0, []byte("Titre v2")),
+			audio,
+		), id3v1Tag("Titre v1", "Artiste v1", "Album v1")...), trackTags{Title: "Titre v2", Artist: "Artiste v1", Album: "Album v1"}},
+		{"ID3v1 seul", append(append([]byte{}, audio...), id3v1Tag("Hier encore", "Charles Aznavour", "")...),
+			trackTags{Title: "Hier encore", Artist: "Charles Aznavour"}},
+		{"FLAC", flacFile("TITLE=Formidable", "artist=Stromae", "ALBUM=Racine carrée", "GENRE=Pop", "COMMENT"),
+			trackTags{Title: "Formidable", Artist: "Stromae", Album: "Racine carrée", Genre: "Pop"}},
+		{"sans tags", audio, trackTags{}},
+		{"fichier trop court", []byte("ID3"), trackTags{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "piste")
+			if err := os.WriteFile(path, tt.data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if got := readTags(path); got != tt.want {
+				t.Errorf("readTags = %+v, attendu %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanGenre(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"Rock", "Rock"},
+		{"(17)Rock", "Rock"},
+		{"(17)(79) Hard Rock", "Hard Rock"},
+		{"(17", "(17"},
+		{"(13)", ""},
+	}
+
+	for _, tt := range tests {
+		if got := cleanGenre(tt.value); got != tt.want {
+			t.Errorf("cleanGenre(%q) = %q, attendu %q", tt.value, got, tt.want)
+		}
+	}
+}
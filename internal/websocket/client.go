@@ -22,9 +22,10 @@ type Client struct {
 	conn *websocket.Conn
 	send chan []byte
 
-	UserID   int64
-	Pseudo   string
-	RoomCode string
+	UserID    int64
+	Pseudo    string
+	RoomCode  string
+	Spectator bool
 
 	messageHandler MessageHandler
 
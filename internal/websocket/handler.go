@@ -3,10 +3,14 @@ package websocket
 import (
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"groupie-tracker/internal/auth"
+	"groupie-tracker/internal/games"
 	"groupie-tracker/internal/models"
 	"groupie-tracker/internal/rooms"
 )
@@ -19,39 +23,38 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-type BlindTestStarter interface {
-	StartGame(roomCode string, genre string, rounds int) error
-	HandleMessage(client *Client, msg *models.WSMessage)
-}
-
-type PetitBacStarter interface {
-	StartGame(roomCode string, categories []string, rounds int) error
+// EngineMessageHandler est implémenté par les moteurs qui reçoivent des
+// messages (games.Engine.MessageTypes non vide).
+type EngineMessageHandler interface {
 	HandleMessage(client *Client, msg *models.WSMessage)
 }
 
 type Handler struct {
 	hub         *Hub
 	roomManager *rooms.Manager
-
-	blindTestHandler BlindTestStarter
-	petitBacHandler  PetitBacStarter
+	registry    *games.Registry
+	presence    *presence
 }
 
-func NewHandler() *Handler {
-	return &Handler{
+// NewHandler crée le handler WebSocket. Un joueur déconnecté garde sa place
+// pendant gracePeriod (DefaultGracePeriod si <= 0).
+func NewHandler(roomManager *rooms.Manager, gracePeriod time.Duration) *Handler {
+	h := &Handler{
 		hub:         GetHub(),
-		roomManager: rooms.GetManager(),
+		roomManager: roomManager,
+		registry:    games.GetRegistry(),
+		presence:    newPresence(gracePeriod),
 	}
+	h.hub.SetDisconnectHandler(h.handleDisconnect)
+	h.registry.AddCheck(requireMessageHandler)
+	return h
 }
 
-func (h *Handler) SetBlindTestHandler(handler BlindTestStarter) {
-	h.blindTestHandler = handler
-	log.Println("[WebSocket] ✅ Handler Blind Test configuré")
-}
-
-func (h *Handler) SetPetitBacHandler(handler PetitBacStarter) {
-	h.petitBacHandler = handler
-	log.Println("[WebSocket] ✅ Handler Petit Bac configuré")
+func requireMessageHandler(engine games.Engine) error {
+	if _, ok := engine.(EngineMessageHandler); !ok && len(engine.MessageTypes()) > 0 {
+		return games.ErrNoHandler
+	}
+	return nil
 }
 
 func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
@@ -86,9 +89,10 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 
 	room.Mutex.RLock()
 	_, isInRoom := room.Players[user.ID]
+	_, isSpectator := room.Spectators[user.ID]
 	room.Mutex.RUnlock()
 
-	if !isInRoom {
+	if !isInRoom && !isSpectator {
 		log.Printf("[WebSocket] ❌ User %d pas dans la salle %s", user.ID, roomCode)
 		http.Error(w, "Vous n'êtes pas dans cette salle", http.StatusForbidden)
 		return
@@ -101,26 +105,36 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 
 	client := NewClient(h.hub, conn, user.ID, user.Pseudo, room.Code, h.handleMessage)
+	client.Spectator = !isInRoom
 
 	h.hub.Register(client)
 
-	room.Mutex.Lock()
-	if player, exists := room.Players[user.ID]; exists {
-		player.Connected = true
-	}
-	room.Mutex.Unlock()
+	reconnected := h.markConnected(room, user.ID)
 
-	log.Printf("[WebSocket] ✅ Client connecté: User %d (%s) dans salle %s", user.ID, user.Pseudo, room.Code)
+	log.Printf("[WebSocket] ✅ Client connecté: User %d (%s) dans salle %s (reconnexion: %v, spectateur: %v)",
+		user.ID, user.Pseudo, room.Code, reconnected, client.Spectator)
 
-	h.hub.BroadcastExcept(room.Code, &models.WSMessage{
-		Type: models.WSTypePlayerJoined,
-		Payload: map[string]interface{}{
-			"user_id": user.ID,
-			"pseudo":  user.Pseudo,
-		},
-	}, user.ID)
+	joinType := models.WSTypePlayerJoined
+	switch {
+	case client.Spectator && reconnected:
+		joinType = ""
+	case client.Spectator:
+		joinType = models.WSTypeSpectatorJoined
+	case reconnected:
+		joinType = models.WSTypePlayerReconnected
+	}
+	if joinType != "" {
+		h.hub.BroadcastExcept(room.Code, &models.WSMessage{
+			Type: joinType,
+			Payload: map[string]interface{}{
+				"user_id": user.ID,
+				"pseudo":  user.Pseudo,
+			},
+		}, user.ID)
+	}
 
 	h.sendRoomState(client, room)
+	h.sendGameSnapshot(client, room)
 
 	client.Start()
 }
@@ -138,6 +152,18 @@ func (h *Handler) handleMessage(client *Client, msg *models.WSMessage) {
 		}
 	}
 
+	// Un spectateur qui rejoint la salle comme joueur (POST /room/join) garde
+	// sa connexion : son rôle suit la salle.
+	room.Mutex.RLock()
+	_, isPlayer := room.Players[client.UserID]
+	room.Mutex.RUnlock()
+	client.Spectator = !isPlayer
+
+	if client.Spectator && msg.Type != models.WSTypeLeaveRoom {
+		client.SendError("Les spectateurs ne peuvent pas jouer")
+		return
+	}
+
 	switch msg.Type {
 	case models.WSTypePlayerReady:
 		h.handlePlayerReady(client, room, msg)
@@ -148,25 +174,34 @@ func (h *Handler) handleMessage(client *Client, msg *models.WSMessage) {
 	case models.WSTypeStartGame:
 		h.handleStartGame(client, room, msg)
 
-	case models.WSTypeBTAnswer:
-		if h.blindTestHandler != nil {
-			h.blindTestHandler.HandleMessage(client, msg)
-		} else {
-			log.Println("[WebSocket] ⚠️ Handler Blind Test non configuré")
-			client.SendError("Handler Blind Test non configuré")
-		}
-
-	case models.WSTypePBSubmitAnswers, models.WSTypePBStopRound, models.WSTypePBSubmitVotes:
-		if h.petitBacHandler != nil {
-			h.petitBacHandler.HandleMessage(client, msg)
-		} else {
-			log.Println("[WebSocket] ⚠️ Handler Petit Bac non configuré")
-			client.SendError("Handler Petit Bac non configuré")
-		}
+	case models.WSTypeUpdateConfig:
+		h.handleUpdateConfig(client, room, msg)
 
 	default:
+		h.dispatchToEngine(client, room, msg)
+	}
+}
+
+func (h *Handler) dispatchToEngine(client *Client, room *models.Room, msg *models.WSMessage) {
+	if !h.registry.Handles(room.GameType, msg.Type) {
 		log.Printf("[WebSocket] ⚠️ Message non géré: %s", msg.Type)
+		return
 	}
+
+	engine, err := h.registry.Get(room.GameType)
+	if err != nil {
+		client.SendError(err.Error())
+		return
+	}
+
+	handler, ok := engine.(EngineMessageHandler)
+	if !ok {
+		log.Printf("[WebSocket] ⚠️ Le moteur %s ne traite pas les messages", room.GameType)
+		client.SendError("Moteur de jeu non configuré")
+		return
+	}
+
+	handler.HandleMessage(client, msg)
 }
 
 func (h *Handler) handlePlayerReady(client *Client, room *models.Room, msg *models.WSMessage) {
@@ -206,23 +241,34 @@ func (h *Handler) handlePlayerReady(client *Client, room *models.Room, msg *mode
 }
 
 func (h *Handler) handleLeaveRoom(client *Client, room *models.Room) {
-	err := h.roomManager.LeaveRoom(room.ID, client.UserID)
-	if err != nil {
+	if err := h.LeaveRoom(room, client.UserID, client.Pseudo); err != nil {
 		client.SendError(err.Error())
-		return
 	}
+}
 
-	log.Printf("[WebSocket] 👋 Player %d (%s) quitte la salle %s", client.UserID, client.Pseudo, room.Code)
+// LeaveRoom fait quitter la salle à un joueur ou à un spectateur, l'annonce
+// aux autres et ferme sa connexion. Le message leave_room et
+// POST /api/rooms/leave passent tous deux par ici.
+func (h *Handler) LeaveRoom(room *models.Room, userID int64, pseudo string) error {
+	room.Mutex.RLock()
+	_, isPlayer := room.Players[userID]
+	_, isSpectator := room.Spectators[userID]
+	room.Mutex.RUnlock()
 
-	h.hub.BroadcastExcept(room.Code, &models.WSMessage{
-		Type: models.WSTypePlayerLeft,
-		Payload: map[string]interface{}{
-			"user_id": client.UserID,
-			"pseudo":  client.Pseudo,
-		},
-	}, client.UserID)
+	switch {
+	case isPlayer:
+		if err := h.removePlayer(room, userID, pseudo); err != nil {
+			return err
+		}
+		log.Printf("[WebSocket] 👋 Player %d (%s) quitte la salle %s", userID, pseudo, room.Code)
+	case isSpectator:
+		h.removeSpectator(room, userID, pseudo)
+	default:
+		return rooms.ErrPlayerNotFound
+	}
 
-	h.hub.Unregister(client)
+	h.hub.Disconnect(room.Code, userID)
+	return nil
 }
 
 func (h *Handler) handleStartGame(client *Client, room *models.Room, msg *models.WSMessage) {
@@ -242,77 +288,114 @@ func (h *Handler) handleStartGame(client *Client, room *models.Room, msg *models
 		return
 	}
 
-	switch room.GameType {
-	case models.GameTypeBlindTest:
-		if h.blindTestHandler == nil {
-			client.SendError("Handler Blind Test non configuré")
-			return
-		}
+	engine, err := h.registry.Get(room.GameType)
+	if err != nil {
+		client.SendError("Type de jeu inconnu: " + string(room.GameType))
+		return
+	}
 
-		genre := room.Config.Playlist
-		if genre == "" {
-			genre = "Pop"
-		}
-		rounds := 10
+	room.Mutex.RLock()
+	config := room.Config
+	room.Mutex.RUnlock()
 
-		if payload, ok := msg.Payload.(map[string]interface{}); ok {
-			if g, ok := payload["genre"].(string); ok && g != "" {
-				genre = g
-			}
-			if r, ok := payload["rounds"].(float64); ok && r > 0 {
-				rounds = int(r)
-			}
-		}
+	if err := engine.ValidateConfig(&config); err != nil {
+		client.SendError("Impossible de démarrer: " + err.Error())
+		return
+	}
+	// ValidateConfig peut compléter la config (résumé de la playlist) : Start doit
+	// partir de cette version.
+	if err := h.roomManager.UpdateRoomConfig(room.ID, config); err != nil {
+		client.SendError("Impossible de démarrer: " + err.Error())
+		return
+	}
 
-		err := h.blindTestHandler.StartGame(room.Code, genre, rounds)
-		if err != nil {
-			log.Printf("[WebSocket] ❌ Erreur démarrage BlindTest: %v", err)
-			client.SendError("Impossible de démarrer: " + err.Error())
-			return
-		}
+	options, _ := msg.Payload.(map[string]interface{})
+	if options == nil {
+		options = map[string]interface{}{}
+	}
 
-		h.roomManager.StartGame(room.ID)
+	if err := engine.Start(room, options); err != nil {
+		log.Printf("[WebSocket] ❌ Erreur démarrage %s: %v", room.GameType, err)
+		client.SendError("Impossible de démarrer: " + err.Error())
+		return
+	}
 
-		log.Printf("[WebSocket] ✅ BlindTest démarré: genre=%s, rounds=%d", genre, rounds)
+	h.roomManager.StartGame(room.ID)
+}
 
-		h.hub.Broadcast(room.Code, &models.WSMessage{
-			Type: models.WSTypeStartGame,
-			Payload: map[string]interface{}{
-				"game_type": "blindtest",
-				"genre":     genre,
-				"rounds":    rounds,
-			},
-		})
+// handleUpdateConfig permet à l'hôte de modifier les réglages de la salle
+// tant que la partie n'a pas commencé. Le payload reprend les champs du
+// formulaire de création et passe par le ParseConfig du moteur.
+func (h *Handler) handleUpdateConfig(client *Client, room *models.Room, msg *models.WSMessage) {
+	if room.HostID != client.UserID {
+		client.SendError("Seul l'hôte peut modifier les réglages")
+		return
+	}
 
-	case models.GameTypePetitBac:
-		if h.petitBacHandler == nil {
-			client.SendError("Handler Petit Bac non configuré")
-			return
-		}
+	payload, ok := msg.Payload.(map[string]interface{})
+	if !ok {
+		client.SendError("Payload invalide")
+		return
+	}
 
-		categories := room.Config.Categories
-		if len(categories) == 0 {
-			categories = models.DefaultPetitBacCategories
-		}
-		rounds := room.Config.NbRounds
-		if rounds <= 0 {
-			rounds = models.NbrsManche
-		}
+	room.Mutex.RLock()
+	status := room.Status
+	config := room.Config
+	room.Mutex.RUnlock()
 
-		err := h.petitBacHandler.StartGame(room.Code, categories, rounds)
-		if err != nil {
-			log.Printf("[WebSocket] ❌ Erreur démarrage PetitBac: %v", err)
-			client.SendError("Impossible de démarrer: " + err.Error())
-			return
-		}
+	if status == models.RoomStatusPlaying {
+		client.SendError("Les réglages ne peuvent plus changer pendant la partie")
+		return
+	}
 
-		h.roomManager.StartGame(room.ID)
+	engine, err := h.registry.Get(room.GameType)
+	if err != nil {
+		client.SendError("Type de jeu inconnu: " + string(room.GameType))
+		return
+	}
 
-		log.Printf("[WebSocket] ✅ PetitBac démarré: categories=%v, rounds=%d", categories, rounds)
+	engine.ParseConfig(payloadToForm(payload), &config)
+	if err := engine.ValidateConfig(&config); err != nil {
+		client.SendError("Réglages refusés: " + err.Error())
+		return
+	}
 
-	default:
-		client.SendError("Type de jeu inconnu: " + string(room.GameType))
+	if err := h.roomManager.UpdateRoomConfig(room.ID, config); err != nil {
+		client.SendError(err.Error())
+		return
 	}
+
+	log.Printf("[WebSocket] ⚙️ Réglages de la salle %s modifiés par %s", room.Code, client.Pseudo)
+
+	h.hub.Broadcast(room.Code, &models.WSMessage{
+		Type: models.WSTypeConfigUpdate,
+		Payload: map[string]interface{}{
+			"config": config,
+		},
+	})
+}
+
+// payloadToForm convertit un payload JSON en valeurs de formulaire, pour que
+// les moteurs n'aient qu'un seul parseur de configuration.
+func payloadToForm(payload map[string]interface{}) url.Values {
+	form := url.Values{}
+	for key, value := range payload {
+		switch v := value.(type) {
+		case string:
+			form.Set(key, v)
+		case float64:
+			form.Set(key, strconv.FormatFloat(v, 'f', -1, 64))
+		case bool:
+			form.Set(key, strconv.FormatBool(v))
+		case []interface{}:
+			for _, item := range v {
+				if str, ok := item.(string); ok {
+					form.Add(key, str)
+				}
+			}
+		}
+	}
+	return form
 }
 
 func (h *Handler) sendRoomState(client *Client, room *models.Room) {
@@ -331,22 +414,63 @@ func (h *Handler) sendRoomState(client *Client, room *models.Room) {
 		})
 	}
 
+	spectators := make([]map[string]interface{}, 0, len(room.Spectators))
+	for _, s := range room.Spectators {
+		spectators = append(spectators, map[string]interface{}{
+			"user_id":   s.UserID,
+			"pseudo":    s.Pseudo,
+			"connected": s.Connected,
+		})
+	}
+
 	client.Send(&models.WSMessage{
 		Type: models.WSTypeRoomUpdate,
 		Payload: map[string]interface{}{
-			"room_id":   room.ID,
-			"code":      room.Code,
-			"name":      room.Name,
-			"host_id":   room.HostID,
-			"game_type": room.GameType,
-			"status":    room.Status,
-			"players":   players,
-			"config":    room.Config,
-			"is_ready":  models.IsRoomReady(room),
+			"room_id":      room.ID,
+			"code":         room.Code,
+			"name":         room.Name,
+			"host_id":      room.HostID,
+			"game_type":    room.GameType,
+			"status":       room.Status,
+			"players":      players,
+			"spectators":   spectators,
+			"is_spectator": client.Spectator,
+			"config":       room.Config,
+			"is_ready":     models.IsRoomReady(room),
 		},
 	})
 }
 
+// sendGameSnapshot permet à un joueur qui recharge la page en pleine partie
+// de reprendre là où il en était sans attendre le prochain broadcast.
+func (h *Handler) sendGameSnapshot(client *Client, room *models.Room) {
+	room.Mutex.RLock()
+	gameType := room.GameType
+	status := room.Status
+	room.Mutex.RUnlock()
+
+	if status != models.RoomStatusPlaying {
+		return
+	}
+
+	engine, err := h.registry.Get(gameType)
+	if err != nil {
+		return
+	}
+
+	snapshot := engine.Snapshot(room.ID, client.UserID)
+	if snapshot == nil {
+		return
+	}
+
+	log.Printf("[WebSocket] 🔄 Snapshot %s envoyé à %s", gameType, client.Pseudo)
+
+	client.Send(&models.WSMessage{
+		Type:    models.WSTypeGameSnapshot,
+		Payload: snapshot,
+	})
+}
+
 func (h *Handler) GetHub() *Hub {
 	return h.hub
 }
\ No newline at end of file
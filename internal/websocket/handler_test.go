@@ -0,0 +1,81 @@
+package websocket
+
+import (
+	"testing"
+
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/rooms"
+)
+
+func TestLeaveRoom(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   int64
+		pseudo   string
+		announce models.WSMessageType
+		err      error
+	}{
+		{"joueur", 2, "Bob", models.WSTypePlayerLeft, nil},
+		{"spectateur", 3, "Carol", models.WSTypeSpectatorLeft, nil},
+		{"inconnu", 4, "Dave", "", rooms.ErrPlayerNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, room, _ := newTestRoom(t)
+			if _, err := h.roomManager.AddSpectator(room.ID, 3, "Carol"); err != nil {
+				t.Fatalf("AddSpectator: %v", err)
+			}
+			alice := connect(h, room, 1, "Alice")
+			leaving := connect(h, room, tt.userID, tt.pseudo)
+
+			if err := h.LeaveRoom(room, tt.userID, tt.pseudo); err != tt.err {
+				t.Fatalf("LeaveRoom = %v, attendu %v", err, tt.err)
+			}
+			if tt.err != nil {
+				expectNoMessage(t, alice)
+				return
+			}
+
+			payload := expectMessage(t, alice, tt.announce)
+			if payload["user_id"] != float64(tt.userID) {
+				t.Errorf("%s = %v", tt.announce, payload)
+			}
+			if _, stillOpen := <-leaving.send; stillOpen {
+				t.Errorf("connexion de %s toujours ouverte", tt.pseudo)
+			}
+			room.Mutex.RLock()
+			_, isPlayer := room.Players[tt.userID]
+			_, isSpectator := room.Spectators[tt.userID]
+			room.Mutex.RUnlock()
+			if isPlayer || isSpectator {
+				t.Errorf("%s toujours dans la salle", tt.pseudo)
+			}
+		})
+	}
+}
+
+func TestPromotedSpectatorCanPlay(t *testing.T) {
+	h, room, _ := newTestRoom(t)
+	if _, err := h.roomManager.AddSpectator(room.ID, 3, "Carol"); err != nil {
+		t.Fatalf("AddSpectator: %v", err)
+	}
+	carol := connect(h, room, 3, "Carol")
+	carol.Spectator = true
+
+	ready := &models.WSMessage{Type: models.WSTypePlayerReady, Payload: map[string]interface{}{"ready": true}}
+	h.handleMessage(carol, ready)
+	if msgType, _ := nextMessage(t, carol); msgType != models.WSTypeError {
+		t.Fatalf("message %s, attendu un refus pour un spectateur", msgType)
+	}
+
+	if _, err := h.roomManager.JoinRoom(room.ID, 3, "Carol"); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	h.handleMessage(carol, ready)
+
+	if carol.Spectator {
+		t.Errorf("Carol toujours spectatrice après avoir rejoint la salle")
+	}
+	expectMessage(t, carol, models.WSTypePlayerReady)
+}
@@ -15,6 +15,8 @@ type Hub struct {
 	unregister chan *Client
 	broadcast  chan *BroadcastMessage
 
+	onDisconnect func(client *Client)
+
 	mutex sync.RWMutex
 }
 
@@ -80,8 +82,10 @@ func (h *Hub) unregisterClient(client *Client) {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
 
+	// Une connexion remplacée par une plus récente (rechargement de page) se
+	// désinscrit après coup : elle ne doit pas retirer sa remplaçante.
 	if room, exists := h.rooms[client.RoomCode]; exists {
-		if _, exists := room[client.UserID]; exists {
+		if current, exists := room[client.UserID]; exists && current == client {
 			delete(room, client.UserID)
 			client.Close()
 			log.Printf("[Hub] 🔌 Client déconnecté: User %d (%s) de salle %s (restant: %d)",
@@ -91,8 +95,14 @@ func (h *Hub) unregisterClient(client *Client) {
 				delete(h.rooms, client.RoomCode)
 				log.Printf("[Hub] 🗑️ Salle %s supprimée (vide)", client.RoomCode)
 			}
+
+			if h.onDisconnect != nil {
+				go h.onDisconnect(client)
+			}
+			return
 		}
 	}
+	client.Close()
 }
 
 func (h *Hub) broadcastToRoom(msg *BroadcastMessage) {
@@ -132,6 +142,14 @@ func (h *Hub) broadcastToRoom(msg *BroadcastMessage) {
 	}
 }
 
+// SetDisconnectHandler est appelé (dans sa propre goroutine) quand la
+// connexion active d'un joueur se ferme.
+func (h *Hub) SetDisconnectHandler(fn func(client *Client)) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.onDisconnect = fn
+}
+
 func (h *Hub) Register(client *Client) {
 	h.register <- client
 }
@@ -140,6 +158,17 @@ func (h *Hub) Unregister(client *Client) {
 	h.unregister <- client
 }
 
+// Disconnect ferme la connexion d'un utilisateur dans la salle, s'il en a une.
+func (h *Hub) Disconnect(roomCode string, userID int64) {
+	h.mutex.RLock()
+	client := h.rooms[roomCode][userID]
+	h.mutex.RUnlock()
+
+	if client != nil {
+		h.Unregister(client)
+	}
+}
+
 func (h *Hub) Broadcast(roomCode string, msg *models.WSMessage) {
 	h.broadcast <- &BroadcastMessage{
 		RoomCode: roomCode,
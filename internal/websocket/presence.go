@@ -0,0 +1,238 @@
+package websocket
+
+import (
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"groupie-tracker/internal/games"
+	"groupie-tracker/internal/models"
+)
+
+// DefaultGracePeriod est le délai pendant lequel un joueur déconnecté garde
+// sa place et son score avant d'être retiré de la salle.
+const DefaultGracePeriod = 30 * time.Second
+
+// presence suit les joueurs déconnectés en attente de retour.
+// transitions sérialise connexions et déconnexions pour qu'un rechargement
+// rapide ne laisse pas un joueur connecté marqué absent.
+type presence struct {
+	gracePeriod time.Duration
+	timers      map[string]*time.Timer
+	mutex       sync.Mutex
+	transitions sync.Mutex
+}
+
+func newPresence(gracePeriod time.Duration) *presence {
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultGracePeriod
+	}
+	return &presence{
+		gracePeriod: gracePeriod,
+		timers:      make(map[string]*time.Timer),
+	}
+}
+
+func presenceKey(roomID string, userID int64) string {
+	return roomID + ":" + strconv.FormatInt(userID, 10)
+}
+
+// cancel arrête le délai de grâce d'un joueur et indique s'il y en avait un.
+func (p *presence) cancel(roomID string, userID int64) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	key := presenceKey(roomID, userID)
+	timer, exists := p.timers[key]
+	if !exists {
+		return false
+	}
+	timer.Stop()
+	delete(p.timers, key)
+	return true
+}
+
+func (p *presence) schedule(roomID string, userID int64, fn func()) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	key := presenceKey(roomID, userID)
+	if timer, exists := p.timers[key]; exists {
+		timer.Stop()
+	}
+	p.timers[key] = time.AfterFunc(p.gracePeriod, func() {
+		p.mutex.Lock()
+		delete(p.timers, key)
+		p.mutex.Unlock()
+		fn()
+	})
+}
+
+// markConnected passe le joueur en ligne et indique s'il revenait d'une
+// déconnexion (player_reconnected) plutôt que d'une première arrivée.
+func (h *Handler) markConnected(room *models.Room, userID int64) bool {
+	h.presence.transitions.Lock()
+	defer h.presence.transitions.Unlock()
+
+	reconnected := h.presence.cancel(room.ID, userID)
+
+	if err := h.roomManager.SetPlayerConnected(room.ID, userID, true); err != nil {
+		h.roomManager.SetSpectatorConnected(room.ID, userID, true)
+	}
+
+	return reconnected
+}
+
+// handleDisconnect est appelé par le hub quand la connexion active d'un
+// joueur se ferme. Le joueur garde sa place pendant le délai de grâce.
+func (h *Handler) handleDisconnect(client *Client) {
+	h.presence.transitions.Lock()
+	defer h.presence.transitions.Unlock()
+
+	room, err := h.roomManager.GetRoomByCode(client.RoomCode)
+	if err != nil {
+		return
+	}
+
+	if h.hub.IsUserConnected(room.Code, client.UserID) {
+		return
+	}
+
+	if client.Spectator {
+		if err := h.roomManager.SetSpectatorConnected(room.ID, client.UserID, false); err != nil {
+			return
+		}
+		roomID, userID, pseudo := room.ID, client.UserID, client.Pseudo
+		h.presence.schedule(roomID, userID, func() {
+			h.expireSpectator(roomID, userID, pseudo)
+		})
+		return
+	}
+
+	if err := h.roomManager.SetPlayerConnected(room.ID, client.UserID, false); err != nil {
+		// Départ volontaire : le joueur a déjà quitté la salle.
+		return
+	}
+
+	log.Printf("[Presence] ⏳ %s déconnecté de %s, place gardée %s", client.Pseudo, room.Code, h.presence.gracePeriod)
+
+	h.hub.Broadcast(room.Code, &models.WSMessage{
+		Type: models.WSTypePlayerDisconnected,
+		Payload: map[string]interface{}{
+			"user_id":      client.UserID,
+			"pseudo":       client.Pseudo,
+			"grace_period": int(h.presence.gracePeriod.Seconds()),
+		},
+	})
+
+	if engine, err := h.registry.Get(room.GameType); err == nil {
+		if handler, ok := engine.(games.DisconnectHandler); ok {
+			handler.PlayerDisconnected(room.ID, client.UserID)
+		}
+	}
+
+	roomID, userID, pseudo := room.ID, client.UserID, client.Pseudo
+	h.presence.schedule(roomID, userID, func() {
+		h.expireSeat(roomID, userID, pseudo)
+	})
+}
+
+func (h *Handler) expireSeat(roomID string, userID int64, pseudo string) {
+	room, err := h.roomManager.GetRoom(roomID)
+	if err != nil {
+		return
+	}
+
+	if h.hub.IsUserConnected(room.Code, userID) {
+		return
+	}
+
+	room.Mutex.RLock()
+	player, exists := room.Players[userID]
+	stillAway := exists && !player.Connected
+	room.Mutex.RUnlock()
+
+	if !stillAway {
+		return
+	}
+
+	log.Printf("[Presence] ⌛ Délai de grâce écoulé pour %s dans %s", pseudo, room.Code)
+	h.removePlayer(room, userID, pseudo)
+}
+
+func (h *Handler) expireSpectator(roomID string, userID int64, pseudo string) {
+	room, err := h.roomManager.GetRoom(roomID)
+	if err != nil {
+		return
+	}
+
+	if h.hub.IsUserConnected(room.Code, userID) {
+		return
+	}
+
+	h.removeSpectator(room, userID, pseudo)
+}
+
+func (h *Handler) removeSpectator(room *models.Room, userID int64, pseudo string) {
+	if !h.roomManager.RemoveSpectator(room.ID, userID) {
+		return
+	}
+
+	log.Printf("[Presence] 👀 %s ne regarde plus %s", pseudo, room.Code)
+
+	h.hub.BroadcastExcept(room.Code, &models.WSMessage{
+		Type: models.WSTypeSpectatorLeft,
+		Payload: map[string]interface{}{
+			"user_id": userID,
+			"pseudo":  pseudo,
+		},
+	}, userID)
+}
+
+// removePlayer retire le joueur via le manager, arrête la partie si la salle
+// a disparu et annonce le départ ainsi que l'éventuel nouvel hôte.
+func (h *Handler) removePlayer(room *models.Room, userID int64, pseudo string) error {
+	room.Mutex.RLock()
+	previousHost := room.HostID
+	room.Mutex.RUnlock()
+
+	if err := h.roomManager.LeaveRoom(room.ID, userID); err != nil {
+		return err
+	}
+
+	if _, err := h.roomManager.GetRoom(room.ID); err != nil {
+		if engine, err := h.registry.Get(room.GameType); err == nil {
+			engine.Stop(room.ID)
+		}
+		return nil
+	}
+
+	h.hub.BroadcastExcept(room.Code, &models.WSMessage{
+		Type: models.WSTypePlayerLeft,
+		Payload: map[string]interface{}{
+			"user_id": userID,
+			"pseudo":  pseudo,
+		},
+	}, userID)
+
+	room.Mutex.RLock()
+	hostID := room.HostID
+	hostPseudo := ""
+	if host, exists := room.Players[hostID]; exists {
+		hostPseudo = host.Pseudo
+	}
+	room.Mutex.RUnlock()
+
+	if hostID != previousHost {
+		log.Printf("[Presence] 👑 Nouvel hôte de %s: %s", room.Code, hostPseudo)
+		h.hub.Broadcast(room.Code, &models.WSMessage{
+			Type: models.WSTypeHostChanged,
+			Payload: map[string]interface{}{
+				"host_id": hostID,
+				"pseudo":  hostPseudo,
+			},
+		})
+	}
+	return nil
+}
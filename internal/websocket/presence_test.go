@@ -0,0 +1,210 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"groupie-tracker/internal/games"
+	"groupie-tracker/internal/models"
+	"groupie-tracker/internal/rooms"
+)
+
+const (
+	testGameType    models.GameType = "test-presence"
+	testGracePeriod                 = 20 * time.Millisecond
+	messageTimeout                  = time.Second
+)
+
+// testEngine note les parties arrêtées pour vérifier l'arrêt d'une salle vide.
+type testEngine struct {
+	stopped chan string
+}
+
+func (testEngine) GameType() models.GameType                  { return testGameType }
+func (testEngine) DisplayName() string                        { return "Test" }
+func (testEngine) MessageTypes() []models.WSMessageType       { return nil }
+func (testEngine) Template() string                           { return "" }
+func (testEngine) DefaultConfig() models.GameConfig           { return models.GameConfig{NbRounds: 3} }
+func (testEngine) ParseConfig(url.Values, *models.GameConfig) {}
+func (testEngine) ValidateConfig(*models.GameConfig) error    { return nil }
+func (testEngine) Start(*models.Room, map[string]interface{}) error {
+	return nil
+}
+func (e testEngine) Stop(roomID string) { e.stopped <- roomID }
+func (testEngine) Snapshot(string, int64) map[string]interface{} {
+	return nil
+}
+
+// newTestRoom crée une salle où Alice (1, hôte) et Bob (2) sont assis, et
+// un handler dont le délai de grâce est court.
+func newTestRoom(t *testing.T) (*Handler, *models.Room, testEngine) {
+	t.Helper()
+	engine := testEngine{stopped: make(chan string, 1)}
+	games.GetRegistry().Register(engine)
+	store := rooms.NewMemoryStore()
+	roomManager := rooms.NewManager(store, store)
+	h := NewHandler(roomManager, testGracePeriod)
+
+	room, err := roomManager.CreateRoom("Salle de test", 1, "Alice", testGameType)
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+	if _, err := roomManager.JoinRoom(room.ID, 2, "Bob"); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	t.Cleanup(func() {
+		h.hub.mutex.Lock()
+		delete(h.hub.rooms, room.Code)
+		h.hub.mutex.Unlock()
+	})
+	return h, room, engine
+}
+
+// connect inscrit directement dans le hub un client sans connexion réseau,
+// dont on lit les messages sur son canal d'envoi.
+func connect(h *Handler, room *models.Room, userID int64, pseudo string) *Client {
+	client := NewClient(h.hub, nil, userID, pseudo, room.Code, nil)
+	h.hub.registerClient(client)
+	return client
+}
+
+func nextMessage(t *testing.T, client *Client) (models.WSMessageType, map[string]interface{}) {
+	t.Helper()
+	select {
+	case data := <-client.send:
+		var msg struct {
+			Type    models.WSMessageType   `json:"type"`
+			Payload map[string]interface{} `json:"payload"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("message illisible: %v", err)
+		}
+		return msg.Type, msg.Payload
+	case <-time.After(messageTimeout):
+		t.Fatalf("aucun message reçu par %s", client.Pseudo)
+		return "", nil
+	}
+}
+
+func expectMessage(t *testing.T, client *Client, want models.WSMessageType) map[string]interface{} {
+	t.Helper()
+	msgType, payload := nextMessage(t, client)
+	if msgType != want {
+		t.Fatalf("message %s reçu par %s, attendu %s", msgType, client.Pseudo, want)
+	}
+	return payload
+}
+
+func expectNoMessage(t *testing.T, client *Client) {
+	t.Helper()
+	select {
+	case data := <-client.send:
+		t.Fatalf("message inattendu reçu par %s: %s", client.Pseudo, data)
+	case <-time.After(5 * testGracePeriod):
+	}
+}
+
+func TestGracePeriodExpires(t *testing.T) {
+	h, room, _ := newTestRoom(t)
+	alice := connect(h, room, 1, "Alice")
+	bob := NewClient(h.hub, nil, 2, "Bob", room.Code, nil)
+
+	h.handleDisconnect(bob)
+
+	payload := expectMessage(t, alice, models.WSTypePlayerDisconnected)
+	if payload["user_id"] != float64(2) {
+		t.Errorf("player_disconnected = %v, attendu Bob", payload)
+	}
+	player, err := h.roomManager.GetPlayer(room.ID, 2)
+	if err != nil || player.Connected {
+		t.Fatalf("Bob doit garder sa place hors ligne: %+v, %v", player, err)
+	}
+
+	expectMessage(t, alice, models.WSTypePlayerLeft)
+	if _, err := h.roomManager.GetPlayer(room.ID, 2); err == nil {
+		t.Errorf("Bob toujours assis après le délai de grâce")
+	}
+}
+
+func TestReconnectKeepsSeat(t *testing.T) {
+	h, room, _ := newTestRoom(t)
+	alice := connect(h, room, 1, "Alice")
+	bob := NewClient(h.hub, nil, 2, "Bob", room.Code, nil)
+
+	h.handleDisconnect(bob)
+	expectMessage(t, alice, models.WSTypePlayerDisconnected)
+
+	if !h.markConnected(room, 2) {
+		t.Errorf("retour pendant le délai de grâce non reconnu comme une reconnexion")
+	}
+	if h.markConnected(room, 2) {
+		t.Errorf("seconde connexion reconnue comme une reconnexion")
+	}
+
+	expectNoMessage(t, alice)
+	player, err := h.roomManager.GetPlayer(room.ID, 2)
+	if err != nil || !player.Connected {
+		t.Errorf("Bob doit être assis et en ligne: %+v, %v", player, err)
+	}
+}
+
+func TestRemovePlayerMigratesHost(t *testing.T) {
+	tests := []struct {
+		name      string
+		leaving   int64
+		stays     int64
+		newHostID int64
+	}{
+		{"départ de l'hôte", 1, 2, 2},
+		{"départ d'un invité", 2, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, room, _ := newTestRoom(t)
+			pseudos := map[int64]string{1: "Alice", 2: "Bob"}
+			remaining := connect(h, room, tt.stays, pseudos[tt.stays])
+
+			if err := h.removePlayer(room, tt.leaving, pseudos[tt.leaving]); err != nil {
+				t.Fatalf("removePlayer: %v", err)
+			}
+
+			payload := expectMessage(t, remaining, models.WSTypePlayerLeft)
+			if payload["user_id"] != float64(tt.leaving) {
+				t.Errorf("player_left = %v", payload)
+			}
+			if tt.newHostID == 0 {
+				expectNoMessage(t, remaining)
+				return
+			}
+			payload = expectMessage(t, remaining, models.WSTypeHostChanged)
+			if payload["host_id"] != float64(tt.newHostID) {
+				t.Errorf("host_changed = %v, attendu %d", payload, tt.newHostID)
+			}
+			if room.HostID != tt.newHostID {
+				t.Errorf("hôte = %d, attendu %d", room.HostID, tt.newHostID)
+			}
+		})
+	}
+}
+
+func TestRemoveLastPlayerStopsGame(t *testing.T) {
+	h, room, engine := newTestRoom(t)
+
+	for _, userID := range []int64{2, 1} {
+		if err := h.removePlayer(room, userID, ""); err != nil {
+			t.Fatalf("removePlayer(%d): %v", userID, err)
+		}
+	}
+
+	select {
+	case roomID := <-engine.stopped:
+		if roomID != room.ID {
+			t.Errorf("partie arrêtée dans %s, attendu %s", roomID, room.ID)
+		}
+	case <-time.After(messageTimeout):
+		t.Fatalf("partie non arrêtée quand la salle s'est vidée")
+	}
+}